@@ -8,6 +8,7 @@ import (
 type AppendTable[E any, PE mutator.Mutatable[E]] struct {
 	Settings *TableSettings
 	*queries.Scanable[E, PE]
+	*queries.Aggregatable[E, PE]
 	*queries.Addable[E, PE]
 	*queries.Transferable[E, PE]
 }
@@ -15,7 +16,10 @@ type AppendTable[E any, PE mutator.Mutatable[E]] struct {
 func (t *AppendTable[E, PE]) Init() {
 	t.Settings.ApplyOption(WithEntry[E, PE]())
 	t.Scanable = &queries.Scanable[E, PE]{}
-	t.Addable = &queries.Addable[E, PE]{}
+	t.Aggregatable = &queries.Aggregatable[E, PE]{}
+	t.Addable = &queries.Addable[E, PE]{
+		Generators: t.Settings.Generators(),
+	}
 	t.Transferable = &queries.Transferable[E, PE]{
 		Scanable: t.Scanable,
 		Addable:  t.Addable,
@@ -28,5 +32,6 @@ func (t *AppendTable[E, PE]) GetSettings() *TableSettings {
 
 func (t *AppendTable[E, PE]) SetBackend(tableBackend AppendTableBackendQueries) {
 	t.Scanable.SetBackend(tableBackend)
+	t.Aggregatable.SetBackend(tableBackend)
 	t.Addable.SetBackend(tableBackend)
 }
@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"context"
+
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
 )
@@ -17,6 +19,7 @@ type TableBackend[C Connection] interface {
 
 type AppendTableBackendQueries interface {
 	queries.ScanableBackend
+	queries.AggregatableBackend
 	queries.AddableBackend
 }
 
@@ -27,8 +30,10 @@ type AppendTableBackend[C Connection] interface {
 
 type HashTableBackendQueries interface {
 	queries.ScanableBackend
+	queries.AggregatableBackend
 	queries.CountableBackend
 	queries.CRUDableBackend
+	queries.IndexableBackend
 }
 
 type HashTableBackend[C Connection] interface {
@@ -38,8 +43,10 @@ type HashTableBackend[C Connection] interface {
 
 type SortTableBackendQueries interface {
 	queries.ScanableBackend
+	queries.AggregatableBackend
 	queries.CountableBackend
 	queries.CRUDableBackend
+	queries.IndexableBackend
 	queries.SortableBackend
 }
 
@@ -51,7 +58,7 @@ type SortTableBackend[C Connection] interface {
 type QueueBackendQueries interface {
 	queries.CountableBackend
 	queries.MessageReceiveableBackend
-	SendMessage(messages []mutator.MappedFieldValues) error
+	SendMessage(ctx context.Context, messages []mutator.MappedFieldValues) error
 }
 
 type QueueBackend[C Connection] interface {
@@ -60,13 +67,13 @@ type QueueBackend[C Connection] interface {
 }
 
 type TopicBackendQueries interface {
-	Publish(messages []mutator.MappedFieldValues) error
-	Subscribe(subscriptionId string) (SubscriptionBackendQueries, error)
+	Publish(ctx context.Context, messages []mutator.MappedFieldValues) error
+	Subscribe(ctx context.Context, subscriptionId string) (SubscriptionBackendQueries, error)
 }
 
 type SubscriptionBackendQueries interface {
 	queries.MessageReceiveableBackend
-	Unsubscribe() error
+	Unsubscribe(ctx context.Context) error
 }
 
 type TopicBackend[C Connection] interface {
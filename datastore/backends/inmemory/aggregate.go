@@ -0,0 +1,179 @@
+package inmemory
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+)
+
+// aggregator accumulates one aggregate over the entries of a group
+type aggregator struct {
+	aggregate *queries.Aggregate
+	count     int
+	intSum    int64
+	uintSum   uint64
+	floatSum  float64
+	value     any
+}
+
+func (a *aggregator) add(entry mutator.MappedFieldValues) error {
+	a.count += 1
+	value := entry[a.aggregate.FieldName]
+
+	switch a.aggregate.Func {
+	case queries.SUM, queries.AVG:
+		reflectValue := reflect.ValueOf(value)
+		if reflectValue.CanInt() {
+			a.intSum += reflectValue.Int()
+		} else if reflectValue.CanUint() {
+			a.uintSum += reflectValue.Uint()
+		} else if reflectValue.CanFloat() {
+			a.floatSum += reflectValue.Float()
+		}
+	case queries.MIN, queries.MAX:
+		if a.count == 1 {
+			a.value = value
+			return nil
+		}
+
+		result, err := compare.CompareValues(value, a.value)
+		if err != nil {
+			return err
+		}
+
+		if (a.aggregate.Func == queries.MIN && result < 0) || (a.aggregate.Func == queries.MAX && result > 0) {
+			a.value = value
+		}
+	}
+
+	return nil
+}
+
+func (a *aggregator) result(emptyValue any) any {
+	emptyResult, _ := a.aggregate.EmptyResult(emptyValue)
+
+	switch a.aggregate.Func {
+	case queries.COUNT:
+		return fields.Int(a.count)
+	case queries.SUM:
+		sum := reflect.New(reflect.TypeOf(emptyResult)).Elem()
+		if sum.CanInt() {
+			sum.SetInt(a.intSum)
+		} else if sum.CanUint() {
+			sum.SetUint(a.uintSum)
+		} else {
+			sum.SetFloat(a.floatSum)
+		}
+		return sum.Interface()
+	case queries.AVG:
+		if a.count == 0 {
+			return emptyResult
+		}
+		return (float64(a.intSum) + float64(a.uintSum) + a.floatSum) / float64(a.count)
+	default:
+		if a.count == 0 {
+			return emptyResult
+		}
+		return a.value
+	}
+}
+
+type aggregateGroup struct {
+	values      mutator.MappedFieldValues
+	aggregators []*aggregator
+}
+
+func newAggregateGroup(values mutator.MappedFieldValues, aggregates []*queries.Aggregate) *aggregateGroup {
+	group := &aggregateGroup{
+		values:      values,
+		aggregators: make([]*aggregator, len(aggregates)),
+	}
+	for i, aggregate := range aggregates {
+		group.aggregators[i] = &aggregator{aggregate: aggregate}
+	}
+
+	return group
+}
+
+// aggregateEntries aggregates the entries matching the filter in options by
+// group
+func aggregateEntries(settings *datastore.TableSettings, entries []mutator.MappedFieldValues, aggregates []*queries.Aggregate, options *queries.AggregateOptions) ([]*queries.AggregateFields, error) {
+	if err := settings.ValidateAggregates(aggregates, options); err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*aggregateGroup{}
+	orderedGroups := []*aggregateGroup{}
+	for _, entry := range entries {
+		matches, err := options.Filter.Matches(entry)
+		if err != nil {
+			return nil, err
+		} else if !matches {
+			continue
+		}
+
+		values := projectEntry(entry, options.GroupBy)
+		if len(options.GroupBy) == 0 {
+			values = mutator.MappedFieldValues{}
+		}
+
+		valuesStr, err := stringifyKey(values)
+		if err != nil {
+			return nil, err
+		}
+
+		group, ok := groups[valuesStr]
+		if !ok {
+			group = newAggregateGroup(values, aggregates)
+			groups[valuesStr] = group
+			orderedGroups = append(orderedGroups, group)
+		}
+
+		for _, aggregator := range group.aggregators {
+			if err := aggregator.add(entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(options.GroupBy) == 0 && len(orderedGroups) == 0 {
+		orderedGroups = append(orderedGroups, newAggregateGroup(mutator.MappedFieldValues{}, aggregates))
+	}
+
+	var sortErr error
+	sort.SliceStable(orderedGroups, func(i, j int) bool {
+		for _, fieldName := range options.GroupBy {
+			result, err := compare.CompareValues(orderedGroups[i].values[fieldName], orderedGroups[j].values[fieldName])
+			if err != nil {
+				sortErr = err
+			} else if result != 0 {
+				return result < 0
+			}
+		}
+
+		return false
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	results := make([]*queries.AggregateFields, len(orderedGroups))
+	for i, group := range orderedGroups {
+		values := mutator.MappedFieldValues{}
+		for _, aggregator := range group.aggregators {
+			values[aggregator.aggregate.Name] = aggregator.result(settings.EmptyValues[aggregator.aggregate.FieldName])
+		}
+
+		results[i] = &queries.AggregateFields{
+			Group:  group.values,
+			Values: values,
+		}
+	}
+
+	return results, nil
+}
@@ -1,12 +1,21 @@
 package inmemory
 
 import (
+	"context"
+	"reflect"
+
 	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
 	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
 )
 
 type AppendTable = []mutator.MappedFieldValues
 
+// entries are only ever added to the end of an append table, so pages are
+// found by their offset
+const offsetCursorKey = "Offset"
+
 type AppendTableBackend struct {
 	conn     *Connection
 	settings *datastore.TableSettings
@@ -21,7 +30,11 @@ func (b *AppendTableBackend) SetConnection(conn *Connection) {
 }
 
 func (b *AppendTableBackend) Register() error {
-	if err := validateAutoGenerateSettings(b.settings.DataSettings); err != nil {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := validateAutoGenerateSettings(b.settings); err != nil {
 		return err
 	}
 
@@ -33,29 +46,110 @@ func (b *AppendTableBackend) Register() error {
 }
 
 func (b *AppendTableBackend) Drop() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
 	b.conn.DropAppendTable(b.settings)
+	b.conn.DropSequence(b.settings)
 	return nil
 }
 
-func (b *AppendTableBackend) Scan(batchSize int) (chan mutator.MappedFieldValues, chan error) {
-	outChan := make(chan mutator.MappedFieldValues, batchSize)
-	errorChan := make(chan error, 1)
+// removeOnRollback removes the added entries from the table if the
+// transaction in ctx is rolled back
+func (b *AppendTableBackend) removeOnRollback(ctx context.Context, added []mutator.MappedFieldValues) {
+	b.conn.tx(ctx).undo(func() error {
+		lock := b.conn.tableLock(b.settings)
+		lock.Lock()
+		defer lock.Unlock()
 
-	go func() {
-		defer close(outChan)
-		defer close(errorChan)
+		isAdded := map[uintptr]bool{}
+		for _, entry := range added {
+			isAdded[reflect.ValueOf(entry).Pointer()] = true
+		}
 
-		for _, fields := range b.conn.GetAppendTable(b.settings) {
-			outChan <- fields
+		table := b.conn.GetAppendTable(b.settings)
+		remaining := make(AppendTable, 0, len(table))
+		for _, entry := range table {
+			if !isAdded[reflect.ValueOf(entry).Pointer()] {
+				remaining = append(remaining, entry)
+			}
 		}
-	}()
 
-	return outChan, errorChan
+		b.conn.SetAppendTable(b.settings, remaining)
+		return nil
+	})
+}
+
+func (b *AppendTableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	entries := append(AppendTable{}, b.conn.GetAppendTable(b.settings)...)
+	lock.RUnlock()
+
+	return scanEntries(ctx, b.settings, batchSize, entries, filter, fieldNames)
 }
 
-func (b *AppendTableBackend) Add(entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+func (b *AppendTableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	offset := 0
+	if cursor != "" {
+		values, err := datastore.DecodeCursor(cursor, mutator.MappedFieldValues{offsetCursorKey: 0}, []string{offsetCursorKey})
+		if err != nil {
+			return nil, "", err
+		}
+
+		offset = values[offsetCursorKey].(int)
+		if offset < 0 {
+			return nil, "", InvalidCursorError
+		}
+	}
+
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetAppendTable(b.settings)
+	if offset+limit >= len(table) {
+		// a rolled back transaction can leave the offset past the end
+		if offset > len(table) {
+			offset = len(table)
+		}
+		return append(AppendTable{}, table[offset:]...), "", nil
+	}
+
+	nextCursor, err := datastore.EncodeCursor(mutator.MappedFieldValues{offsetCursorKey: offset + limit})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append(AppendTable{}, table[offset:offset+limit]...), nextCursor, nil
+}
+
+func (b *AppendTableBackend) Aggregate(ctx context.Context, aggregates []*queries.Aggregate, options *queries.AggregateOptions) ([]*queries.AggregateFields, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return aggregateEntries(b.settings, b.conn.GetAppendTable(b.settings), aggregates, options)
+}
+
+func (b *AppendTableBackend) Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	added := make([]mutator.MappedFieldValues, len(entries))
+	for i, entry := range entries {
+		generated, err := b.conn.generateValues(b.settings, entry)
+		if err != nil {
+			return nil, err
+		}
+		added[i] = generated
+	}
+
 	table := b.conn.GetAppendTable(b.settings)
-	table = append(table, entries...)
+	table = append(table, added...)
 	b.conn.SetAppendTable(b.settings, table)
-	return entries, nil
+	b.removeOnRollback(ctx, added)
+	return added, nil
 }
@@ -0,0 +1,15 @@
+package inmemory_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+)
+
+func TestAppendTableBackend(t *testing.T) {
+	datastoretest.RunAppendTableSuite(t, func(t *testing.T) (*inmemory.Connection, *inmemory.AppendTableBackend) {
+		conn := inmemory.NewConnection()
+		return conn, &inmemory.AppendTableBackend{}
+	})
+}
@@ -0,0 +1,88 @@
+package inmemory
+
+import (
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/utils"
+)
+
+// rejectAutoGenerateSettings is used by queues and topics
+func rejectAutoGenerateSettings(rowSettings *fields.RowSettings) error {
+	if len(autoGenerateFieldNames(rowSettings)) > 0 {
+		return AutoGenerateNotSupportedError
+	}
+
+	return nil
+}
+
+// validateAutoGenerateSettings checks every auto generated field has a
+// generator, and that AutoIncrement fields are integers
+func validateAutoGenerateSettings(settings *datastore.TableSettings) error {
+	for _, rowSettings := range []*fields.RowSettings{settings.KeySettings, settings.DataSettings} {
+		for _, fieldName := range autoGenerateFieldNames(rowSettings) {
+			emptyValue := settings.EmptyValues[fieldName]
+			setting := rowSettings.FieldSettings[fieldName]
+			if setting.GeneratorFor(emptyValue) == nil {
+				return AutoGenerateNotSupportedError
+			} else if setting.IsAutoIncrement(emptyValue) && !fields.IsIntField(emptyValue) {
+				return AutoGenerateNotSupportedError
+			}
+		}
+	}
+
+	return nil
+}
+
+func autoGenerateFieldNames(rowSettings *fields.RowSettings) []string {
+	autoGenerateFieldNames := []string{}
+	if rowSettings == nil {
+		return autoGenerateFieldNames
+	}
+
+	for _, fieldName := range rowSettings.FieldOrder {
+		setting := rowSettings.FieldSettings[fieldName]
+		if setting != nil && setting.AutoGenerate {
+			autoGenerateFieldNames = append(autoGenerateFieldNames, fieldName)
+		}
+	}
+
+	return autoGenerateFieldNames
+}
+
+// autoIncrementFieldNames are the fields of a table generated by its sequence
+func autoIncrementFieldNames(settings *datastore.TableSettings) []string {
+	autoIncrementFieldNames := []string{}
+	for _, rowSettings := range []*fields.RowSettings{settings.KeySettings, settings.DataSettings} {
+		for _, fieldName := range autoGenerateFieldNames(rowSettings) {
+			if rowSettings.FieldSettings[fieldName].IsAutoIncrement(settings.EmptyValues[fieldName]) {
+				autoIncrementFieldNames = append(autoIncrementFieldNames, fieldName)
+			}
+		}
+	}
+
+	return autoIncrementFieldNames
+}
+
+// generateValues returns a copy of entry with the next value of the table's
+// sequence for every auto increment field
+func (c *Connection) generateValues(settings *datastore.TableSettings, entry mutator.MappedFieldValues) (mutator.MappedFieldValues, error) {
+	fieldNames := autoIncrementFieldNames(settings)
+	if len(fieldNames) == 0 {
+		return entry, nil
+	}
+
+	id := c.nextSequenceValue(settings)
+	generated := utils.MergeMaps(entry)
+
+	for _, fieldName := range fieldNames {
+		value, err := fields.IntValue(settings.EmptyValues[fieldName], id)
+		if err != nil {
+			return nil, err
+		}
+
+		generated[fieldName] = value
+	}
+
+	return generated, nil
+}
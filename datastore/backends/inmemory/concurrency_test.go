@@ -0,0 +1,296 @@
+package inmemory_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// these tests are most useful with go test -race, they check that every
+// table kind can be used from many goroutines at once
+
+const (
+	numWorkers       = 8
+	entriesPerWorker = 50
+	totalEntries     = numWorkers * entriesPerWorker
+	numScanners      = numWorkers / 2
+)
+
+func registerTables(t *testing.T, registerFuncs ...func(*datastore.ConnectionGroup[*inmemory.Connection]) error) {
+	t.Helper()
+
+	group := datastore.NewConnectionGroup(
+		datastore.WithConnection(inmemory.NewConnection()),
+	)
+	testutils.AssertOk(t, group.RegisterTables(registerFuncs...))
+}
+
+// runWorkers runs work in numWorkers goroutines alongside scan in
+// numScanners goroutines, which repeat until all work is done
+func runWorkers(work func(worker int), scan func()) {
+	done := make(chan struct{})
+	workers := sync.WaitGroup{}
+	scanners := sync.WaitGroup{}
+
+	for i := 0; i < numScanners; i += 1 {
+		scanners.Add(1)
+		go func() {
+			defer scanners.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					scan()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < numWorkers; i += 1 {
+		workers.Add(1)
+		go func(worker int) {
+			defer workers.Done()
+			work(worker)
+		}(i)
+	}
+
+	workers.Wait()
+	close(done)
+	scanners.Wait()
+}
+
+func TestAppendTableConcurrency(t *testing.T) {
+	mockTable := datastoretest.NewMockAppendTable()
+	registerTables(t, datastore.RegisterAppendTable[*inmemory.Connection](mockTable, &inmemory.AppendTableBackend{}))
+
+	runWorkers(func(worker int) {
+		for _, entry := range datastoretest.GenerateNonKeyedEntries(entriesPerWorker, strconv.Itoa(worker)+"-") {
+			_, err := mockTable.Add(entry)
+			testutils.AssertOk(t, err)
+		}
+	}, func() {
+		dataChan, errorChan := mockTable.Scan(10)
+		datastoretest.CollectScan(t, dataChan, errorChan)
+	})
+
+	dataChan, errorChan := mockTable.Scan(10)
+	testutils.AssertEquals(t, totalEntries, len(datastoretest.CollectScan(t, dataChan, errorChan)))
+}
+
+func TestHashTableConcurrency(t *testing.T) {
+	mockTable := datastoretest.NewMockTable()
+	registerTables(t, datastore.RegisterHashTable[*inmemory.Connection](mockTable, &inmemory.HashTableBackend{}))
+
+	runWorkers(func(worker int) {
+		for _, entry := range datastoretest.GenerateEntries(entriesPerWorker, strconv.Itoa(worker)+"-") {
+			_, err := mockTable.Add(entry)
+			testutils.AssertOk(t, err)
+
+			entry.Data.Data = "updated"
+			testutils.AssertOk(t, mockTable.Update(entry))
+
+			found, err := mockTable.Get(entry.Key)
+			testutils.AssertOk(t, err)
+			testutils.AssertEquals(t, 1, len(found))
+		}
+	}, func() {
+		_, err := mockTable.Count()
+		testutils.AssertOk(t, err)
+
+		dataChan, errorChan := mockTable.Scan(10)
+		datastoretest.CollectScan(t, dataChan, errorChan)
+	})
+
+	count, err := mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, totalEntries, count)
+
+	runWorkers(func(worker int) {
+		for _, entry := range datastoretest.GenerateEntries(entriesPerWorker, strconv.Itoa(worker)+"-") {
+			testutils.AssertOk(t, mockTable.Delete(entry.Key))
+		}
+	}, func() {
+		dataChan, errorChan := mockTable.Scan(10)
+		datastoretest.CollectScan(t, dataChan, errorChan)
+	})
+
+	count, err = mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, count)
+}
+
+func TestSortTableConcurrency(t *testing.T) {
+	mockTable := datastoretest.NewMockSortTable()
+	registerTables(t, datastore.RegisterSortTable[*inmemory.Connection](mockTable, &inmemory.SortTableBackend{}))
+
+	runWorkers(func(worker int) {
+		id := strconv.Itoa(worker)
+		for _, entry := range datastoretest.GenerateSortEntries(entriesPerWorker, id) {
+			_, err := mockTable.Add(entry)
+			testutils.AssertOk(t, err)
+		}
+
+		found, err := mockTable.GetWithSortComparator(
+			&datastoretest.MockSortKey{Id: id},
+			&datastoretest.MockSortComparator{},
+		)
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, entriesPerWorker, len(found))
+
+		testutils.AssertOk(t, mockTable.DeleteWithSortComparator(
+			&datastoretest.MockSortKey{Id: id},
+			&datastoretest.MockSortComparator{},
+		))
+	}, func() {
+		_, err := mockTable.Count()
+		testutils.AssertOk(t, err)
+
+		dataChan, errorChan := mockTable.Scan(10)
+		datastoretest.CollectScan(t, dataChan, errorChan)
+	})
+
+	count, err := mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, count)
+}
+
+func TestQueueConcurrency(t *testing.T) {
+	mockQueue := datastoretest.NewMockQueue()
+	registerTables(t, datastore.RegisterQueue[*inmemory.Connection](mockQueue, &inmemory.QueueBackend{}))
+
+	ackedLock := sync.Mutex{}
+	deliveries := map[string]int{}
+	acked := map[string]int{}
+
+	// every message is failed the first time it is recieved, so each one is
+	// redelivered once before it is acked
+	recieve := func() bool {
+		id, message, err := mockQueue.RecieveMessage()
+		if err == inmemory.QueueEmptyError {
+			return false
+		}
+		testutils.AssertOk(t, err)
+
+		ackedLock.Lock()
+		deliveries[message.Data.Data] += 1
+		firstDelivery := deliveries[message.Data.Data] == 1
+		if !firstDelivery {
+			acked[message.Data.Data] += 1
+		}
+		ackedLock.Unlock()
+
+		if firstDelivery {
+			testutils.AssertOk(t, mockQueue.AckFailure(id))
+		} else {
+			testutils.AssertOk(t, mockQueue.AckSuccess(id))
+		}
+
+		return true
+	}
+
+	runWorkers(func(worker int) {
+		messages := datastoretest.GenerateNonKeyedEntries(entriesPerWorker, strconv.Itoa(worker)+"-")
+		testutils.AssertOk(t, mockQueue.SendMessage(messages...))
+	}, func() {
+		recieve()
+	})
+
+	for recieve() {
+	}
+
+	testutils.AssertEquals(t, totalEntries, len(acked))
+	for _, numAcked := range acked {
+		testutils.AssertEquals(t, 1, numAcked)
+	}
+}
+
+func TestQueueWaitConcurrency(t *testing.T) {
+	mockQueue := datastoretest.NewMockQueue()
+	registerTables(t, datastore.RegisterQueue[*inmemory.Connection](mockQueue, &inmemory.QueueBackend{}))
+
+	// consumers start waiting before anything is sent, and are woken by each
+	// SendMessage
+	recieved := make(chan string, totalEntries)
+	consumers := sync.WaitGroup{}
+	for i := 0; i < numWorkers; i += 1 {
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+
+			for {
+				id, message, err := mockQueue.RecieveMessageWait(500 * time.Millisecond)
+				if err == inmemory.QueueEmptyError {
+					return
+				}
+				testutils.AssertOk(t, err)
+				testutils.AssertOk(t, mockQueue.AckSuccess(id))
+				recieved <- message.Data.Data
+			}
+		}()
+	}
+
+	runWorkers(func(worker int) {
+		for _, message := range datastoretest.GenerateNonKeyedEntries(entriesPerWorker, strconv.Itoa(worker)+"-") {
+			testutils.AssertOk(t, mockQueue.SendMessage(message))
+		}
+	}, func() {})
+
+	consumers.Wait()
+	close(recieved)
+
+	unique := map[string]bool{}
+	for data := range recieved {
+		unique[data] = true
+	}
+	testutils.AssertEquals(t, totalEntries, len(unique))
+}
+
+func TestTopicConcurrency(t *testing.T) {
+	mockTopic := datastoretest.NewMockTopic()
+	registerTables(t, datastore.RegisterTopic[*inmemory.Connection](mockTopic, &inmemory.TopicBackend{}))
+
+	subscription, err := mockTopic.Subscribe("testconcurrency")
+	testutils.AssertOk(t, err)
+
+	recievedLock := sync.Mutex{}
+	numRecieved := 0
+	recieve := func() bool {
+		id, _, err := subscription.RecieveMessage()
+		if err == inmemory.QueueEmptyError {
+			return false
+		}
+		testutils.AssertOk(t, err)
+		testutils.AssertOk(t, subscription.AckSuccess(id))
+
+		recievedLock.Lock()
+		numRecieved += 1
+		recievedLock.Unlock()
+		return true
+	}
+
+	runWorkers(func(worker int) {
+		messages := datastoretest.GenerateNonKeyedEntries(entriesPerWorker, strconv.Itoa(worker)+"-")
+		testutils.AssertOk(t, mockTopic.Publish(messages...))
+
+		// subscriptions which come and go should not affect the others
+		churn, err := mockTopic.Subscribe("testchurn" + strconv.Itoa(worker))
+		testutils.AssertOk(t, err)
+		testutils.AssertOk(t, mockTopic.Publish(messages[0]))
+		testutils.AssertOk(t, churn.Unsubscribe())
+	}, func() {
+		recieve()
+	})
+
+	for recieve() {
+	}
+
+	testutils.AssertEquals(t, totalEntries+numWorkers, numRecieved)
+}
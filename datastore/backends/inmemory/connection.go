@@ -1,51 +1,173 @@
 package inmemory
 
-import "github.com/sophielizg/go-libs/datastore"
+import (
+	"sync"
 
+	"github.com/sophielizg/go-libs/datastore"
+)
+
+// Connection guards its maps of tables with mu, and each table has its own
+// lock
 type Connection struct {
+	mu           sync.RWMutex
+	tableLocks   map[string]*sync.RWMutex
 	appendTables map[string]AppendTable
 	hashTables   map[string]HashTable
+	sortTables   map[string]SortTable
 	queues       map[string]*Queue
+	topics       map[string]*Topic
+	indexes      map[string]Indexes
+	sequences    map[string]int64
 }
 
 func (c *Connection) Close() {}
 
+// tableLock returns the lock for the table with the settings name
+func (c *Connection) tableLock(settings *datastore.TableSettings) *sync.RWMutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lock := c.tableLocks[settings.Name]
+	if lock == nil {
+		lock = &sync.RWMutex{}
+		c.tableLocks[settings.Name] = lock
+	}
+
+	return lock
+}
+
 func (c *Connection) GetAppendTable(settings *datastore.TableSettings) AppendTable {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.appendTables[settings.Name]
 }
 
 func (c *Connection) SetAppendTable(settings *datastore.TableSettings, newTable AppendTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.appendTables[settings.Name] = newTable
 }
 
 func (c *Connection) DropAppendTable(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.appendTables[settings.Name] = nil
 }
 
 func (c *Connection) GetHashTable(settings *datastore.TableSettings) HashTable {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.hashTables[settings.Name]
 }
 
 func (c *Connection) SetHashTable(settings *datastore.TableSettings, newTable HashTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.hashTables[settings.Name] = newTable
 }
 
 func (c *Connection) DropHashTable(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.hashTables[settings.Name] = nil
 }
 
+func (c *Connection) GetSortTable(settings *datastore.TableSettings) SortTable {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sortTables[settings.Name]
+}
+
+func (c *Connection) SetSortTable(settings *datastore.TableSettings, newTable SortTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sortTables[settings.Name] = newTable
+}
+
+func (c *Connection) DropSortTable(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sortTables[settings.Name] = nil
+}
+
 func (c *Connection) GetQueue(settings *datastore.TableSettings) *Queue {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.queues[settings.Name]
 }
 
+func (c *Connection) SetQueue(settings *datastore.TableSettings, queue *Queue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queues[settings.Name] = queue
+}
+
 func (c *Connection) DropQueue(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.queues[settings.Name] = nil
 }
 
+func (c *Connection) GetTopic(settings *datastore.TableSettings) *Topic {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.topics[settings.Name]
+}
+
+func (c *Connection) SetTopic(settings *datastore.TableSettings, newTopic *Topic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics[settings.Name] = newTopic
+}
+
+func (c *Connection) DropTopic(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics[settings.Name] = nil
+}
+
+func (c *Connection) GetIndexes(settings *datastore.TableSettings) Indexes {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.indexes[settings.Name]
+}
+
+func (c *Connection) SetIndexes(settings *datastore.TableSettings, indexes Indexes) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.indexes[settings.Name] = indexes
+}
+
+func (c *Connection) DropIndexes(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.indexes[settings.Name] = nil
+}
+
+// nextSequenceValue increments the sequence of auto generated integers for
+// the table with the settings name, which starts from one
+func (c *Connection) nextSequenceValue(settings *datastore.TableSettings) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sequences[settings.Name] += 1
+	return c.sequences[settings.Name]
+}
+
+func (c *Connection) DropSequence(settings *datastore.TableSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sequences, settings.Name)
+}
+
 func NewConnection() *Connection {
 	return &Connection{
+		tableLocks:   map[string]*sync.RWMutex{},
 		appendTables: map[string]AppendTable{},
 		hashTables:   map[string]HashTable{},
+		sortTables:   map[string]SortTable{},
 		queues:       map[string]*Queue{},
+		topics:       map[string]*Topic{},
+		indexes:      map[string]Indexes{},
+		sequences:    map[string]int64{},
 	}
 }
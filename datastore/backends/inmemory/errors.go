@@ -1,9 +1,39 @@
 package inmemory
 
-import "errors"
+import (
+	"errors"
 
-var AutoGenerateNotSupportedError = errors.New("auto generate fields are not supported for inmemory backends")
+	"github.com/sophielizg/go-libs/datastore"
+)
 
-var KeyExistsError = errors.New("cannot add a key that already exists")
+var AutoGenerateNotSupportedError = errors.New("auto generate is not supported for this field in inmemory backends")
 
-var KeyDoesNotExistError = errors.New("cannot update a key that does not already exist")
+var KeyExistsError = datastore.KeyExistsError
+
+var KeyDoesNotExistError = datastore.KeyDoesNotExistError
+
+var IndexDoesNotExistError = datastore.IndexDoesNotExistError
+
+var InvalidIndexError = datastore.InvalidIndexError
+
+var FieldDoesNotExistError = datastore.FieldDoesNotExistError
+
+var InvalidCursorError = datastore.InvalidCursorError
+
+var AggregateTypeError = datastore.AggregateTypeError
+
+var ConditionFailedError = datastore.ConditionFailedError
+
+var QueueEmptyError = datastore.QueueEmptyError
+
+var QueueDoesNotExistError = datastore.QueueDoesNotExistError
+
+var TopicDoesNotExistError = datastore.TopicDoesNotExistError
+
+var SubscriptionDoesNotExistError = datastore.SubscriptionDoesNotExistError
+
+var DeadLetterQueueDoesNotExistError = datastore.DeadLetterQueueDoesNotExistError
+
+var InvalidDeadLetterQueueError = datastore.InvalidDeadLetterQueueError
+
+type ConflictError = datastore.ConflictError
@@ -1,8 +1,13 @@
 package inmemory
 
 import (
+	"context"
+	"sort"
+
 	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
 	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
 )
 
 type HashTable = map[string]mutator.MappedFieldValues
@@ -21,9 +26,15 @@ func (b *HashTableBackend) SetConnection(conn *Connection) {
 }
 
 func (b *HashTableBackend) Register() error {
-	if err := validateAutoGenerateSettings(b.settings.DataSettings); err != nil {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := validateAutoGenerateSettings(b.settings); err != nil {
+		return err
+	} else if err := validateVersionSettings(b.settings); err != nil {
 		return err
-	} else if err := validateAutoGenerateSettings(b.settings.KeySettings); err != nil {
+	} else if err := b.settings.ValidateIndexes(); err != nil {
 		return err
 	}
 
@@ -31,35 +42,184 @@ func (b *HashTableBackend) Register() error {
 		b.conn.SetHashTable(b.settings, HashTable{})
 	}
 
+	if b.conn.GetIndexes(b.settings) == nil {
+		indexes := newIndexes(b.settings)
+		for keyStr, entry := range b.conn.GetHashTable(b.settings) {
+			if err := indexes.replace(b.settings, keyStr, nil, entry); err != nil {
+				return err
+			}
+		}
+		b.conn.SetIndexes(b.settings, indexes)
+	}
+
 	return nil
 }
 
 func (b *HashTableBackend) Drop() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
 	b.conn.DropHashTable(b.settings)
+	b.conn.DropIndexes(b.settings)
+	b.conn.DropSequence(b.settings)
 	return nil
 }
 
-func (b *HashTableBackend) Count() (int, error) {
+func (b *HashTableBackend) Count(ctx context.Context) (int, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
 	return len(b.conn.GetHashTable(b.settings)), nil
 }
 
-func (b *HashTableBackend) Scan(batchSize int) (chan mutator.MappedFieldValues, chan error) {
-	outChan := make(chan mutator.MappedFieldValues, batchSize)
-	errorChan := make(chan error, 1)
+// saveEntry keeps the entry stored under keyStr to put back if the
+// transaction in ctx is rolled back
+func (b *HashTableBackend) saveEntry(ctx context.Context, keyStr string) {
+	b.conn.tx(ctx).save(entryKey{b.settings.Name, keyStr}, func() func() error {
+		saved := b.conn.GetHashTable(b.settings)[keyStr]
+
+		return func() error {
+			lock := b.conn.tableLock(b.settings)
+			lock.Lock()
+			defer lock.Unlock()
 
-	go func() {
-		defer close(outChan)
-		defer close(errorChan)
+			table := b.conn.GetHashTable(b.settings)
+			if table == nil {
+				return nil
+			}
 
-		for _, entry := range b.conn.GetHashTable(b.settings) {
-			outChan <- entry
+			if err := b.conn.GetIndexes(b.settings).replace(b.settings, keyStr, table[keyStr], saved); err != nil {
+				return err
+			}
+
+			if saved == nil {
+				delete(table, keyStr)
+			} else {
+				table[keyStr] = saved
+			}
+			return nil
 		}
-	}()
+	})
+}
+
+// put stores entry in the table and indexes, replacing the entry with the
+// same key if there is one, and adds the step reversing it to undo
+func (b *HashTableBackend) put(ctx context.Context, table HashTable, indexes Indexes, keyStr string, entry mutator.MappedFieldValues, undo *undoLog) error {
+	b.saveEntry(ctx, keyStr)
+
+	existing := table[keyStr]
+	if err := indexes.replace(b.settings, keyStr, existing, entry); err != nil {
+		return err
+	}
+
+	table[keyStr] = entry
+	undo.add(func() {
+		// later steps are undone first, so existing cannot break an index
+		indexes.replace(b.settings, keyStr, entry, existing)
+		if existing == nil {
+			delete(table, keyStr)
+		} else {
+			table[keyStr] = existing
+		}
+	})
+	return nil
+}
+
+// add stores entry with new values for its auto generated fields, failing if
+// an entry with the same key exists
+func (b *HashTableBackend) add(ctx context.Context, table HashTable, indexes Indexes, entry mutator.MappedFieldValues, undo *undoLog) (mutator.MappedFieldValues, error) {
+	entry, err := b.conn.generateValues(b.settings, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	keyStr, err := stringifyKey(getKeyFromEntry(b.settings, entry))
+	if err != nil {
+		return nil, err
+	} else if table[keyStr] != nil {
+		return nil, KeyExistsError
+	}
+
+	if err := b.put(ctx, table, indexes, keyStr, entry, undo); err != nil {
+		return nil, err
+	}
 
-	return outChan, errorChan
+	return entry, nil
 }
 
-func (b *HashTableBackend) Get(keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+func (b *HashTableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	table := b.conn.GetHashTable(b.settings)
+	entries := make([]mutator.MappedFieldValues, 0, len(table))
+	for _, entry := range table {
+		entries = append(entries, entry)
+	}
+	lock.RUnlock()
+
+	return scanEntries(ctx, b.settings, batchSize, entries, filter, fieldNames)
+}
+
+// ScanPage orders entries by their stringified keys
+func (b *HashTableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	cursorKey, err := decodeKeyCursor(b.settings, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursorKeyStr := ""
+	if cursorKey != nil {
+		if cursorKeyStr, err = stringifyKey(cursorKey); err != nil {
+			return nil, "", err
+		}
+	}
+
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetHashTable(b.settings)
+	keyStrs := []string{}
+	for keyStr := range table {
+		if cursorKey == nil || keyStr > cursorKeyStr {
+			keyStrs = append(keyStrs, keyStr)
+		}
+	}
+	sort.Strings(keyStrs)
+
+	if len(keyStrs) > limit+1 {
+		keyStrs = keyStrs[:limit+1]
+	}
+
+	entries := make([]mutator.MappedFieldValues, len(keyStrs))
+	for i, keyStr := range keyStrs {
+		entries[i] = table[keyStr]
+	}
+
+	return keyPage(b.settings, entries, limit)
+}
+
+func (b *HashTableBackend) Aggregate(ctx context.Context, aggregates []*queries.Aggregate, options *queries.AggregateOptions) ([]*queries.AggregateFields, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetHashTable(b.settings)
+	entries := make([]mutator.MappedFieldValues, 0, len(table))
+	for _, entry := range table {
+		entries = append(entries, entry)
+	}
+
+	return aggregateEntries(b.settings, entries, aggregates, options)
+}
+
+func (b *HashTableBackend) Get(ctx context.Context, keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
 	table := b.conn.GetHashTable(b.settings)
 
 	data := make([]mutator.MappedFieldValues, 0, len(keys))
@@ -77,45 +237,165 @@ func (b *HashTableBackend) Get(keys []mutator.MappedFieldValues) ([]mutator.Mapp
 	return data, nil
 }
 
-func (b *HashTableBackend) Add(entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+func (b *HashTableBackend) Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
 	table := b.conn.GetHashTable(b.settings)
 
+	added := make([]mutator.MappedFieldValues, len(entries))
+	for i, entry := range entries {
+		var err error
+		if added[i], err = b.add(ctx, table, b.conn.GetIndexes(b.settings), entry, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	b.conn.SetHashTable(b.settings, table)
+	return added, nil
+}
+
+func (b *HashTableBackend) Update(ctx context.Context, entries []mutator.MappedFieldValues) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return b.update(ctx, entries)
+}
+
+func (b *HashTableBackend) update(ctx context.Context, entries []mutator.MappedFieldValues) (err error) {
+	table, indexes := b.conn.GetHashTable(b.settings), b.conn.GetIndexes(b.settings)
+
+	undo := undoLog{}
+	defer func() {
+		if err != nil {
+			undo.run()
+		}
+	}()
+
 	for _, entry := range entries {
 		key := getKeyFromEntry(b.settings, entry)
 		keyStr, err := stringifyKey(key)
 		if err != nil {
-			return nil, err
-		} else if table[keyStr] != nil {
-			return nil, KeyExistsError
+			return err
+		} else if table[keyStr] == nil {
+			return KeyDoesNotExistError
 		}
 
-		table[keyStr] = entry
+		updated, err := nextVersion(b.settings, table[keyStr], entry)
+		if err != nil {
+			return err
+		}
+
+		if err := b.put(ctx, table, indexes, keyStr, updated, &undo); err != nil {
+			return err
+		}
 	}
 
-	b.conn.SetHashTable(b.settings, table)
-	return entries, nil
+	return nil
 }
 
-func (b *HashTableBackend) Update(entries []mutator.MappedFieldValues) error {
+func (b *HashTableBackend) UpdateIf(ctx context.Context, entries []mutator.MappedFieldValues, predicate func(existing mutator.MappedFieldValues) (bool, error)) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
 	table := b.conn.GetHashTable(b.settings)
 
+	// every entry is checked before any are updated
 	for _, entry := range entries {
-		key := getKeyFromEntry(b.settings, entry)
-		keyStr, err := stringifyKey(key)
+		keyStr, err := stringifyKey(getKeyFromEntry(b.settings, entry))
 		if err != nil {
 			return err
 		} else if table[keyStr] == nil {
 			return KeyDoesNotExistError
 		}
 
-		table[keyStr] = entry
+		if matches, err := predicate(table[keyStr]); err != nil {
+			return err
+		} else if !matches {
+			return ConditionFailedError
+		}
+	}
+
+	return b.update(ctx, entries)
+}
+
+func (b *HashTableBackend) Upsert(ctx context.Context, entries []mutator.MappedFieldValues) (_ []mutator.MappedFieldValues, err error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table, indexes := b.conn.GetHashTable(b.settings), b.conn.GetIndexes(b.settings)
+
+	undo := undoLog{}
+	defer func() {
+		if err != nil {
+			undo.run()
+		}
+	}()
+
+	upserted := make([]mutator.MappedFieldValues, len(entries))
+	for i, entry := range entries {
+		keyStr, err := stringifyKey(getKeyFromEntry(b.settings, entry))
+		if err != nil {
+			return nil, err
+		}
+
+		if table[keyStr] == nil {
+			if upserted[i], err = b.add(ctx, table, indexes, entry, &undo); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		entry, err = nextVersion(b.settings, table[keyStr], entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := b.put(ctx, table, indexes, keyStr, entry, &undo); err != nil {
+			return nil, err
+		}
+		upserted[i] = entry
+	}
+
+	return upserted, nil
+}
+
+func (b *HashTableBackend) AddIfAbsent(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table := b.conn.GetHashTable(b.settings)
+
+	added := []mutator.MappedFieldValues{}
+	for _, entry := range entries {
+		keyStr, err := stringifyKey(getKeyFromEntry(b.settings, entry))
+		if err != nil {
+			return nil, err
+		} else if table[keyStr] != nil {
+			continue
+		}
+
+		entry, err = b.add(ctx, table, b.conn.GetIndexes(b.settings), entry, nil)
+		if err != nil {
+			return nil, err
+		}
+		added = append(added, entry)
 	}
 
 	b.conn.SetHashTable(b.settings, table)
-	return nil
+	return added, nil
 }
 
-func (b *HashTableBackend) Delete(keys []mutator.MappedFieldValues) error {
+func (b *HashTableBackend) Delete(ctx context.Context, keys []mutator.MappedFieldValues) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
 	table := b.conn.GetHashTable(b.settings)
 
 	for _, key := range keys {
@@ -126,9 +406,21 @@ func (b *HashTableBackend) Delete(keys []mutator.MappedFieldValues) error {
 			return KeyDoesNotExistError
 		}
 
+		b.saveEntry(ctx, keyStr)
+		if err := b.conn.GetIndexes(b.settings).replace(b.settings, keyStr, table[keyStr], nil); err != nil {
+			return err
+		}
 		delete(table, keyStr)
 	}
 
 	b.conn.SetHashTable(b.settings, table)
 	return nil
 }
+
+func (b *HashTableBackend) GetByIndex(ctx context.Context, indexName string, values mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return b.conn.GetIndexes(b.settings).get(b.settings, indexName, values)
+}
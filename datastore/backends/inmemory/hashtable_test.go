@@ -1,42 +1,73 @@
 package inmemory_test
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/sophielizg/go-libs/datastore"
 	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
 	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/testutils"
 )
 
 func TestHashTableBackend(t *testing.T) {
+	datastoretest.RunHashTableSuite(t, func(t *testing.T) (*inmemory.Connection, *inmemory.HashTableBackend) {
+		conn := inmemory.NewConnection()
+		return conn, &inmemory.HashTableBackend{}
+	})
+}
+
+func TestHashTableAutoGenerateUUID(t *testing.T) {
 	conn := inmemory.NewConnection()
-	mockTable := datastoretest.NewMockTable()
-	mockTableBackend := &inmemory.HashTableBackend{}
-
-	group := datastore.NewConnectionGroup(
-		datastore.WithConnection(conn),
-	)
-	err := group.RegisterTables(
-		datastore.RegisterHashTable[*inmemory.Connection](mockTable, mockTableBackend),
-	)
+	mockTable := &datastoretest.MockTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestAutoGenerateUUID"),
+			datastore.WithDataSettings(datastoretest.MockDataSettings),
+			datastore.WithKeySettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(fields.WithAutoGenerate(datastoretest.IdKey)),
+				FieldOrder:    fields.OrderedFieldKeys{datastoretest.IdKey},
+			}),
+		),
+	}
+	group := datastore.NewConnectionGroup(datastore.WithConnection(conn))
+	testutils.AssertOk(t, group.RegisterTables(datastore.RegisterHashTable[*inmemory.Connection](mockTable, &inmemory.HashTableBackend{})))
+
+	entries := datastoretest.GenerateEntries(3, "")
+	entries[0].Key.Id = ""
+	entries[1].Key.Id = ""
+
+	added, err := mockTable.Add(entries...)
 	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, len(added))
+	if len(added) == 3 {
+		uuidPattern := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+		testutils.AssertTrue(t, uuidPattern.MatchString(added[0].Key.Id))
+		testutils.AssertTrue(t, uuidPattern.MatchString(added[1].Key.Id))
+		testutils.AssertTrue(t, added[0].Key.Id != added[1].Key.Id)
+		// ids which are set are kept
+		testutils.AssertEquals(t, "2", added[2].Key.Id)
+	}
 
-	testutils.Case(t, "count", func(t *testing.T) {
-		datastoretest.TestHashTableCount(t, mockTable)
-	})
-	testutils.Case(t, "get", func(t *testing.T) {
-		datastoretest.TestHashTableGet(t, mockTable)
-	})
-	testutils.Case(t, "add", func(t *testing.T) {
-		datastoretest.TestHashTableAdd(t, mockTable)
-	})
-	testutils.Case(t, "update", func(t *testing.T) {
-		datastoretest.TestHashTableUpdate(t, mockTable)
-	})
-	testutils.Case(t, "delete", func(t *testing.T) {
-		datastoretest.TestHashTableDelete(t, mockTable)
-	})
+	count, err := mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, count)
+}
+
+func TestHashTableAutoGenerateUnsupported(t *testing.T) {
+	conn := inmemory.NewConnection()
+	mockTable := &datastoretest.MockAggregateTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestAutoGenerateUnsupported"),
+			datastore.WithDataSettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(fields.WithAutoGenerate(datastoretest.PriceKey)),
+				FieldOrder:    datastoretest.MockAggregateDataSettings.FieldOrder,
+			}),
+			datastore.WithKeySettings(datastoretest.MockKeySettings),
+		),
+	}
 
-	mockTableBackend.Drop()
+	group := datastore.NewConnectionGroup(datastore.WithConnection(conn))
+	err := group.RegisterTables(datastore.RegisterHashTable[*inmemory.Connection](mockTable, &inmemory.HashTableBackend{}))
+	testutils.AssertErrorEquals(t, inmemory.AutoGenerateNotSupportedError, err)
 }
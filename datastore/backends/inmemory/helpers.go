@@ -1,25 +1,25 @@
 package inmemory
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
 	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/datastore/mutator"
 )
 
-func validateAutoGenerateSettings(settings *fields.RowSettings) error {
-	if settings == nil {
+// validateVersionSettings checks the version field, if there is one, can be
+// incremented
+func validateVersionSettings(settings *datastore.TableSettings) error {
+	versionFieldName := fields.VersionFieldName(settings.DataSettings)
+	if versionFieldName == "" {
 		return nil
 	}
 
-	for _, fieldSetting := range settings.FieldSettings {
-		if fieldSetting.AutoGenerate {
-			return AutoGenerateNotSupportedError
-		}
-	}
-
-	return nil
+	_, err := fields.NextVersion(settings.EmptyValues[versionFieldName])
+	return err
 }
 
 func stringifyKey(key mutator.MappedFieldValues) (string, error) {
@@ -51,3 +51,126 @@ func getKeyFromEntry(settings *datastore.TableSettings, entry mutator.MappedFiel
 
 	return key
 }
+
+// scanEntries sends the entries matching filter, projected to fieldNames
+func scanEntries(ctx context.Context, settings *datastore.TableSettings, batchSize int, entries []mutator.MappedFieldValues, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	outChan := make(chan mutator.MappedFieldValues, batchSize)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer close(outChan)
+		defer close(errorChan)
+
+		if err := settings.ValidateScan(filter, fieldNames); err != nil {
+			errorChan <- err
+			return
+		}
+
+		for _, entry := range entries {
+			matches, err := filter.Matches(entry)
+			if err != nil {
+				errorChan <- err
+				return
+			} else if !matches {
+				continue
+			}
+
+			select {
+			case outChan <- projectEntry(entry, fieldNames):
+			case <-ctx.Done():
+				errorChan <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return outChan, errorChan
+}
+
+// projectEntry returns a copy of entry with only the fields in fieldNames, or
+// entry itself if there are none
+func projectEntry(entry mutator.MappedFieldValues, fieldNames []string) mutator.MappedFieldValues {
+	if len(fieldNames) == 0 {
+		return entry
+	}
+
+	projected := mutator.MappedFieldValues{}
+	for _, fieldName := range fieldNames {
+		projected[fieldName] = entry[fieldName]
+	}
+
+	return projected
+}
+
+// decodeKeyCursor returns the key in a cursor made by keyPage, or nil if the
+// cursor is empty
+func decodeKeyCursor(settings *datastore.TableSettings, cursor string) (mutator.MappedFieldValues, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	return datastore.DecodeCursor(cursor, settings.EmptyValues, settings.KeySettings.FieldOrder)
+}
+
+// keyPage returns the first limit entries, with a cursor holding the key of
+// the last one if there are more
+func keyPage(settings *datastore.TableSettings, entries []mutator.MappedFieldValues, limit int) ([]mutator.MappedFieldValues, string, error) {
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+
+	entries = entries[:limit]
+	cursor, err := datastore.EncodeCursor(getKeyFromEntry(settings, entries[limit-1]))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entries, cursor, nil
+}
+
+// nextVersion checks entry has the version of existing, and returns a copy
+// with the version incremented
+func nextVersion(settings *datastore.TableSettings, existing, entry mutator.MappedFieldValues) (mutator.MappedFieldValues, error) {
+	versionFieldName := fields.VersionFieldName(settings.DataSettings)
+	if versionFieldName == "" {
+		return entry, nil
+	}
+
+	if existing[versionFieldName] != entry[versionFieldName] {
+		return nil, &ConflictError{
+			Key:      getKeyFromEntry(settings, existing),
+			Expected: entry[versionFieldName],
+			Actual:   existing[versionFieldName],
+		}
+	}
+
+	version, err := fields.NextVersion(entry[versionFieldName])
+	if err != nil {
+		return nil, err
+	}
+
+	updated := mutator.MappedFieldValues{}
+	for fieldName, value := range entry {
+		updated[fieldName] = value
+	}
+	updated[versionFieldName] = version
+
+	return updated, nil
+}
+
+// undoLog holds the steps which reverse a multi-entry change, so the entries
+// changed before one fails can be put back
+type undoLog []func()
+
+func (u *undoLog) add(undo func()) {
+	if u != nil {
+		*u = append(*u, undo)
+	}
+}
+
+// run undoes the steps in the opposite order they were made
+func (u undoLog) run() {
+	for i := len(u) - 1; i >= 0; i-- {
+		u[i]()
+	}
+}
@@ -0,0 +1,112 @@
+package inmemory
+
+import (
+	"sort"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+// Index maps the stringified values of an index's fields to the entries with
+// those values, by their stringified key
+type Index = map[string]map[string]mutator.MappedFieldValues
+
+// Indexes holds every index of a table by name
+type Indexes map[string]Index
+
+func newIndexes(settings *datastore.TableSettings) Indexes {
+	indexes := Indexes{}
+	for _, index := range settings.Indexes {
+		indexes[index.Name] = Index{}
+	}
+
+	return indexes
+}
+
+func indexValues(index *datastore.Index, entry mutator.MappedFieldValues) (string, error) {
+	values := mutator.MappedFieldValues{}
+	for _, fieldName := range index.FieldNames {
+		values[fieldName] = entry[fieldName]
+	}
+
+	return stringifyKey(values)
+}
+
+// replace swaps existing for entry in every index, either may be nil. It
+// returns KeyExistsError without changes if entry breaks a unique index.
+func (ix Indexes) replace(settings *datastore.TableSettings, keyStr string, existing, entry mutator.MappedFieldValues) error {
+	if entry != nil {
+		for _, index := range settings.Indexes {
+			if !index.Unique {
+				continue
+			}
+
+			valuesStr, err := indexValues(index, entry)
+			if err != nil {
+				return err
+			}
+
+			for indexedKeyStr := range ix[index.Name][valuesStr] {
+				if indexedKeyStr != keyStr {
+					return KeyExistsError
+				}
+			}
+		}
+	}
+
+	for _, index := range settings.Indexes {
+		if existing != nil {
+			valuesStr, err := indexValues(index, existing)
+			if err != nil {
+				return err
+			}
+
+			delete(ix[index.Name][valuesStr], keyStr)
+			if len(ix[index.Name][valuesStr]) == 0 {
+				delete(ix[index.Name], valuesStr)
+			}
+		}
+
+		if entry != nil {
+			valuesStr, err := indexValues(index, entry)
+			if err != nil {
+				return err
+			}
+
+			if ix[index.Name][valuesStr] == nil {
+				ix[index.Name][valuesStr] = map[string]mutator.MappedFieldValues{}
+			}
+			ix[index.Name][valuesStr][keyStr] = entry
+		}
+	}
+
+	return nil
+}
+
+// get returns the entries in the index named indexName with the same values
+// as values, ordered by their stringified keys
+func (ix Indexes) get(settings *datastore.TableSettings, indexName string, values mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	index := settings.Index(indexName)
+	if index == nil {
+		return nil, IndexDoesNotExistError
+	}
+
+	valuesStr, err := indexValues(index, values)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := ix[indexName][valuesStr]
+	keyStrs := make([]string, 0, len(indexed))
+	for keyStr := range indexed {
+		keyStrs = append(keyStrs, keyStr)
+	}
+	sort.Strings(keyStrs)
+
+	entries := make([]mutator.MappedFieldValues, len(keyStrs))
+	for i, keyStr := range keyStrs {
+		entries[i] = indexed[keyStr]
+	}
+
+	return entries, nil
+}
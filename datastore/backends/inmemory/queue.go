@@ -2,98 +2,199 @@ package inmemory
 
 import (
 	"container/list"
+	"context"
 	"errors"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/sophielizg/go-libs/datastore"
 	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/utils"
 )
 
-type InFlightMessages = map[string]mutator.MappedFieldValues
+// InFlightMessage is a recieved message waiting to be acked, a zero
+// visibleAt never expires
+type InFlightMessage struct {
+	message    mutator.MappedFieldValues
+	deliveries int
+	visibleAt  time.Time
+}
+
+type InFlightMessages = map[string]*InFlightMessage
 
 type QueueItem struct {
-	id      int
-	message mutator.MappedFieldValues
+	id         int
+	message    mutator.MappedFieldValues
+	deliveries int
 }
 
+// Queue is safe for concurrent use, every method holds mu. messageAdded is
+// closed and replaced whenever messages are added.
 type Queue struct {
-	messageQueue     *list.List
-	inFlightMessages InFlightMessages
+	mu                  sync.Mutex
+	messageQueue        *list.List
+	inFlightMessages    InFlightMessages
+	lastId              int
+	messageAdded        chan struct{}
+	visibilityTimeout   time.Duration
+	maxDeliveryAttempts int
+	deadLetterQueue     func() *Queue
 }
 
-type QueueBackend struct {
-	conn     *Connection
-	settings *datastore.TableSettings
+func newQueue(conn *Connection, settings *datastore.TableSettings) *Queue {
+	deadLetterSettings := datastore.NewTableSettings(
+		datastore.WithTableName(settings.DeadLetterQueueName),
+	)
+
+	return &Queue{
+		messageQueue:        &list.List{},
+		inFlightMessages:    InFlightMessages{},
+		messageAdded:        make(chan struct{}),
+		visibilityTimeout:   settings.VisibilityTimeout,
+		maxDeliveryAttempts: settings.MaxDeliveryAttempts,
+		deadLetterQueue: func() *Queue {
+			return conn.GetQueue(deadLetterSettings)
+		},
+	}
 }
 
-func (b *QueueBackend) SetSettings(settings *datastore.TableSettings) {
-	b.settings = settings
+// broadcast wakes every waiting consumer, it must be called while holding mu
+func (q *Queue) broadcast() {
+	close(q.messageAdded)
+	q.messageAdded = make(chan struct{})
 }
 
-func (b *QueueBackend) SetConnection(conn *Connection) {
-	b.conn = conn
+func (q *Queue) isDeadLetter(inFlight *InFlightMessage) bool {
+	return q.maxDeliveryAttempts > 0 && inFlight.deliveries >= q.maxDeliveryAttempts
 }
 
-func (b *QueueBackend) Register() error {
-	if err := validateAutoGenerateSettings(b.settings.DataSettings); err != nil {
-		return err
+// deadLetters are pushed to the dead letter queue after mu is released
+type deadLetters struct {
+	queue    *Queue
+	messages []mutator.MappedFieldValues
+}
+
+func (d *deadLetters) push(tx *Tx) {
+	if len(d.messages) > 0 {
+		d.queue.push(tx, d.messages)
+	}
+}
+
+// pushFront returns in flight messages to the queue in id order, or takes
+// them out as dead letters once they run out of delivery attempts. It must be
+// called while holding mu.
+func (q *Queue) pushFront(messageIds []string) (*deadLetters, error) {
+	dead := &deadLetters{}
+	if q.maxDeliveryAttempts > 0 {
+		dead.queue = q.deadLetterQueue()
 	}
 
-	if queue := b.conn.GetQueue(b.settings); queue == nil {
-		queue = &Queue{
-			messageQueue:     &list.List{},
-			inFlightMessages: InFlightMessages{},
+	ids := []int{}
+	for _, messageId := range messageIds {
+		inFlight := q.inFlightMessages[messageId]
+		if dead.queue != nil && q.isDeadLetter(inFlight) {
+			dead.messages = append(dead.messages, inFlight.message)
+			delete(q.inFlightMessages, messageId)
+			continue
+		}
+
+		id, err := strconv.Atoi(messageId)
+		if err != nil {
+			return dead, err
 		}
+
+		ids = append(ids, id)
 	}
 
-	return nil
-}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	for _, id := range ids {
+		messageId := strconv.Itoa(id)
+		inFlight := q.inFlightMessages[messageId]
+		q.messageQueue.PushFront(QueueItem{
+			id:         id,
+			message:    inFlight.message,
+			deliveries: inFlight.deliveries,
+		})
+		delete(q.inFlightMessages, messageId)
+	}
 
-func (b *QueueBackend) Drop() error {
-	b.conn.DropQueue(b.settings)
-	return nil
+	if len(ids) > 0 {
+		q.broadcast()
+	}
+
+	return dead, nil
 }
 
-func (b *QueueBackend) Count() (int, error) {
-	queue := b.conn.GetQueue(b.settings)
-	return queue.messageQueue.Len(), nil
+// requeueExpired returns expired in flight messages to the queue, and when
+// the next one expires. It must be called while holding mu.
+func (q *Queue) requeueExpired() (time.Time, *deadLetters, error) {
+	now := time.Now()
+	expired := []string{}
+	nextExpiry := time.Time{}
+
+	for messageId, inFlight := range q.inFlightMessages {
+		if inFlight.visibleAt.IsZero() {
+			continue
+		} else if !inFlight.visibleAt.After(now) {
+			expired = append(expired, messageId)
+		} else if nextExpiry.IsZero() || inFlight.visibleAt.Before(nextExpiry) {
+			nextExpiry = inFlight.visibleAt
+		}
+	}
+
+	dead, err := q.pushFront(expired)
+	return nextExpiry, dead, err
 }
 
-func (b *QueueBackend) HasMessage() (bool, error) {
-	count, err := b.Count()
-	if err != nil {
-		return false, err
+// withRequeued runs f while holding mu after requeueing expired messages,
+// then pushes any dead letters once mu is released
+func (q *Queue) withRequeued(f func() error) error {
+	q.mu.Lock()
+	_, dead, err := q.requeueExpired()
+	if err == nil {
+		err = f()
 	}
+	q.mu.Unlock()
 
-	return count > 0, nil
+	dead.push(nil)
+	return err
 }
 
-func (b *QueueBackend) SendMessage(messages []mutator.MappedFieldValues) error {
-	queue := b.conn.GetQueue(b.settings)
-	back := queue.messageQueue.Back()
+func (q *Queue) count() (int, error) {
+	count := 0
+	err := q.withRequeued(func() error {
+		count = q.messageQueue.Len()
+		return nil
+	})
 
-	lastItem, ok := back.Value.(QueueItem)
-	if !ok {
-		// this should never happen
-		return errors.New("send message failed, item with invalid type in queue")
-	}
+	return count, err
+}
+
+func (q *Queue) push(tx *Tx, messages []mutator.MappedFieldValues) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 
-	lastId := lastItem.id
 	for _, message := range messages {
-		lastId += 1
-		queue.messageQueue.PushBack(QueueItem{
-			id:      lastId,
+		q.lastId += 1
+		q.saveMessage(tx, strconv.Itoa(q.lastId))
+		q.messageQueue.PushBack(QueueItem{
+			id:      q.lastId,
 			message: message,
 		})
 	}
 
-	return nil
+	q.broadcast()
 }
 
-func (b *QueueBackend) RecieveMessage() (string, mutator.MappedFieldValues, error) {
-	queue := b.conn.GetQueue(b.settings)
-	popped := queue.messageQueue.Front()
-	queue.messageQueue.Remove(popped)
+// recieveLocked pops the front message, it must be called while holding mu
+// after requeueExpired
+func (q *Queue) recieveLocked(tx *Tx) (string, mutator.MappedFieldValues, error) {
+	popped := q.messageQueue.Front()
+	if popped == nil {
+		return "", nil, QueueEmptyError
+	}
 
 	item, ok := popped.Value.(QueueItem)
 	if !ok {
@@ -101,43 +202,397 @@ func (b *QueueBackend) RecieveMessage() (string, mutator.MappedFieldValues, erro
 		return "", nil, errors.New("recieve message failed, item with invalid type in queue")
 	}
 
+	q.saveMessage(tx, strconv.Itoa(item.id))
+	q.messageQueue.Remove(popped)
+
+	inFlight := &InFlightMessage{
+		message:    item.message,
+		deliveries: item.deliveries + 1,
+	}
+	if q.visibilityTimeout > 0 {
+		inFlight.visibleAt = time.Now().Add(q.visibilityTimeout)
+	}
+
 	idStr := strconv.Itoa(item.id)
-	queue.inFlightMessages[idStr] = item.message
+	q.inFlightMessages[idStr] = inFlight
 	return idStr, item.message, nil
 }
 
-func (b *QueueBackend) AckSuccess(messageIds []string) error {
-	queue := b.conn.GetQueue(b.settings)
+func (q *Queue) recieve(tx *Tx) (id string, message mutator.MappedFieldValues, deliveries int, err error) {
+	err = q.withRequeued(func() error {
+		if id, message, err = q.recieveLocked(tx); err != nil {
+			return err
+		}
 
+		deliveries = q.inFlightMessages[id].deliveries
+		return nil
+	})
+
+	return id, message, deliveries, err
+}
+
+func (q *Queue) recieveWait(ctx context.Context, tx *Tx, maxWait time.Duration) (string, mutator.MappedFieldValues, error) {
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	for {
+		q.mu.Lock()
+		nextExpiry, dead, err := q.requeueExpired()
+		id, message := "", mutator.MappedFieldValues(nil)
+		if err == nil {
+			id, message, err = q.recieveLocked(tx)
+		}
+		messageAdded := q.messageAdded
+		q.mu.Unlock()
+
+		dead.push(nil)
+		if err != QueueEmptyError {
+			return id, message, err
+		}
+
+		// wake up when an in flight message becomes visible again too
+		var expired <-chan time.Time
+		var expiryTimer *time.Timer
+		if !nextExpiry.IsZero() {
+			expiryTimer = time.NewTimer(time.Until(nextExpiry))
+			expired = expiryTimer.C
+		}
+
+		err = nil
+		select {
+		case <-messageAdded:
+		case <-expired:
+		case <-timer.C:
+			err = QueueEmptyError
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		if expiryTimer != nil {
+			expiryTimer.Stop()
+		}
+
+		if err != nil {
+			return "", nil, err
+		}
+	}
+}
+
+func (q *Queue) recieveBatch(tx *Tx, n int) ([]string, []mutator.MappedFieldValues, error) {
+	ids := make([]string, 0, n)
+	messages := make([]mutator.MappedFieldValues, 0, n)
+	err := q.withRequeued(func() error {
+		for len(ids) < n {
+			id, message, err := q.recieveLocked(tx)
+			if err == QueueEmptyError {
+				break
+			} else if err != nil {
+				return err
+			}
+
+			ids = append(ids, id)
+			messages = append(messages, message)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ids, messages, nil
+}
+
+func (q *Queue) ackSuccess(tx *Tx, messageIds []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	messageIds = utils.Unique(messageIds)
 	for _, messageId := range messageIds {
-		if queue.inFlightMessages[messageId] == nil {
+		if q.inFlightMessages[messageId] == nil {
 			return KeyDoesNotExistError
 		}
+	}
 
-		delete(queue.inFlightMessages, messageId)
+	for _, messageId := range messageIds {
+		q.saveMessage(tx, messageId)
+		delete(q.inFlightMessages, messageId)
 	}
 
 	return nil
 }
 
-func (b *QueueBackend) AckFailure(messageIds []string) error {
-	queue := b.conn.GetQueue(b.settings)
+func (q *Queue) ackFailure(tx *Tx, messageIds []string) error {
+	messageIds = utils.Unique(messageIds)
 
+	q.mu.Lock()
 	for _, messageId := range messageIds {
-		if queue.inFlightMessages[messageId] == nil {
+		if q.inFlightMessages[messageId] == nil {
+			q.mu.Unlock()
 			return KeyDoesNotExistError
 		}
+	}
 
-		messageIdInt, err := strconv.Atoi(messageId)
-		if err != nil {
-			return err
+	for _, messageId := range messageIds {
+		q.saveMessage(tx, messageId)
+	}
+
+	dead, err := q.pushFront(messageIds)
+	q.mu.Unlock()
+
+	dead.push(tx)
+	return err
+}
+
+func (q *Queue) extendVisibility(tx *Tx, messageId string, duration time.Duration) error {
+	return q.withRequeued(func() error {
+		inFlight := q.inFlightMessages[messageId]
+		if inFlight == nil {
+			return KeyDoesNotExistError
 		}
 
-		queue.messageQueue.PushFront(QueueItem{
-			id:      messageIdInt,
-			message: queue.inFlightMessages[messageId],
-		})
+		q.saveMessage(tx, messageId)
+		inFlight.visibleAt = time.Now().Add(duration)
+		return nil
+	})
+}
+
+// removeMessage takes a message out of the queue or the in flight messages,
+// it must be called while holding mu
+func (q *Queue) removeMessage(messageId string) {
+	delete(q.inFlightMessages, messageId)
+
+	for element := q.messageQueue.Front(); element != nil; element = element.Next() {
+		if item, ok := element.Value.(QueueItem); ok && strconv.Itoa(item.id) == messageId {
+			q.messageQueue.Remove(element)
+			return
+		}
+	}
+}
+
+// insertItem puts item back into the queue in id order, it must be called
+// while holding mu
+func (q *Queue) insertItem(item QueueItem) {
+	for element := q.messageQueue.Front(); element != nil; element = element.Next() {
+		if next, ok := element.Value.(QueueItem); ok && next.id > item.id {
+			q.messageQueue.InsertBefore(item, element)
+			return
+		}
+	}
+
+	q.messageQueue.PushBack(item)
+}
+
+// saveMessage keeps the state of a message to put back if tx is rolled back,
+// it must be called while holding mu
+func (q *Queue) saveMessage(tx *Tx, messageId string) {
+	tx.save(messageKey{q, messageId}, func() func() error {
+		var savedItem *QueueItem
+		for element := q.messageQueue.Front(); element != nil; element = element.Next() {
+			if item, ok := element.Value.(QueueItem); ok && strconv.Itoa(item.id) == messageId {
+				savedItem = &item
+				break
+			}
+		}
+
+		var savedInFlight *InFlightMessage
+		if inFlight := q.inFlightMessages[messageId]; inFlight != nil {
+			saved := *inFlight
+			savedInFlight = &saved
+		}
+
+		return func() error {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			q.removeMessage(messageId)
+			if savedInFlight != nil {
+				restored := *savedInFlight
+				q.inFlightMessages[messageId] = &restored
+			} else if savedItem != nil {
+				q.insertItem(*savedItem)
+				q.broadcast()
+			}
+
+			return nil
+		}
+	})
+}
+
+func (q *Queue) deliveryCount(messageId string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	inFlight := q.inFlightMessages[messageId]
+	if inFlight == nil {
+		return 0, KeyDoesNotExistError
+	}
+
+	return inFlight.deliveries, nil
+}
+
+// validateDeadLetterQueue checks the dead letter queue has been registered
+func (c *Connection) validateDeadLetterQueue(settings *datastore.TableSettings) error {
+	if settings.MaxDeliveryAttempts <= 0 {
+		return nil
+	}
+
+	deadLetterSettings := datastore.NewTableSettings(
+		datastore.WithTableName(settings.DeadLetterQueueName),
+	)
+	if c.GetQueue(deadLetterSettings) == nil {
+		return DeadLetterQueueDoesNotExistError
+	}
+
+	return nil
+}
+
+type QueueBackend struct {
+	conn     *Connection
+	settings *datastore.TableSettings
+}
+
+func (b *QueueBackend) SetSettings(settings *datastore.TableSettings) {
+	b.settings = settings
+}
+
+func (b *QueueBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+}
+
+func (b *QueueBackend) Register() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := rejectAutoGenerateSettings(b.settings.DataSettings); err != nil {
+		return err
+	}
+
+	if b.settings.MaxDeliveryAttempts > 0 && b.settings.DeadLetterQueueName == b.settings.Name {
+		return InvalidDeadLetterQueueError
+	} else if err := b.conn.validateDeadLetterQueue(b.settings); err != nil {
+		return err
 	}
 
+	if b.conn.GetQueue(b.settings) == nil {
+		b.conn.SetQueue(b.settings, newQueue(b.conn, b.settings))
+	}
+
+	return nil
+}
+
+func (b *QueueBackend) Drop() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	b.conn.DropQueue(b.settings)
 	return nil
 }
+
+// queue returns QueueDoesNotExistError once the queue is dropped
+func (b *QueueBackend) queue() (*Queue, error) {
+	queue := b.conn.GetQueue(b.settings)
+	if queue == nil {
+		return nil, QueueDoesNotExistError
+	}
+
+	return queue, nil
+}
+
+func (b *QueueBackend) Count(ctx context.Context) (int, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return 0, err
+	}
+
+	return queue.count()
+}
+
+func (b *QueueBackend) HasMessage(ctx context.Context) (bool, error) {
+	count, err := b.Count(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (b *QueueBackend) SendMessage(ctx context.Context, messages []mutator.MappedFieldValues) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	queue.push(b.conn.tx(ctx), messages)
+	return nil
+}
+
+func (b *QueueBackend) RecieveMessage(ctx context.Context) (string, mutator.MappedFieldValues, error) {
+	messageId, message, _, err := b.RecieveMessageWithDeliveryCount(ctx)
+	return messageId, message, err
+}
+
+func (b *QueueBackend) RecieveMessageWithDeliveryCount(ctx context.Context) (string, mutator.MappedFieldValues, int, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	return queue.recieve(b.conn.tx(ctx))
+}
+
+func (b *QueueBackend) RecieveMessageWait(ctx context.Context, maxWait time.Duration) (string, mutator.MappedFieldValues, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return queue.recieveWait(ctx, b.conn.tx(ctx), maxWait)
+}
+
+func (b *QueueBackend) RecieveMessages(ctx context.Context, n int) ([]string, []mutator.MappedFieldValues, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return queue.recieveBatch(b.conn.tx(ctx), n)
+}
+
+func (b *QueueBackend) AckSuccess(ctx context.Context, messageIds []string) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	return queue.ackSuccess(b.conn.tx(ctx), messageIds)
+}
+
+func (b *QueueBackend) AckFailure(ctx context.Context, messageIds []string) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	return queue.ackFailure(b.conn.tx(ctx), messageIds)
+}
+
+func (b *QueueBackend) ExtendVisibility(ctx context.Context, messageId string, duration time.Duration) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	return queue.extendVisibility(b.conn.tx(ctx), messageId, duration)
+}
+
+func (b *QueueBackend) DeliveryCount(ctx context.Context, messageId string) (int, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return 0, err
+	}
+
+	return queue.deliveryCount(messageId)
+}
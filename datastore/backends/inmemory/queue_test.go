@@ -0,0 +1,64 @@
+package inmemory_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestQueueBackend(t *testing.T) {
+	datastoretest.RunQueueSuite(t, func(t *testing.T) (*inmemory.Connection, *inmemory.QueueBackend) {
+		conn := inmemory.NewConnection()
+		return conn, &inmemory.QueueBackend{}
+	})
+}
+
+func TestQueueDroppedDeadLetterQueue(t *testing.T) {
+	deadLetterBackend := &inmemory.QueueBackend{}
+	deadLetterQueue := datastoretest.NewMockDeadLetterQueue()
+	mockQueue := datastoretest.NewMockQueue(
+		datastore.WithDeadLetterQueue(datastoretest.DeadLetterQueueName, datastoretest.MaxDeliveryAttempts),
+	)
+	registerTables(
+		t,
+		datastore.RegisterQueue[*inmemory.Connection](deadLetterQueue, deadLetterBackend),
+		datastore.RegisterQueue[*inmemory.Connection](mockQueue, &inmemory.QueueBackend{}),
+	)
+	testutils.AssertOk(t, deadLetterBackend.Drop())
+
+	message := datastoretest.GenerateNonKeyedEntries(1, "testdropped")[0]
+	testutils.AssertOk(t, mockQueue.SendMessage(message))
+
+	// the message stays in the queue once it runs out of attempts, rather
+	// than every call failing
+	for i := 0; i < datastoretest.MaxDeliveryAttempts; i += 1 {
+		id := datastoretest.AssertRecievesMessage(t, mockQueue, message.Data.Data)
+		testutils.AssertOk(t, mockQueue.AckFailure(id))
+	}
+
+	count, err := mockQueue.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, count)
+
+	id := datastoretest.AssertRecievesMessage(t, mockQueue, message.Data.Data)
+	testutils.AssertOk(t, mockQueue.AckSuccess(id))
+}
+
+func TestDroppedQueue(t *testing.T) {
+	backend := &inmemory.QueueBackend{}
+	mockQueue := datastoretest.NewMockQueue()
+	registerTables(t, datastore.RegisterQueue[*inmemory.Connection](mockQueue, backend))
+	testutils.AssertOk(t, backend.Drop())
+
+	_, err := mockQueue.Count()
+	testutils.AssertErrorEquals(t, inmemory.QueueDoesNotExistError, err)
+
+	err = mockQueue.SendMessage(datastoretest.GenerateNonKeyedEntries(1, "testdropped")...)
+	testutils.AssertErrorEquals(t, inmemory.QueueDoesNotExistError, err)
+
+	_, _, err = mockQueue.RecieveMessage()
+	testutils.AssertErrorEquals(t, inmemory.QueueDoesNotExistError, err)
+}
@@ -0,0 +1,718 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/utils"
+)
+
+// SortTable entries are kept ordered by the key fields which are not sort
+// fields, followed by each of the SortFieldNames in order
+type SortTable = []mutator.MappedFieldValues
+
+type SortTableBackend struct {
+	conn     *Connection
+	settings *datastore.TableSettings
+}
+
+func (b *SortTableBackend) SetSettings(settings *datastore.TableSettings) {
+	b.settings = settings
+}
+
+func (b *SortTableBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+}
+
+func (b *SortTableBackend) Register() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := validateAutoGenerateSettings(b.settings); err != nil {
+		return err
+	} else if err := validateVersionSettings(b.settings); err != nil {
+		return err
+	} else if err := b.settings.ValidateIndexes(); err != nil {
+		return err
+	}
+
+	if b.conn.GetSortTable(b.settings) == nil {
+		b.conn.SetSortTable(b.settings, SortTable{})
+	}
+
+	if b.conn.GetIndexes(b.settings) == nil {
+		indexes := newIndexes(b.settings)
+		for _, entry := range b.conn.GetSortTable(b.settings) {
+			if err := b.replaceIndexed(indexes, nil, entry); err != nil {
+				return err
+			}
+		}
+		b.conn.SetIndexes(b.settings, indexes)
+	}
+
+	return nil
+}
+
+func (b *SortTableBackend) Drop() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	b.conn.DropSortTable(b.settings)
+	b.conn.DropIndexes(b.settings)
+	b.conn.DropSequence(b.settings)
+	return nil
+}
+
+func (b *SortTableBackend) hashFieldNames() []string {
+	hashFieldNames := make([]string, 0, len(b.settings.KeySettings.FieldOrder))
+
+	for _, fieldName := range b.settings.KeySettings.FieldOrder {
+		if !utils.SliceContains(b.settings.SortFieldNames, fieldName) {
+			hashFieldNames = append(hashFieldNames, fieldName)
+		}
+	}
+
+	return hashFieldNames
+}
+
+func (b *SortTableBackend) compareFields(fieldNames []string, entry1, entry2 mutator.MappedFieldValues) (int, error) {
+	for _, fieldName := range fieldNames {
+		result, err := compare.CompareValues(entry1[fieldName], entry2[fieldName])
+		if err != nil {
+			return 0, err
+		} else if result != 0 {
+			return result, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (b *SortTableBackend) compareKeys(entry1, entry2 mutator.MappedFieldValues) (int, error) {
+	result, err := b.compareFields(b.hashFieldNames(), entry1, entry2)
+	if err != nil || result != 0 {
+		return result, err
+	}
+
+	return b.compareFields(b.settings.SortFieldNames, entry1, entry2)
+}
+
+// search returns the index where key is or would be inserted in table, and
+// whether an entry with that key already exists there
+func (b *SortTableBackend) search(table SortTable, key mutator.MappedFieldValues) (int, bool, error) {
+	var searchErr error
+	i := sort.Search(len(table), func(i int) bool {
+		result, err := b.compareKeys(table[i], key)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+
+		return result >= 0
+	})
+
+	if searchErr != nil {
+		return 0, false, searchErr
+	} else if i == len(table) {
+		return i, false, nil
+	}
+
+	result, err := b.compareKeys(table[i], key)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return i, result == 0, nil
+}
+
+func (b *SortTableBackend) matchesSortComparator(entry, key, comparator mutator.MappedFieldValues) (bool, error) {
+	result, err := b.compareFields(b.hashFieldNames(), entry, key)
+	if err != nil || result != 0 {
+		return false, err
+	}
+
+	for _, fieldName := range b.settings.SortFieldNames {
+		if compare.IsNilComparator(comparator[fieldName]) {
+			continue
+		}
+
+		matcher, ok := comparator[fieldName].(compare.Matcher)
+		if !ok {
+			return false, compare.ComparisonTypeError
+		}
+
+		matches, err := matcher.Matches(entry[fieldName])
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (b *SortTableBackend) Count(ctx context.Context) (int, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return len(b.conn.GetSortTable(b.settings)), nil
+}
+
+// saveEntry keeps the entry stored with the key of entry to put back if the
+// transaction in ctx is rolled back
+func (b *SortTableBackend) saveEntry(ctx context.Context, entry mutator.MappedFieldValues) error {
+	tx := b.conn.tx(ctx)
+	if tx == nil {
+		return nil
+	}
+
+	key := getKeyFromEntry(b.settings, entry)
+	keyStr, err := stringifyKey(key)
+	if err != nil {
+		return err
+	}
+
+	tx.save(entryKey{b.settings.Name, keyStr}, func() func() error {
+		var saved mutator.MappedFieldValues
+		table := b.conn.GetSortTable(b.settings)
+		if i, found, err := b.search(table, key); err == nil && found {
+			saved = table[i]
+		}
+
+		return func() error {
+			lock := b.conn.tableLock(b.settings)
+			lock.Lock()
+			defer lock.Unlock()
+
+			table := b.conn.GetSortTable(b.settings)
+			i, found, err := b.search(table, key)
+			if err != nil {
+				return err
+			}
+
+			var existing mutator.MappedFieldValues
+			if found {
+				existing = table[i]
+			} else if saved == nil {
+				return nil
+			}
+
+			if err := b.replaceIndexed(b.conn.GetIndexes(b.settings), existing, saved); err != nil {
+				return err
+			}
+
+			switch {
+			case saved == nil:
+				table = append(table[:i], table[i+1:]...)
+			case found:
+				table[i] = saved
+			default:
+				table = append(table, nil)
+				copy(table[i+1:], table[i:])
+				table[i] = saved
+			}
+
+			b.conn.SetSortTable(b.settings, table)
+			return nil
+		}
+	})
+
+	return nil
+}
+
+// replaceIndexed swaps existing for entry in indexes, either of which may be
+// nil when an entry is added or deleted
+func (b *SortTableBackend) replaceIndexed(indexes Indexes, existing, entry mutator.MappedFieldValues) error {
+	key := existing
+	if key == nil {
+		key = entry
+	}
+
+	keyStr, err := stringifyKey(getKeyFromEntry(b.settings, key))
+	if err != nil {
+		return err
+	}
+
+	return indexes.replace(b.settings, keyStr, existing, entry)
+}
+
+// put stores entry at i in the table and indexes, replacing the entry there
+// if found is true, and adds the step reversing it to undo. The table must be
+// stored before undo is run.
+func (b *SortTableBackend) put(ctx context.Context, table SortTable, indexes Indexes, i int, found bool, entry mutator.MappedFieldValues, undo *undoLog) (SortTable, error) {
+	if err := b.saveEntry(ctx, entry); err != nil {
+		return table, err
+	}
+
+	var existing mutator.MappedFieldValues
+	if found {
+		existing = table[i]
+	}
+
+	if err := b.replaceIndexed(indexes, existing, entry); err != nil {
+		return table, err
+	}
+
+	undo.add(func() {
+		// later steps are undone first, so existing cannot break an index
+		// and i is still the position of entry
+		b.replaceIndexed(indexes, entry, existing)
+		table := b.conn.GetSortTable(b.settings)
+		if found {
+			table[i] = existing
+		} else {
+			b.conn.SetSortTable(b.settings, append(table[:i], table[i+1:]...))
+		}
+	})
+
+	if found {
+		table[i] = entry
+		return table, nil
+	}
+
+	table = append(table, nil)
+	copy(table[i+1:], table[i:])
+	table[i] = entry
+	return table, nil
+}
+
+// add inserts entry with new values for its auto generated fields, failing
+// if an entry with the same key exists
+func (b *SortTableBackend) add(ctx context.Context, table SortTable, indexes Indexes, entry mutator.MappedFieldValues, undo *undoLog) (SortTable, mutator.MappedFieldValues, error) {
+	entry, err := b.conn.generateValues(b.settings, entry)
+	if err != nil {
+		return table, nil, err
+	}
+
+	i, found, err := b.search(table, entry)
+	if err != nil {
+		return table, nil, err
+	} else if found {
+		return table, nil, KeyExistsError
+	}
+
+	table, err = b.put(ctx, table, indexes, i, false, entry, undo)
+	return table, entry, err
+}
+
+func (b *SortTableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	entries := append(SortTable{}, b.conn.GetSortTable(b.settings)...)
+	lock.RUnlock()
+
+	return scanEntries(ctx, b.settings, batchSize, entries, filter, fieldNames)
+}
+
+// pageStart returns the index of the first entry after the key in cursor
+func (b *SortTableBackend) pageStart(table SortTable, cursor string) (int, error) {
+	cursorKey, err := decodeKeyCursor(b.settings, cursor)
+	if err != nil || cursorKey == nil {
+		return 0, err
+	}
+
+	i, found, err := b.search(table, cursorKey)
+	if err != nil {
+		return 0, InvalidCursorError
+	} else if found {
+		i += 1
+	}
+
+	return i, nil
+}
+
+func (b *SortTableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetSortTable(b.settings)
+	start, err := b.pageStart(table, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	end := start + limit + 1
+	if end > len(table) {
+		end = len(table)
+	}
+
+	return keyPage(b.settings, append(SortTable{}, table[start:end]...), limit)
+}
+
+func (b *SortTableBackend) Aggregate(ctx context.Context, aggregates []*queries.Aggregate, options *queries.AggregateOptions) ([]*queries.AggregateFields, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return aggregateEntries(b.settings, b.conn.GetSortTable(b.settings), aggregates, options)
+}
+
+func (b *SortTableBackend) Get(ctx context.Context, keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetSortTable(b.settings)
+
+	data := make([]mutator.MappedFieldValues, 0, len(keys))
+	for _, key := range keys {
+		i, found, err := b.search(table, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			data = append(data, table[i])
+		}
+	}
+
+	return data, nil
+}
+
+func (b *SortTableBackend) Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// stored even if an entry fails, to match its indexes
+	table := b.conn.GetSortTable(b.settings)
+	defer func() { b.conn.SetSortTable(b.settings, table) }()
+
+	added := make([]mutator.MappedFieldValues, len(entries))
+	for i, entry := range entries {
+		var err error
+		if table, added[i], err = b.add(ctx, table, b.conn.GetIndexes(b.settings), entry, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return added, nil
+}
+
+func (b *SortTableBackend) Update(ctx context.Context, entries []mutator.MappedFieldValues) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return b.update(ctx, entries)
+}
+
+func (b *SortTableBackend) update(ctx context.Context, entries []mutator.MappedFieldValues) (err error) {
+	table, indexes := b.conn.GetSortTable(b.settings), b.conn.GetIndexes(b.settings)
+
+	// entries are replaced in place, so the stored table is always current
+	undo := undoLog{}
+	defer func() {
+		if err != nil {
+			undo.run()
+		}
+	}()
+
+	for _, entry := range entries {
+		i, found, err := b.search(table, entry)
+		if err != nil {
+			return err
+		} else if !found {
+			return KeyDoesNotExistError
+		}
+
+		updated, err := nextVersion(b.settings, table[i], entry)
+		if err != nil {
+			return err
+		}
+
+		if _, err := b.put(ctx, table, indexes, i, true, updated, &undo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *SortTableBackend) UpdateIf(ctx context.Context, entries []mutator.MappedFieldValues, predicate func(existing mutator.MappedFieldValues) (bool, error)) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table := b.conn.GetSortTable(b.settings)
+
+	// every entry is checked before any are updated
+	for _, entry := range entries {
+		i, found, err := b.search(table, entry)
+		if err != nil {
+			return err
+		} else if !found {
+			return KeyDoesNotExistError
+		}
+
+		if matches, err := predicate(table[i]); err != nil {
+			return err
+		} else if !matches {
+			return ConditionFailedError
+		}
+	}
+
+	return b.update(ctx, entries)
+}
+
+func (b *SortTableBackend) Upsert(ctx context.Context, entries []mutator.MappedFieldValues) (_ []mutator.MappedFieldValues, err error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table, indexes := b.conn.GetSortTable(b.settings), b.conn.GetIndexes(b.settings)
+
+	undo := undoLog{}
+	defer func() {
+		b.conn.SetSortTable(b.settings, table)
+		if err != nil {
+			undo.run()
+		}
+	}()
+
+	upserted := make([]mutator.MappedFieldValues, len(entries))
+	for j, entry := range entries {
+		i, found, err := b.search(table, entry)
+		if err != nil {
+			return nil, err
+		} else if !found {
+			if table, upserted[j], err = b.add(ctx, table, indexes, entry, &undo); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		entry, err = nextVersion(b.settings, table[i], entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if table, err = b.put(ctx, table, indexes, i, true, entry, &undo); err != nil {
+			return nil, err
+		}
+
+		upserted[j] = entry
+	}
+
+	return upserted, nil
+}
+
+func (b *SortTableBackend) AddIfAbsent(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table := b.conn.GetSortTable(b.settings)
+	defer func() { b.conn.SetSortTable(b.settings, table) }()
+
+	added := []mutator.MappedFieldValues{}
+	for _, entry := range entries {
+		_, found, err := b.search(table, entry)
+		if err != nil {
+			return nil, err
+		} else if found {
+			continue
+		}
+
+		if table, entry, err = b.add(ctx, table, b.conn.GetIndexes(b.settings), entry, nil); err != nil {
+			return nil, err
+		}
+		added = append(added, entry)
+	}
+
+	return added, nil
+}
+
+func (b *SortTableBackend) Delete(ctx context.Context, keys []mutator.MappedFieldValues) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table := b.conn.GetSortTable(b.settings)
+	defer func() { b.conn.SetSortTable(b.settings, table) }()
+
+	for _, key := range keys {
+		i, found, err := b.search(table, key)
+		if err != nil {
+			return err
+		} else if !found {
+			return KeyDoesNotExistError
+		}
+
+		if err := b.saveEntry(ctx, table[i]); err != nil {
+			return err
+		} else if err := b.replaceIndexed(b.conn.GetIndexes(b.settings), table[i], nil); err != nil {
+			return err
+		}
+		table = append(table[:i], table[i+1:]...)
+	}
+
+	return nil
+}
+
+func (b *SortTableBackend) GetWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues, options *queries.SortOptions) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetSortTable(b.settings)
+	data := []mutator.MappedFieldValues{}
+
+	for i := range table {
+		entry := table[i]
+		if options.Order == queries.DESCENDING {
+			entry = table[len(table)-1-i]
+		}
+
+		matches, err := b.matchesSortComparator(entry, key, comparator)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			data = append(data, entry)
+			if len(data) == options.Limit {
+				break
+			}
+		}
+	}
+
+	return data, nil
+}
+
+func (b *SortTableBackend) GetPageWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	table := b.conn.GetSortTable(b.settings)
+	start, err := b.pageStart(table, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data := []mutator.MappedFieldValues{}
+	for _, entry := range table[start:] {
+		matches, err := b.matchesSortComparator(entry, key, comparator)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if matches {
+			data = append(data, entry)
+			if len(data) > limit {
+				break
+			}
+		}
+	}
+
+	return keyPage(b.settings, data, limit)
+}
+
+func (b *SortTableBackend) UpdateWithSortComparator(ctx context.Context, entry mutator.MappedFieldValues, comparator mutator.MappedFieldValues) (err error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table, indexes := b.conn.GetSortTable(b.settings), b.conn.GetIndexes(b.settings)
+
+	// entries are replaced in place, and put back unless every row passes
+	undo := undoLog{}
+	defer func() {
+		if err != nil {
+			undo.run()
+		}
+	}()
+
+	for i, existing := range table {
+		matches, err := b.matchesSortComparator(existing, entry, comparator)
+		if err != nil {
+			return err
+		} else if !matches {
+			continue
+		}
+
+		// only data fields are updated, the key of each matched entry is kept
+		updated := mutator.MappedFieldValues{}
+		for fieldName, value := range entry {
+			updated[fieldName] = value
+		}
+		for _, fieldName := range b.settings.KeySettings.FieldOrder {
+			updated[fieldName] = existing[fieldName]
+		}
+
+		updated, err = nextVersion(b.settings, existing, updated)
+		if err != nil {
+			return err
+		}
+
+		if _, err := b.put(ctx, table, indexes, i, true, updated, &undo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *SortTableBackend) DeleteWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	table := b.conn.GetSortTable(b.settings)
+
+	remaining := make(SortTable, 0, len(table))
+	deleted := []mutator.MappedFieldValues{}
+	for _, entry := range table {
+		matches, err := b.matchesSortComparator(entry, key, comparator)
+		if err != nil {
+			return err
+		} else if matches {
+			deleted = append(deleted, entry)
+		} else {
+			remaining = append(remaining, entry)
+		}
+	}
+
+	for _, entry := range deleted {
+		if err := b.saveEntry(ctx, entry); err != nil {
+			return err
+		} else if err := b.replaceIndexed(b.conn.GetIndexes(b.settings), entry, nil); err != nil {
+			return err
+		}
+	}
+
+	b.conn.SetSortTable(b.settings, remaining)
+	return nil
+}
+
+func (b *SortTableBackend) GetByIndex(ctx context.Context, indexName string, values mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	lock := b.conn.tableLock(b.settings)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	entries, err := b.conn.GetIndexes(b.settings).get(b.settings, indexName, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries are returned in the same order as the table
+	var sortErr error
+	sort.SliceStable(entries, func(i, j int) bool {
+		result, err := b.compareKeys(entries[i], entries[j])
+		if err != nil {
+			sortErr = err
+		}
+
+		return result < 0
+	})
+
+	return entries, sortErr
+}
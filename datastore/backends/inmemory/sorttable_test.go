@@ -0,0 +1,15 @@
+package inmemory_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+)
+
+func TestSortTableBackend(t *testing.T) {
+	datastoretest.RunSortTableSuite(t, func(t *testing.T) (*inmemory.Connection, *inmemory.SortTableBackend) {
+		conn := inmemory.NewConnection()
+		return conn, &inmemory.SortTableBackend{}
+	})
+}
@@ -0,0 +1,230 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+// Topic copies every published message to the queue of each subscription
+type Topic struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*Queue
+}
+
+func (t *Topic) subscription(subscriptionId string) *Queue {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.subscriptions[subscriptionId]
+}
+
+type TopicBackend struct {
+	conn     *Connection
+	settings *datastore.TableSettings
+}
+
+func (b *TopicBackend) SetSettings(settings *datastore.TableSettings) {
+	b.settings = settings
+}
+
+func (b *TopicBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+}
+
+func (b *TopicBackend) Register() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := rejectAutoGenerateSettings(b.settings.DataSettings); err != nil {
+		return err
+	} else if err := b.conn.validateDeadLetterQueue(b.settings); err != nil {
+		return err
+	}
+
+	if b.conn.GetTopic(b.settings) == nil {
+		b.conn.SetTopic(b.settings, &Topic{
+			subscriptions: map[string]*Queue{},
+		})
+	}
+
+	return nil
+}
+
+func (b *TopicBackend) Drop() error {
+	lock := b.conn.tableLock(b.settings)
+	lock.Lock()
+	defer lock.Unlock()
+
+	b.conn.DropTopic(b.settings)
+	return nil
+}
+
+// topic returns TopicDoesNotExistError once the topic is dropped
+func (b *TopicBackend) topic() (*Topic, error) {
+	topic := b.conn.GetTopic(b.settings)
+	if topic == nil {
+		return nil, TopicDoesNotExistError
+	}
+
+	return topic, nil
+}
+
+func (b *TopicBackend) Publish(ctx context.Context, messages []mutator.MappedFieldValues) error {
+	topic, err := b.topic()
+	if err != nil {
+		return err
+	}
+
+	topic.mu.RLock()
+	defer topic.mu.RUnlock()
+
+	tx := b.conn.tx(ctx)
+	for _, queue := range topic.subscriptions {
+		queue.push(tx, messages)
+	}
+
+	return nil
+}
+
+func (b *TopicBackend) Subscribe(ctx context.Context, subscriptionId string) (datastore.SubscriptionBackendQueries, error) {
+	topic, err := b.topic()
+	if err != nil {
+		return nil, err
+	}
+
+	topic.mu.Lock()
+	defer topic.mu.Unlock()
+
+	// subscribing again with the same id picks up the existing queue
+	if topic.subscriptions[subscriptionId] == nil {
+		topic.subscriptions[subscriptionId] = newQueue(b.conn, b.settings)
+	}
+
+	return &SubscriptionBackend{
+		topicBackend:   b,
+		subscriptionId: subscriptionId,
+	}, nil
+}
+
+type SubscriptionBackend struct {
+	topicBackend   *TopicBackend
+	subscriptionId string
+}
+
+// topic is looked up on every call so subscriptions end when it is dropped
+func (b *SubscriptionBackend) topic() *Topic {
+	return b.topicBackend.conn.GetTopic(b.topicBackend.settings)
+}
+
+func (b *SubscriptionBackend) queue() (*Queue, error) {
+	topic := b.topic()
+	if topic == nil {
+		return nil, SubscriptionDoesNotExistError
+	}
+
+	queue := topic.subscription(b.subscriptionId)
+	if queue == nil {
+		return nil, SubscriptionDoesNotExistError
+	}
+
+	return queue, nil
+}
+
+func (b *SubscriptionBackend) HasMessage(ctx context.Context) (bool, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return false, err
+	}
+
+	count, err := queue.count()
+	return count > 0, err
+}
+
+func (b *SubscriptionBackend) RecieveMessage(ctx context.Context) (string, mutator.MappedFieldValues, error) {
+	messageId, message, _, err := b.RecieveMessageWithDeliveryCount(ctx)
+	return messageId, message, err
+}
+
+func (b *SubscriptionBackend) RecieveMessageWithDeliveryCount(ctx context.Context) (string, mutator.MappedFieldValues, int, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	return queue.recieve(b.topicBackend.conn.tx(ctx))
+}
+
+func (b *SubscriptionBackend) RecieveMessageWait(ctx context.Context, maxWait time.Duration) (string, mutator.MappedFieldValues, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return queue.recieveWait(ctx, b.topicBackend.conn.tx(ctx), maxWait)
+}
+
+func (b *SubscriptionBackend) RecieveMessages(ctx context.Context, n int) ([]string, []mutator.MappedFieldValues, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return queue.recieveBatch(b.topicBackend.conn.tx(ctx), n)
+}
+
+func (b *SubscriptionBackend) AckSuccess(ctx context.Context, messageIds []string) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	return queue.ackSuccess(b.topicBackend.conn.tx(ctx), messageIds)
+}
+
+func (b *SubscriptionBackend) AckFailure(ctx context.Context, messageIds []string) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	return queue.ackFailure(b.topicBackend.conn.tx(ctx), messageIds)
+}
+
+func (b *SubscriptionBackend) Unsubscribe(ctx context.Context) error {
+	topic := b.topic()
+	if topic == nil {
+		return SubscriptionDoesNotExistError
+	}
+
+	topic.mu.Lock()
+	defer topic.mu.Unlock()
+
+	if topic.subscriptions[b.subscriptionId] == nil {
+		return SubscriptionDoesNotExistError
+	}
+
+	delete(topic.subscriptions, b.subscriptionId)
+	return nil
+}
+
+func (b *SubscriptionBackend) ExtendVisibility(ctx context.Context, messageId string, duration time.Duration) error {
+	queue, err := b.queue()
+	if err != nil {
+		return err
+	}
+
+	return queue.extendVisibility(b.topicBackend.conn.tx(ctx), messageId, duration)
+}
+
+func (b *SubscriptionBackend) DeliveryCount(ctx context.Context, messageId string) (int, error) {
+	queue, err := b.queue()
+	if err != nil {
+		return 0, err
+	}
+
+	return queue.deliveryCount(messageId)
+}
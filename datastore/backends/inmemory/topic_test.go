@@ -0,0 +1,47 @@
+package inmemory_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestTopicBackend(t *testing.T) {
+	datastoretest.RunTopicSuite(t, func(t *testing.T) (*inmemory.Connection, *inmemory.TopicBackend) {
+		conn := inmemory.NewConnection()
+		return conn, &inmemory.TopicBackend{}
+	})
+}
+
+func TestSubscriptionDeadLetter(t *testing.T) {
+	deadLetterQueue := datastoretest.NewMockDeadLetterQueue()
+	mockTopic := datastoretest.NewMockTopic(
+		datastore.WithVisibilityTimeout(datastoretest.VisibilityTimeout),
+		datastore.WithDeadLetterQueue(datastoretest.DeadLetterQueueName, datastoretest.MaxDeliveryAttempts),
+	)
+	registerTables(
+		t,
+		datastore.RegisterQueue[*inmemory.Connection](deadLetterQueue, &inmemory.QueueBackend{}),
+		datastore.RegisterTopic[*inmemory.Connection](mockTopic, &inmemory.TopicBackend{}),
+	)
+
+	subscription, err := mockTopic.Subscribe("testdeadletter")
+	testutils.AssertOk(t, err)
+	datastoretest.TestMessageDeadLetter(t, subscription, mockTopic.Publish, deadLetterQueue)
+}
+
+func TestDroppedTopic(t *testing.T) {
+	backend := &inmemory.TopicBackend{}
+	mockTopic := datastoretest.NewMockTopic()
+	registerTables(t, datastore.RegisterTopic[*inmemory.Connection](mockTopic, backend))
+	testutils.AssertOk(t, backend.Drop())
+
+	err := mockTopic.Publish(datastoretest.GenerateNonKeyedEntries(1, "testdropped")...)
+	testutils.AssertErrorEquals(t, inmemory.TopicDoesNotExistError, err)
+
+	_, err = mockTopic.Subscribe("testdropped")
+	testutils.AssertErrorEquals(t, inmemory.TopicDoesNotExistError, err)
+}
@@ -0,0 +1,119 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sophielizg/go-libs/datastore"
+)
+
+type txKey struct{}
+
+// entryKey identifies an entry of a table which a Tx has saved
+type entryKey struct {
+	tableName string
+	keyStr    string
+}
+
+// messageKey identifies a message in a queue which a Tx has saved
+type messageKey struct {
+	queue     *Queue
+	messageId string
+}
+
+// Tx keeps the original state of each entry or message it changes to put
+// back on Rollback. Changes are visible before Commit.
+type Tx struct {
+	mu    sync.Mutex
+	conn  *Connection
+	saved map[any]bool
+	undos []func() error
+}
+
+func (tx *Tx) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.saved = map[any]bool{}
+	tx.undos = nil
+	return nil
+}
+
+func (tx *Tx) Rollback() error {
+	tx.mu.Lock()
+	undos := tx.undos
+	tx.saved = map[any]bool{}
+	tx.undos = nil
+	tx.mu.Unlock()
+
+	// undos take table locks, so they run without mu
+	var firstErr error
+	for i := len(undos) - 1; i >= 0; i -= 1 {
+		if err := undos[i](); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// undo keeps a function to run on rollback, it does nothing if tx is nil
+func (tx *Tx) undo(undo func() error) {
+	if tx == nil {
+		return
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.undos = append(tx.undos, undo)
+}
+
+// save keeps the undo returned by snapshot the first time key is changed, it
+// does nothing if tx is nil
+func (tx *Tx) save(key any, snapshot func() func() error) {
+	if tx == nil {
+		return
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if !tx.saved[key] {
+		tx.saved[key] = true
+		tx.undos = append(tx.undos, snapshot())
+	}
+}
+
+// joinedTx leaves committing to the outer transaction
+type joinedTx struct{}
+
+func (joinedTx) Commit() error {
+	return nil
+}
+
+func (joinedTx) Rollback() error {
+	return nil
+}
+
+func (c *Connection) BeginTx(ctx context.Context) (context.Context, datastore.Tx, error) {
+	if c.tx(ctx) != nil {
+		return ctx, joinedTx{}, nil
+	}
+
+	tx := &Tx{
+		conn:  c,
+		saved: map[any]bool{},
+	}
+	return context.WithValue(ctx, txKey{}, tx), tx, nil
+}
+
+// tx returns the transaction for this connection carried by ctx, if any
+func (c *Connection) tx(ctx context.Context) *Tx {
+	tx, _ := ctx.Value(txKey{}).(*Tx)
+	if tx == nil || tx.conn != c {
+		return nil
+	}
+
+	return tx
+}
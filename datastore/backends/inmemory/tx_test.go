@@ -0,0 +1,100 @@
+package inmemory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestTx(t *testing.T) {
+	datastoretest.RunTxSuite(
+		t,
+		func(t *testing.T) *inmemory.Connection {
+			return inmemory.NewConnection()
+		},
+		func() *inmemory.HashTableBackend {
+			return &inmemory.HashTableBackend{}
+		},
+		func() *inmemory.SortTableBackend {
+			return &inmemory.SortTableBackend{}
+		},
+	)
+}
+
+// TestTxRollbackKeepsOtherWrites checks rolling back only undoes the changes
+// made in the transaction, not ones made to the same tables outside it
+func TestTxRollbackKeepsOtherWrites(t *testing.T) {
+	mockTable := datastoretest.NewMockTable()
+	mockSortTable := datastoretest.NewMockSortTable()
+	mockAppendTable := datastoretest.NewMockAppendTable()
+	mockQueue := datastoretest.NewMockQueue()
+	conn := inmemory.NewConnection()
+	group := datastore.NewConnectionGroup(datastore.WithConnection(conn))
+	testutils.AssertOk(t, group.RegisterTables(
+		datastore.RegisterHashTable[*inmemory.Connection](mockTable, &inmemory.HashTableBackend{}),
+		datastore.RegisterSortTable[*inmemory.Connection](mockSortTable, &inmemory.SortTableBackend{}),
+		datastore.RegisterAppendTable[*inmemory.Connection](mockAppendTable, &inmemory.AppendTableBackend{}),
+		datastore.RegisterQueue[*inmemory.Connection](mockQueue, &inmemory.QueueBackend{}),
+	))
+
+	existing := datastoretest.GenerateEntries(1, "testtxexisting")[0]
+	_, err := mockTable.Add(existing)
+	testutils.AssertOk(t, err)
+
+	outside := datastoretest.GenerateEntries(1, "testtxoutside")[0]
+	outsideSort := datastoretest.GenerateSortEntries(1, "testtxoutside")[0]
+	outsideMessage := datastoretest.GenerateNonKeyedEntries(1, "testtxoutside")[0]
+
+	err = group.WithTx(context.Background(), func(ctx context.Context) error {
+		updated := datastoretest.GenerateEntries(1, "testtxexisting")[0]
+		updated.Data.Data = "updated"
+		if err := mockTable.UpdateContext(ctx, updated); err != nil {
+			return err
+		} else if _, err := mockTable.AddContext(ctx, datastoretest.GenerateEntries(1, "testtxinside")...); err != nil {
+			return err
+		} else if _, err := mockSortTable.AddContext(ctx, datastoretest.GenerateSortEntries(1, "testtxinside")...); err != nil {
+			return err
+		} else if _, err := mockAppendTable.AddContext(ctx, datastoretest.GenerateNonKeyedEntries(1, "testtxinside")...); err != nil {
+			return err
+		} else if err := mockQueue.SendMessageContext(ctx, datastoretest.GenerateNonKeyedEntries(1, "testtxinside")...); err != nil {
+			return err
+		}
+
+		// written without the transaction while it is open
+		if _, err := mockTable.Add(outside); err != nil {
+			return err
+		} else if _, err := mockSortTable.Add(outsideSort); err != nil {
+			return err
+		} else if _, err := mockAppendTable.Add(outsideMessage); err != nil {
+			return err
+		} else if err := mockQueue.SendMessage(outsideMessage); err != nil {
+			return err
+		}
+
+		return errors.New("rolled back")
+	})
+	testutils.AssertTrue(t, err != nil)
+
+	found, err := mockTable.Get(existing.Key, outside.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(found))
+	if len(found) == 2 {
+		testutils.AssertEquals(t, existing.Data.Data, found[0].Data.Data)
+	}
+
+	count, err := mockSortTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, count)
+
+	dataChan, errorChan := mockAppendTable.Scan(10)
+	testutils.AssertEquals(t, 1, len(datastoretest.CollectScan(t, dataChan, errorChan)))
+
+	id := datastoretest.AssertRecievesMessage(t, mockQueue, outsideMessage.Data.Data)
+	testutils.AssertOk(t, mockQueue.AckSuccess(id))
+	datastoretest.AssertNoMessage(t, mockQueue)
+}
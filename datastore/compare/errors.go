@@ -0,0 +1,9 @@
+package compare
+
+import "errors"
+
+var ComparisonTypeError = errors.New("unable to compare: values are not of the same comparable type")
+
+var ComparatorValuesError = errors.New("comparator has the wrong number of values for its operator")
+
+var FilterValuesError = errors.New("filter has an unknown operator or the wrong number of filters for its operator")
@@ -0,0 +1,185 @@
+package compare
+
+import "github.com/sophielizg/go-libs/datastore/fields"
+
+type ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~string
+}
+
+// Matcher is implemented by every *Comparator, so comparators stored as
+// mutator.MappedFieldValues can be evaluated without knowing their type
+type Matcher interface {
+	Matches(value any) (bool, error)
+}
+
+// Expression is implemented by every *Comparator, so backends can translate
+// comparators into their own query language without knowing their type
+type Expression interface {
+	Operator() Operator
+	Operands() []any
+}
+
+func compareOrdered[T ordered](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+
+	return 0
+}
+
+func compareBool(a, b bool) int {
+	if a == b {
+		return 0
+	} else if !a {
+		return -1
+	}
+
+	return 1
+}
+
+func compareTime(a, b fields.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+
+	return 0
+}
+
+// nil values are always ordered before non nil values
+func compareNullable[T any](a, b *T, compareFunc func(T, T) int) int {
+	if a == nil && b == nil {
+		return 0
+	} else if a == nil {
+		return -1
+	} else if b == nil {
+		return 1
+	}
+
+	return compareFunc(*a, *b)
+}
+
+func compareAs[T any](a T, b any, compareFunc func(T, T) int) (int, error) {
+	bVal, ok := b.(T)
+	if !ok {
+		return 0, ComparisonTypeError
+	}
+
+	return compareFunc(a, bVal), nil
+}
+
+func compareNullableAs[T any](a *T, b any, compareFunc func(T, T) int) (int, error) {
+	bVal, ok := b.(*T)
+	if !ok {
+		return 0, ComparisonTypeError
+	}
+
+	return compareNullable(a, bVal, compareFunc), nil
+}
+
+// CompareValues returns -1, 0 or 1 if a is less than, equal to or greater
+// than b. Both values must be the same Comparable field type.
+func CompareValues(a, b any) (int, error) {
+	switch aVal := a.(type) {
+	case fields.Int:
+		return compareAs(aVal, b, compareOrdered[fields.Int])
+	case fields.NullInt:
+		return compareNullableAs(aVal, b, compareOrdered[fields.Int])
+	case fields.UInt:
+		return compareAs(aVal, b, compareOrdered[fields.UInt])
+	case fields.NullUInt:
+		return compareNullableAs(aVal, b, compareOrdered[fields.UInt])
+	case fields.BigInt:
+		return compareAs(aVal, b, compareOrdered[fields.BigInt])
+	case fields.NullBigInt:
+		return compareNullableAs(aVal, b, compareOrdered[fields.BigInt])
+	case fields.BigUInt:
+		return compareAs(aVal, b, compareOrdered[fields.BigUInt])
+	case fields.NullBigUInt:
+		return compareNullableAs(aVal, b, compareOrdered[fields.BigUInt])
+	case fields.SmallFloat:
+		return compareAs(aVal, b, compareOrdered[fields.SmallFloat])
+	case fields.NullSmallFloat:
+		return compareNullableAs(aVal, b, compareOrdered[fields.SmallFloat])
+	case fields.Float:
+		return compareAs(aVal, b, compareOrdered[fields.Float])
+	case fields.NullFloat:
+		return compareNullableAs(aVal, b, compareOrdered[fields.Float])
+	case fields.String:
+		return compareAs(aVal, b, compareOrdered[fields.String])
+	case fields.NullString:
+		return compareNullableAs(aVal, b, compareOrdered[fields.String])
+	case fields.Bool:
+		return compareAs(aVal, b, compareBool)
+	case fields.NullBool:
+		return compareNullableAs(aVal, b, compareBool)
+	case fields.Time:
+		return compareAs(aVal, b, compareTime)
+	case fields.NullTime:
+		return compareNullableAs(aVal, b, compareTime)
+	default:
+		return 0, ComparisonTypeError
+	}
+}
+
+// Matches reports whether value satisfies the comparator. A nil comparator
+// places no restriction on the value and always matches.
+func (c *Comparator[T]) Matches(value any) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+
+	numValues := 1
+	if c.Op == BTW {
+		numValues = 2
+	}
+
+	if len(c.Values) != numValues {
+		return false, ComparatorValuesError
+	}
+
+	result, err := CompareValues(value, c.Values[0])
+	if err != nil {
+		return false, err
+	}
+
+	switch c.Op {
+	case EQ:
+		return result == 0, nil
+	case LT:
+		return result < 0, nil
+	case LTE:
+		return result <= 0, nil
+	case GT:
+		return result > 0, nil
+	case GTE:
+		return result >= 0, nil
+	case BTW:
+		upperResult, err := CompareValues(value, c.Values[1])
+		if err != nil {
+			return false, err
+		}
+
+		return result >= 0 && upperResult <= 0, nil
+	default:
+		return false, ComparatorValuesError
+	}
+}
+
+func (c *Comparator[T]) Operator() Operator {
+	return c.Op
+}
+
+func (c *Comparator[T]) Operands() []any {
+	operands := make([]any, len(c.Values))
+	for i, value := range c.Values {
+		operands[i] = value
+	}
+
+	return operands
+}
@@ -0,0 +1,90 @@
+package compare_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	one, two := 1, 2
+	str := "a"
+
+	type compareInputVal struct {
+		a any
+		b any
+	}
+
+	type compareExpectedVal struct {
+		result int
+		err    error
+	}
+
+	tests := &testutils.Tests[*compareInputVal, *compareExpectedVal]{
+		Cases: []testutils.TestCase[*compareInputVal, *compareExpectedVal]{
+			{Name: "int less", Input: &compareInputVal{fields.Int(1), fields.Int(2)}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "uint greater", Input: &compareInputVal{fields.UInt(2), fields.UInt(1)}, Expected: &compareExpectedVal{1, nil}},
+			{Name: "big int equal", Input: &compareInputVal{fields.BigInt(1), fields.BigInt(1)}, Expected: &compareExpectedVal{0, nil}},
+			{Name: "big uint less", Input: &compareInputVal{fields.BigUInt(1), fields.BigUInt(2)}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "small float greater", Input: &compareInputVal{fields.SmallFloat(1.5), fields.SmallFloat(1.25)}, Expected: &compareExpectedVal{1, nil}},
+			{Name: "float less", Input: &compareInputVal{fields.Float(1.25), fields.Float(1.5)}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "string less", Input: &compareInputVal{fields.String("a"), fields.String("b")}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "bool greater", Input: &compareInputVal{fields.Bool(true), fields.Bool(false)}, Expected: &compareExpectedVal{1, nil}},
+			{Name: "time less", Input: &compareInputVal{now, now.Add(time.Second)}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "null int less", Input: &compareInputVal{&one, &two}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "null before non null", Input: &compareInputVal{fields.NullInt(nil), &one}, Expected: &compareExpectedVal{-1, nil}},
+			{Name: "both null", Input: &compareInputVal{fields.NullString(nil), fields.NullString(nil)}, Expected: &compareExpectedVal{0, nil}},
+			{Name: "non null after null", Input: &compareInputVal{&str, fields.NullString(nil)}, Expected: &compareExpectedVal{1, nil}},
+			{Name: "mismatched types", Input: &compareInputVal{fields.Int(1), fields.BigInt(1)}, Expected: &compareExpectedVal{0, compare.ComparisonTypeError}},
+			{Name: "unsupported type", Input: &compareInputVal{fields.JsonMap{}, fields.JsonMap{}}, Expected: &compareExpectedVal{0, compare.ComparisonTypeError}},
+		},
+		Func: func(t *testing.T, input *compareInputVal, expected *compareExpectedVal) {
+			actual, err := compare.CompareValues(input.a, input.b)
+			testutils.AssertErrorEquals(t, expected.err, err)
+			testutils.AssertEquals(t, expected.result, actual)
+		},
+	}
+
+	tests.Run(t)
+}
+
+func TestMatches(t *testing.T) {
+	type matchesInputVal struct {
+		comparator compare.Matcher
+		value      any
+	}
+
+	type matchesExpectedVal struct {
+		matches bool
+		err     error
+	}
+
+	tests := &testutils.Tests[*matchesInputVal, *matchesExpectedVal]{
+		Cases: []testutils.TestCase[*matchesInputVal, *matchesExpectedVal]{
+			{Name: "nil comparator", Input: &matchesInputVal{(*compare.Comparator[fields.Int])(nil), 1}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "eq matches", Input: &matchesInputVal{compare.Eq(1), 1}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "eq does not match", Input: &matchesInputVal{compare.Eq(1), 2}, Expected: &matchesExpectedVal{false, nil}},
+			{Name: "lt matches", Input: &matchesInputVal{compare.Lt("b"), "a"}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "lt does not match equal", Input: &matchesInputVal{compare.Lt("b"), "b"}, Expected: &matchesExpectedVal{false, nil}},
+			{Name: "lte matches equal", Input: &matchesInputVal{compare.Lte(1.5), 1.5}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "gt matches", Input: &matchesInputVal{compare.Gt(false), true}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "gte does not match", Input: &matchesInputVal{compare.Gte(2), 1}, Expected: &matchesExpectedVal{false, nil}},
+			{Name: "btw matches lower bound", Input: &matchesInputVal{compare.Btw(1, 3), 1}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "btw matches upper bound", Input: &matchesInputVal{compare.Btw(1, 3), 3}, Expected: &matchesExpectedVal{true, nil}},
+			{Name: "btw does not match", Input: &matchesInputVal{compare.Btw(1, 3), 4}, Expected: &matchesExpectedVal{false, nil}},
+			{Name: "wrong value type", Input: &matchesInputVal{compare.Eq(1), "1"}, Expected: &matchesExpectedVal{false, compare.ComparisonTypeError}},
+			{Name: "wrong number of values", Input: &matchesInputVal{&compare.Comparator[fields.Int]{Op: compare.BTW, Values: []int{1}}, 1}, Expected: &matchesExpectedVal{false, compare.ComparatorValuesError}},
+		},
+		Func: func(t *testing.T, input *matchesInputVal, expected *matchesExpectedVal) {
+			actual, err := input.comparator.Matches(input.value)
+			testutils.AssertErrorEquals(t, expected.err, err)
+			testutils.AssertEquals(t, expected.matches, actual)
+		},
+	}
+
+	tests.Run(t)
+}
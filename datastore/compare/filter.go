@@ -0,0 +1,114 @@
+package compare
+
+import "github.com/sophielizg/go-libs/datastore/mutator"
+
+type FilterOperator = int8
+
+const (
+	FIELD FilterOperator = iota
+	AND
+	OR
+	NOT
+)
+
+// Filter is a condition on the fields of an entry, a nil filter matches
+// every entry
+type Filter struct {
+	Op         FilterOperator
+	FieldName  string
+	Comparator any
+	Filters    []*Filter
+}
+
+// Field matches entries where the value of fieldName satisfies comparator
+func Field[T Comparable](fieldName string, comparator *Comparator[T]) *Filter {
+	return &Filter{
+		Op:         FIELD,
+		FieldName:  fieldName,
+		Comparator: comparator,
+	}
+}
+
+// And matches entries which match every filter, or every entry if there are
+// none
+func And(filters ...*Filter) *Filter {
+	return &Filter{
+		Op:      AND,
+		Filters: filters,
+	}
+}
+
+// Or matches entries which match any filter, or no entries if there are none
+func Or(filters ...*Filter) *Filter {
+	return &Filter{
+		Op:      OR,
+		Filters: filters,
+	}
+}
+
+func Not(filter *Filter) *Filter {
+	return &Filter{
+		Op:      NOT,
+		Filters: []*Filter{filter},
+	}
+}
+
+// Matches evaluates the filter against the fields of entry
+func (f *Filter) Matches(entry mutator.MappedFieldValues) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	switch f.Op {
+	case FIELD:
+		matcher, ok := f.Comparator.(Matcher)
+		if !ok {
+			return false, ComparisonTypeError
+		}
+
+		return matcher.Matches(entry[f.FieldName])
+	case AND:
+		for _, filter := range f.Filters {
+			if matches, err := filter.Matches(entry); err != nil || !matches {
+				return false, err
+			}
+		}
+
+		return true, nil
+	case OR:
+		for _, filter := range f.Filters {
+			if matches, err := filter.Matches(entry); err != nil || matches {
+				return matches, err
+			}
+		}
+
+		return false, nil
+	case NOT:
+		if len(f.Filters) != 1 {
+			return false, FilterValuesError
+		}
+
+		matches, err := f.Filters[0].Matches(entry)
+		return !matches && err == nil, err
+	default:
+		return false, FilterValuesError
+	}
+}
+
+// FieldNames returns the name of every field the filter references
+func (f *Filter) FieldNames() []string {
+	if f == nil {
+		return nil
+	}
+
+	if f.Op == FIELD {
+		return []string{f.FieldName}
+	}
+
+	fieldNames := []string{}
+	for _, filter := range f.Filters {
+		fieldNames = append(fieldNames, filter.FieldNames()...)
+	}
+
+	return fieldNames
+}
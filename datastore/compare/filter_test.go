@@ -0,0 +1,57 @@
+package compare_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestFilterMatches(t *testing.T) {
+	entry := mutator.MappedFieldValues{"1": "b", "2": 2}
+
+	type filterExpectedVal struct {
+		matches bool
+		err     error
+	}
+
+	tests := &testutils.Tests[*compare.Filter, *filterExpectedVal]{
+		Cases: []testutils.TestCase[*compare.Filter, *filterExpectedVal]{
+			{Name: "nil filter", Input: nil, Expected: &filterExpectedVal{true, nil}},
+			{Name: "field matches", Input: compare.Field("1", compare.Eq("b")), Expected: &filterExpectedVal{true, nil}},
+			{Name: "field does not match", Input: compare.Field("2", compare.Gt(2)), Expected: &filterExpectedVal{false, nil}},
+			{Name: "and matches", Input: compare.And(compare.Field("1", compare.Eq("b")), compare.Field("2", compare.Lte(2))), Expected: &filterExpectedVal{true, nil}},
+			{Name: "and does not match", Input: compare.And(compare.Field("1", compare.Eq("b")), compare.Field("2", compare.Lt(2))), Expected: &filterExpectedVal{false, nil}},
+			{Name: "empty and", Input: compare.And(), Expected: &filterExpectedVal{true, nil}},
+			{Name: "or matches", Input: compare.Or(compare.Field("1", compare.Eq("a")), compare.Field("2", compare.Eq(2))), Expected: &filterExpectedVal{true, nil}},
+			{Name: "or does not match", Input: compare.Or(compare.Field("1", compare.Eq("a")), compare.Field("2", compare.Eq(1))), Expected: &filterExpectedVal{false, nil}},
+			{Name: "empty or", Input: compare.Or(), Expected: &filterExpectedVal{false, nil}},
+			{Name: "not", Input: compare.Not(compare.Field("1", compare.Eq("a"))), Expected: &filterExpectedVal{true, nil}},
+			{Name: "nested", Input: compare.Not(compare.Or(compare.And(), compare.Field("2", compare.Eq(1)))), Expected: &filterExpectedVal{false, nil}},
+			{Name: "wrong value type", Input: compare.Field("2", compare.Eq("2")), Expected: &filterExpectedVal{false, compare.ComparisonTypeError}},
+			{Name: "not with error", Input: compare.Not(compare.Field("2", compare.Eq("2"))), Expected: &filterExpectedVal{false, compare.ComparisonTypeError}},
+			{Name: "not a comparator", Input: &compare.Filter{Op: compare.FIELD, FieldName: "1", Comparator: "b"}, Expected: &filterExpectedVal{false, compare.ComparisonTypeError}},
+			{Name: "unknown operator", Input: &compare.Filter{Op: 10}, Expected: &filterExpectedVal{false, compare.FilterValuesError}},
+		},
+		Func: func(t *testing.T, input *compare.Filter, expected *filterExpectedVal) {
+			actual, err := input.Matches(entry)
+			testutils.AssertErrorEquals(t, expected.err, err)
+			testutils.AssertEquals(t, expected.matches, actual)
+		},
+	}
+
+	tests.Run(t)
+}
+
+func TestFilterFieldNames(t *testing.T) {
+	filter := compare.And(
+		compare.Field("1", compare.Eq("a")),
+		compare.Not(compare.Or(compare.Field("2", compare.Eq(1)))),
+	)
+
+	fieldNames := filter.FieldNames()
+	testutils.AssertEquals(t, 2, len(fieldNames))
+	testutils.AssertEquals(t, "1", fieldNames[0])
+	testutils.AssertEquals(t, "2", fieldNames[1])
+}
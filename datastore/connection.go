@@ -43,6 +43,10 @@ type Table[B any] interface {
 func RegisterAppendTable[C Connection, TB AppendTableBackend[C], T Table[AppendTableBackendQueries]](table T, tableBackend TB) func(*ConnectionGroup[C]) error {
 	return func(g *ConnectionGroup[C]) error {
 		table.Init()
+		if err := table.GetSettings().Validate(); err != nil {
+			return err
+		}
+
 		table.SetBackend(tableBackend)
 		tableBackend.SetConnection(g.Conn)
 		tableBackend.SetSettings(table.GetSettings())
@@ -53,6 +57,10 @@ func RegisterAppendTable[C Connection, TB AppendTableBackend[C], T Table[AppendT
 func RegisterHashTable[C Connection, TB HashTableBackend[C], T Table[HashTableBackendQueries]](table T, tableBackend TB) func(*ConnectionGroup[C]) error {
 	return func(g *ConnectionGroup[C]) error {
 		table.Init()
+		if err := table.GetSettings().Validate(); err != nil {
+			return err
+		}
+
 		table.SetBackend(tableBackend)
 		tableBackend.SetConnection(g.Conn)
 		tableBackend.SetSettings(table.GetSettings())
@@ -63,6 +71,10 @@ func RegisterHashTable[C Connection, TB HashTableBackend[C], T Table[HashTableBa
 func RegisterSortTable[C Connection, TB SortTableBackend[C], T Table[SortTableBackendQueries]](table T, tableBackend TB) func(*ConnectionGroup[C]) error {
 	return func(g *ConnectionGroup[C]) error {
 		table.Init()
+		if err := table.GetSettings().Validate(); err != nil {
+			return err
+		}
+
 		table.SetBackend(tableBackend)
 		tableBackend.SetConnection(g.Conn)
 		tableBackend.SetSettings(table.GetSettings())
@@ -73,6 +85,10 @@ func RegisterSortTable[C Connection, TB SortTableBackend[C], T Table[SortTableBa
 func RegisterQueue[C Connection, TB QueueBackend[C], T Table[QueueBackendQueries]](table T, tableBackend TB) func(*ConnectionGroup[C]) error {
 	return func(g *ConnectionGroup[C]) error {
 		table.Init()
+		if err := table.GetSettings().Validate(); err != nil {
+			return err
+		}
+
 		table.SetBackend(tableBackend)
 		tableBackend.SetConnection(g.Conn)
 		tableBackend.SetSettings(table.GetSettings())
@@ -83,6 +99,10 @@ func RegisterQueue[C Connection, TB QueueBackend[C], T Table[QueueBackendQueries
 func RegisterTopic[C Connection, TB TopicBackend[C], T Table[TopicBackendQueries]](table T, tableBackend TB) func(*ConnectionGroup[C]) error {
 	return func(g *ConnectionGroup[C]) error {
 		table.Init()
+		if err := table.GetSettings().Validate(); err != nil {
+			return err
+		}
+
 		table.SetBackend(tableBackend)
 		tableBackend.SetConnection(g.Conn)
 		tableBackend.SetSettings(table.GetSettings())
@@ -0,0 +1,51 @@
+package datastore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+// EncodeCursor returns an opaque cursor holding the values of the last entry
+// of a page
+func EncodeCursor(values mutator.MappedFieldValues) (string, error) {
+	bytes, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// DecodeCursor reads the values of a cursor made by EncodeCursor, which must
+// have exactly fieldNames
+func DecodeCursor(cursor string, emptyValues mutator.MappedFieldValues, fieldNames []string) (mutator.MappedFieldValues, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, InvalidCursorError
+	}
+
+	encoded := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bytes, &encoded); err != nil || len(encoded) != len(fieldNames) {
+		return nil, InvalidCursorError
+	}
+
+	values := mutator.MappedFieldValues{}
+	for _, fieldName := range fieldNames {
+		emptyValue := emptyValues[fieldName]
+		encodedValue, ok := encoded[fieldName]
+		if !ok || emptyValue == nil {
+			return nil, InvalidCursorError
+		}
+
+		value := reflect.New(reflect.TypeOf(emptyValue))
+		if err := json.Unmarshal(encodedValue, value.Interface()); err != nil {
+			return nil, InvalidCursorError
+		}
+		values[fieldName] = value.Elem().Interface()
+	}
+
+	return values, nil
+}
@@ -0,0 +1,201 @@
+package datastoretest
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// MOCKS
+
+const (
+	QuantityKey = "Quantity"
+	PriceKey    = "Price"
+	TimeKey     = "Time"
+)
+
+type MockAggregateData struct {
+	Group    fields.String
+	Quantity fields.Int
+	Price    fields.Float
+	Time     fields.Time
+}
+
+func (d *MockAggregateData) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(GroupKey, &d.Group),
+		mutator.WithAddress(QuantityKey, &d.Quantity),
+		mutator.WithAddress(PriceKey, &d.Price),
+		mutator.WithAddress(TimeKey, &d.Time),
+	)
+}
+
+var MockAggregateDataSettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithNumBytes(GroupKey, 63),
+	),
+	FieldOrder: fields.OrderedFieldKeys{GroupKey, QuantityKey, PriceKey, TimeKey},
+}
+
+type MockAggregateEntry = fields.KeyedEntry[MockKey, *MockKey, MockAggregateData, *MockAggregateData]
+
+type MockAggregateTable = datastore.HashTable[MockKey, *MockKey, MockAggregateEntry, *MockAggregateEntry]
+
+func NewMockAggregateTable() *MockAggregateTable {
+	return &MockAggregateTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestAggregate"),
+			datastore.WithDataSettings(MockAggregateDataSettings),
+			datastore.WithKeySettings(MockKeySettings),
+		),
+	}
+}
+
+// TESTS
+
+// TestHashTableAggregate checks every aggregate function, with and without
+// filters and group by fields
+func TestHashTableAggregate(t *testing.T, mockTable *MockAggregateTable) {
+	t.Helper()
+
+	startTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	groups := []string{"a", "b", "a", "b", "a"}
+	entries := make([]*MockAggregateEntry, len(groups))
+	for i, group := range groups {
+		entries[i] = &MockAggregateEntry{
+			Key: &MockKey{Id: "testaggregate" + strconv.Itoa(i)},
+			Data: &MockAggregateData{
+				Group:    group,
+				Quantity: i + 1,
+				Price:    float64(i) + 0.5,
+				Time:     startTime.Add(time.Duration(i) * time.Hour),
+			},
+		}
+	}
+
+	// a table with no entries still has one result when not grouped
+	results, err := mockTable.Aggregate([]*queries.Aggregate{queries.Count(), queries.Sum(QuantityKey)})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(results))
+	testutils.AssertTrue(t, results[0].Values["Count"] == fields.Int(0))
+	testutils.AssertTrue(t, results[0].Values["SumQuantity"] == fields.Int(0))
+
+	_, err = mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	aggregates := []*queries.Aggregate{
+		queries.Count(),
+		queries.Sum(QuantityKey),
+		queries.Sum(PriceKey),
+		queries.Min(TimeKey),
+		queries.Max(QuantityKey),
+		queries.Avg(QuantityKey),
+	}
+
+	results, err = mockTable.Aggregate(aggregates)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(results))
+	if len(results) == 1 {
+		testutils.AssertTrue(t, results[0].Values["Count"] == fields.Int(5))
+		testutils.AssertTrue(t, results[0].Values["SumQuantity"] == fields.Int(15))
+		testutils.AssertTrue(t, results[0].Values["SumPrice"] == fields.Float(12.5))
+		testutils.AssertTrue(t, startTime.Equal(results[0].Values["MinTime"].(fields.Time)))
+		testutils.AssertTrue(t, results[0].Values["MaxQuantity"] == fields.Int(5))
+		testutils.AssertTrue(t, results[0].Values["AvgQuantity"] == fields.Float(3))
+	}
+
+	results, err = mockTable.Aggregate(aggregates, queries.WithGroupBy(GroupKey))
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(results))
+	if len(results) == 2 {
+		testutils.AssertEquals(t, "a", results[0].Group.Data.Group)
+		testutils.AssertTrue(t, results[0].Values["Count"] == fields.Int(3))
+		testutils.AssertTrue(t, results[0].Values["SumQuantity"] == fields.Int(9))
+		testutils.AssertTrue(t, results[0].Values["SumPrice"] == fields.Float(7.5))
+		testutils.AssertTrue(t, results[0].Values["MaxQuantity"] == fields.Int(5))
+
+		testutils.AssertEquals(t, "b", results[1].Group.Data.Group)
+		testutils.AssertTrue(t, results[1].Values["Count"] == fields.Int(2))
+		testutils.AssertTrue(t, startTime.Add(time.Hour).Equal(results[1].Values["MinTime"].(fields.Time)))
+		testutils.AssertTrue(t, results[1].Values["AvgQuantity"] == fields.Float(3))
+	}
+
+	results, err = mockTable.Aggregate(
+		[]*queries.Aggregate{queries.Count()},
+		queries.WithGroupBy(GroupKey),
+		queries.WithFilter(compare.Field(QuantityKey, compare.Gt(2))),
+	)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(results))
+	if len(results) == 2 {
+		testutils.AssertTrue(t, results[0].Values["Count"] == fields.Int(2))
+		testutils.AssertTrue(t, results[1].Values["Count"] == fields.Int(1))
+	}
+
+	_, err = mockTable.Aggregate([]*queries.Aggregate{queries.Sum(GroupKey)})
+	testutils.AssertErrorEquals(t, datastore.AggregateTypeError, err)
+
+	_, err = mockTable.Aggregate([]*queries.Aggregate{queries.Avg(TimeKey)})
+	testutils.AssertErrorEquals(t, datastore.AggregateTypeError, err)
+
+	_, err = mockTable.Aggregate([]*queries.Aggregate{queries.Count()}, queries.WithGroupBy("MissingField"))
+	testutils.AssertErrorEquals(t, datastore.FieldDoesNotExistError, err)
+
+	_, err = mockTable.Aggregate([]*queries.Aggregate{queries.Count(), queries.Count()})
+	testutils.AssertErrorEquals(t, queries.AggregateNameError, err)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestAppendTableAggregate(t *testing.T, mockTable *MockAppendTable) {
+	t.Helper()
+
+	_, err := mockTable.Add(GenerateNonKeyedEntries(3, "testaggregate")...)
+	testutils.AssertOk(t, err)
+
+	results, err := mockTable.Aggregate(
+		[]*queries.Aggregate{queries.Count()},
+		queries.WithGroupBy(DataKey),
+		queries.WithFilter(compare.Field(DataKey, compare.Lt("testaggregate2"))),
+	)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(results))
+	if len(results) == 2 {
+		testutils.AssertEquals(t, "testaggregate0", results[0].Group.Data.Data)
+		testutils.AssertEquals(t, "testaggregate1", results[1].Group.Data.Data)
+		testutils.AssertTrue(t, results[1].Values["Count"] == fields.Int(1))
+	}
+}
+
+func TestSortTableAggregate(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := append(GenerateSortEntries(3, "testaggregate"), GenerateSortEntries(2, "testaggregateother")...)
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	results, err := mockTable.Aggregate(
+		[]*queries.Aggregate{queries.Count(), queries.Max(SortKey)},
+		queries.WithGroupBy(IdKey),
+	)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(results))
+	if len(results) == 2 {
+		testutils.AssertEquals(t, "testaggregate", results[0].Group.Key.Id)
+		testutils.AssertTrue(t, results[0].Values["Count"] == fields.Int(3))
+		testutils.AssertTrue(t, results[0].Values["MaxSort"] == fields.Int(2))
+		testutils.AssertEquals(t, "testaggregateother", results[1].Group.Key.Id)
+		testutils.AssertTrue(t, results[1].Values["MaxSort"] == fields.Int(1))
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
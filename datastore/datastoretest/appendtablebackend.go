@@ -0,0 +1,89 @@
+package datastoretest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// HELPERS
+
+func GenerateNonKeyedEntries(numEntries int, dataPrefix string) []*MockNonKeyedEntry {
+	entries := make([]*MockNonKeyedEntry, numEntries)
+
+	for i := 0; i < numEntries; i += 1 {
+		entries[i] = &MockNonKeyedEntry{
+			Data: &MockData{
+				Data: dataPrefix + strconv.Itoa(i),
+			},
+		}
+	}
+
+	return entries
+}
+
+// MOCKS
+
+type MockNonKeyedEntry = fields.Entry[MockData, *MockData]
+
+type MockAppendTable = datastore.AppendTable[MockNonKeyedEntry, *MockNonKeyedEntry]
+
+func NewMockAppendTable() *MockAppendTable {
+	return &MockAppendTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestAppend"),
+			datastore.WithDataSettings(MockDataSettings),
+		),
+	}
+}
+
+// TESTS
+
+func TestAppendTableAddAndScan(t *testing.T, mockTable *MockAppendTable) {
+	t.Helper()
+
+	entries := GenerateNonKeyedEntries(5, "testappend")
+
+	actualAddEntries, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(entries), len(actualAddEntries))
+
+	// duplicate entries are allowed in an append table
+	_, err = mockTable.Add(entries[0])
+	testutils.AssertOk(t, err)
+
+	dataChan, errorChan := mockTable.Scan(2)
+	scanned := CollectScan(t, dataChan, errorChan)
+	testutils.AssertEquals(t, len(entries)+1, len(scanned))
+
+	scannedCounts := map[string]int{}
+	for _, entry := range scanned {
+		scannedCounts[entry.Data.Data] += 1
+	}
+
+	testutils.AssertEquals(t, 2, scannedCounts[entries[0].Data.Data])
+	for _, entry := range entries[1:] {
+		testutils.AssertEquals(t, 1, scannedCounts[entry.Data.Data])
+	}
+}
+
+func TestAppendTableScanEmpty(t *testing.T, mockTable *MockAppendTable) {
+	t.Helper()
+
+	dataChan, errorChan := mockTable.Scan(2)
+	scanned := CollectScan(t, dataChan, errorChan)
+	testutils.AssertEquals(t, 0, len(scanned))
+}
+
+func TestAppendTableScanCancelled(t *testing.T, mockTable *MockAppendTable) {
+	_, err := mockTable.Add(GenerateNonKeyedEntries(scanCancelledEntries, "testscancancelled")...)
+	testutils.AssertOk(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dataChan, errorChan := mockTable.ScanContext(ctx, 1)
+	assertScanCancelled(t, dataChan, errorChan, cancel, scanCancelledEntries)
+}
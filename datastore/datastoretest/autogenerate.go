@@ -0,0 +1,195 @@
+package datastoretest
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// MOCKS
+
+type MockAutoGenerateKey struct {
+	Id fields.BigInt
+}
+
+func (k *MockAutoGenerateKey) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(IdKey, &k.Id),
+	)
+}
+
+var MockAutoGenerateKeySettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithAutoGenerate(IdKey),
+	),
+	FieldOrder: fields.OrderedFieldKeys{IdKey},
+}
+
+type MockAutoGenerateEntry = fields.KeyedEntry[MockAutoGenerateKey, *MockAutoGenerateKey, MockData, *MockData]
+
+type MockAutoGenerateTable = datastore.HashTable[MockAutoGenerateKey, *MockAutoGenerateKey, MockAutoGenerateEntry, *MockAutoGenerateEntry]
+
+func NewMockAutoGenerateTable() *MockAutoGenerateTable {
+	return &MockAutoGenerateTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestAutoGenerate"),
+			datastore.WithDataSettings(MockDataSettings),
+			datastore.WithKeySettings(MockAutoGenerateKeySettings),
+		),
+	}
+}
+
+// NewMockGeneratedTable creates a table whose ids are generated as UUIDv7
+// strings before entries reach the backend
+func NewMockGeneratedTable() *MockTable {
+	return &MockTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestGenerated"),
+			datastore.WithDataSettings(MockDataSettings),
+			datastore.WithKeySettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(
+					fields.WithNumBytes(IdKey, 63),
+					fields.WithGenerator(IdKey, fields.UUIDv7),
+				),
+				FieldOrder: fields.OrderedFieldKeys{IdKey},
+			}),
+		),
+	}
+}
+
+// NewMockGeneratedSortTable creates a sort table whose sort values are
+// generated as snowflake ids, so entries sort in the order they are added
+func NewMockGeneratedSortTable() *MockSortTable {
+	return &MockSortTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestGeneratedSort"),
+			datastore.WithDataSettings(MockDataSettings),
+			datastore.WithKeySettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(
+					fields.WithNumBytes(IdKey, 63),
+					fields.WithGenerator(SortKey, fields.NewSnowflake(0)),
+				),
+				FieldOrder: fields.OrderedFieldKeys{IdKey, SortKey},
+			}),
+			datastore.WithSortFieldNames(MockSortFieldNames),
+		),
+	}
+}
+
+// TESTS
+
+// TestHashTableAutoGenerate checks added entries are returned with increasing
+// generated ids, which can be used to get them
+func TestHashTableAutoGenerate(t *testing.T, mockTable *MockAutoGenerateTable) {
+	t.Helper()
+
+	entries := []*MockAutoGenerateEntry{
+		{Key: &MockAutoGenerateKey{}, Data: &MockData{Data: "testautogenerate0"}},
+		{Key: &MockAutoGenerateKey{}, Data: &MockData{Data: "testautogenerate1"}},
+	}
+
+	added, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(added))
+	if len(added) != 2 {
+		return
+	}
+
+	testutils.AssertTrue(t, added[0].Key.Id > 0)
+	testutils.AssertTrue(t, added[1].Key.Id > added[0].Key.Id)
+
+	more, err := mockTable.Add(&MockAutoGenerateEntry{Key: &MockAutoGenerateKey{}, Data: &MockData{Data: "testautogenerate2"}})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(more))
+	if len(more) == 1 {
+		testutils.AssertTrue(t, more[0].Key.Id > added[1].Key.Id)
+	}
+
+	found, err := mockTable.Get(added[1].Key, added[0].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(found))
+	if len(found) == 2 {
+		testutils.AssertEquals(t, "testautogenerate1", found[0].Data.Data)
+		testutils.AssertEquals(t, "testautogenerate0", found[1].Data.Data)
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(append(added, more...))...)
+	testutils.AssertOk(t, err)
+}
+
+// TestHashTableGenerated checks ids made by a generator are stored as they
+// were generated, and that ids which are set are kept
+func TestHashTableGenerated(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	uuidPattern := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+	entries := []*MockEntry{
+		{Key: &MockKey{}, Data: &MockData{Data: "testgenerated0"}},
+		{Key: &MockKey{Id: "testgenerated1"}, Data: &MockData{Data: "testgenerated1"}},
+	}
+
+	added, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(added))
+	if len(added) != 2 {
+		return
+	}
+
+	testutils.AssertTrue(t, uuidPattern.MatchString(added[0].Key.Id))
+	testutils.AssertEquals(t, "testgenerated1", added[1].Key.Id)
+
+	upserted, err := mockTable.Upsert(&MockEntry{Key: &MockKey{}, Data: &MockData{Data: "testgenerated2"}})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(upserted))
+	if len(upserted) == 1 {
+		testutils.AssertTrue(t, uuidPattern.MatchString(upserted[0].Key.Id))
+	}
+
+	found, err := mockTable.Get(added[0].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(found))
+	if len(found) == 1 {
+		testutils.AssertEquals(t, added[0].Key.Id, found[0].Key.Id)
+		testutils.AssertEquals(t, "testgenerated0", found[0].Data.Data)
+	}
+
+	count, err := mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, count)
+}
+
+// TestSortTableGenerated checks generated sort values increase in the order
+// entries are added, and that sort values which are set are kept
+func TestSortTableGenerated(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := []*MockSortEntry{
+		{Key: &MockSortKey{Id: "testgenerated"}, Data: &MockData{Data: "testgenerated0"}},
+		{Key: &MockSortKey{Id: "testgenerated"}, Data: &MockData{Data: "testgenerated1"}},
+		{Key: &MockSortKey{Id: "testgenerated", Sort: 1}, Data: &MockData{Data: "testgeneratedset"}},
+	}
+
+	added, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, len(added))
+	if len(added) != 3 {
+		return
+	}
+
+	testutils.AssertTrue(t, added[0].Key.Sort > 1)
+	testutils.AssertTrue(t, added[1].Key.Sort > added[0].Key.Sort)
+	testutils.AssertEquals(t, 1, added[2].Key.Sort)
+
+	found, err := mockTable.GetWithSortComparator(&MockSortKey{Id: "testgenerated"}, &MockSortComparator{})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, len(found))
+	if len(found) == 3 {
+		testutils.AssertEquals(t, "testgeneratedset", found[0].Data.Data)
+		testutils.AssertEquals(t, "testgenerated0", found[1].Data.Data)
+		testutils.AssertEquals(t, "testgenerated1", found[2].Data.Data)
+	}
+}
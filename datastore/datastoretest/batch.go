@@ -0,0 +1,62 @@
+package datastoretest
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// TestHashTableAddInBatches checks adding more entries than fit in one batch
+func TestHashTableAddInBatches(t *testing.T, mockTable *MockTable, backend any) {
+	t.Helper()
+
+	batchSizer, ok := backend.(queries.BatchSizer)
+	if !ok || batchSizer.MaxBatchSize() <= 0 {
+		return
+	}
+
+	maxBatchSize := batchSizer.MaxBatchSize()
+	entries := GenerateEntries(maxBatchSize+1, "testbatch")
+	added, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(entries), len(added))
+	if len(added) == len(entries) {
+		testutils.AssertEquals(t, entries[maxBatchSize].Key.Id, added[maxBatchSize].Key.Id)
+	}
+
+	count, err := mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(entries), count)
+
+	found, err := mockTable.Get(entries[maxBatchSize].Key, entries[0].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(found))
+	if len(found) == 2 {
+		testutils.AssertEquals(t, entries[maxBatchSize].Key.Id, found[0].Key.Id)
+		testutils.AssertEquals(t, entries[0].Key.Id, found[1].Key.Id)
+	}
+}
+
+// TestSortTableAddInBatches is TestHashTableAddInBatches for sort tables
+func TestSortTableAddInBatches(t *testing.T, mockTable *MockSortTable, backend any) {
+	t.Helper()
+
+	batchSizer, ok := backend.(queries.BatchSizer)
+	if !ok || batchSizer.MaxBatchSize() <= 0 {
+		return
+	}
+
+	maxBatchSize := batchSizer.MaxBatchSize()
+	entries := GenerateSortEntries(maxBatchSize+1, "testbatch")
+	added, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(entries), len(added))
+
+	found, err := mockTable.GetWithSortComparator(&MockSortKey{Id: "testbatch"}, &MockSortComparator{})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(entries), len(found))
+	for i := range found {
+		testutils.AssertEquals(t, i, found[i].Key.Sort)
+	}
+}
@@ -0,0 +1,131 @@
+package datastoretest
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// HELPERS
+
+// collectScanError reads every entry from the channels returned by Scan,
+// expecting exactly one error
+func collectScanError[E any](t *testing.T, dataChan chan E, errorChan chan error) error {
+	t.Helper()
+
+	errs := []error{}
+	for dataChan != nil || errorChan != nil {
+		select {
+		case _, more := <-dataChan:
+			if !more {
+				dataChan = nil
+			}
+		case err, more := <-errorChan:
+			if !more {
+				errorChan = nil
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	testutils.AssertEquals(t, 1, len(errs))
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs[0]
+}
+
+// TESTS
+
+func TestAppendTableScanWithFilter(t *testing.T, mockTable *MockAppendTable) {
+	t.Helper()
+
+	entries := GenerateNonKeyedEntries(5, "testfilter")
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	dataChan, errorChan := mockTable.ScanWithFilter(2, compare.Or(
+		compare.Field(DataKey, compare.Eq("testfilter0")),
+		compare.Field(DataKey, compare.Gte("testfilter3")),
+	))
+	scanned := CollectScan(t, dataChan, errorChan)
+
+	scannedData := map[string]bool{}
+	for _, entry := range scanned {
+		scannedData[entry.Data.Data] = true
+	}
+
+	testutils.AssertEquals(t, 3, len(scanned))
+	testutils.AssertTrue(t, scannedData["testfilter0"])
+	testutils.AssertTrue(t, scannedData["testfilter3"])
+	testutils.AssertTrue(t, scannedData["testfilter4"])
+
+	dataChan, errorChan = mockTable.ScanWithFilter(2, compare.Field("MissingField", compare.Eq("")))
+	testutils.AssertErrorEquals(t, datastore.FieldDoesNotExistError, collectScanError(t, dataChan, errorChan))
+}
+
+// TestHashTableScanWithFilter checks filters can combine key and data fields,
+// and that projected scans only set the requested fields
+func TestHashTableScanWithFilter(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(5, "testfilter")
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	dataChan, errorChan := mockTable.ScanWithFilter(2, compare.And(
+		compare.Field(IdKey, compare.Btw("testfilter1", "testfilter3")),
+		compare.Not(compare.Field(DataKey, compare.Eq("2"))),
+	), IdKey)
+	scanned := CollectScan(t, dataChan, errorChan)
+
+	scannedIds := map[string]bool{}
+	for _, entry := range scanned {
+		scannedIds[entry.Key.Id] = true
+		testutils.AssertEquals(t, "", entry.Data.Data)
+	}
+
+	testutils.AssertEquals(t, 2, len(scanned))
+	testutils.AssertTrue(t, scannedIds["testfilter1"])
+	testutils.AssertTrue(t, scannedIds["testfilter3"])
+
+	// an empty or filter matches nothing
+	dataChan, errorChan = mockTable.ScanWithFilter(2, compare.Or())
+	testutils.AssertEquals(t, 0, len(CollectScan(t, dataChan, errorChan)))
+
+	dataChan, errorChan = mockTable.ScanWithFilter(2, nil, "MissingField")
+	testutils.AssertErrorEquals(t, datastore.FieldDoesNotExistError, collectScanError(t, dataChan, errorChan))
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+// TestSortTableScanWithFilter checks filtered scans keep the table order
+func TestSortTableScanWithFilter(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testfilter")
+	_, err := mockTable.Add(entries[3], entries[0], entries[4], entries[2], entries[1])
+	testutils.AssertOk(t, err)
+
+	dataChan, errorChan := mockTable.ScanWithFilter(2, compare.Or(
+		compare.Field(SortKey, compare.Lt(1)),
+		compare.Field(DataKey, compare.Gt("2")),
+	), IdKey, SortKey)
+	scanned := CollectScan(t, dataChan, errorChan)
+	assertSortValues(t, []int{0, 3, 4}, scanned)
+
+	for _, entry := range scanned {
+		testutils.AssertEquals(t, "testfilter", entry.Key.Id)
+		testutils.AssertEquals(t, "", entry.Data.Data)
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
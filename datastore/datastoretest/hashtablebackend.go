@@ -1,7 +1,9 @@
 package datastoretest
 
 import (
+	"context"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/sophielizg/go-libs/datastore"
@@ -84,6 +86,26 @@ func NewMockTable() *MockTable {
 	}
 }
 
+type MockKeyOnlyData struct{}
+
+func (d *MockKeyOnlyData) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator()
+}
+
+type MockKeyOnlyEntry = fields.KeyedEntry[MockKey, *MockKey, MockKeyOnlyData, *MockKeyOnlyData]
+
+type MockKeyOnlyTable = datastore.HashTable[MockKey, *MockKey, MockKeyOnlyEntry, *MockKeyOnlyEntry]
+
+// NewMockKeyOnlyTable creates a table without data fields
+func NewMockKeyOnlyTable() *MockKeyOnlyTable {
+	return &MockKeyOnlyTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestKeyOnly"),
+			datastore.WithKeySettings(MockKeySettings),
+		),
+	}
+}
+
 // TESTS
 
 func TestHashTableCount(t *testing.T, mockTable *MockTable) {
@@ -102,18 +124,26 @@ func TestHashTableCount(t *testing.T, mockTable *MockTable) {
 	testutils.AssertOk(t, err)
 }
 
-// TODO
 func TestHashTableScan(t *testing.T, mockTable *MockTable) {
 	t.Helper()
 
-	entries := GenerateEntries(1, "testcount")
+	entries := GenerateEntries(5, "testscan")
 
 	_, err := mockTable.Add(entries...)
 	testutils.AssertOk(t, err)
 
-	count, err := mockTable.Count()
-	testutils.AssertOk(t, err)
-	testutils.AssertTrue(t, count > 0)
+	dataChan, errorChan := mockTable.Scan(2)
+	scanned := CollectScan(t, dataChan, errorChan)
+	testutils.AssertEquals(t, len(entries), len(scanned))
+
+	scannedData := map[string]string{}
+	for _, entry := range scanned {
+		scannedData[entry.Key.Id] = entry.Data.Data
+	}
+
+	for _, entry := range entries {
+		testutils.AssertEquals(t, entry.Data.Data, scannedData[entry.Key.Id])
+	}
 
 	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
 	testutils.AssertOk(t, err)
@@ -158,6 +188,40 @@ func TestHashTableAdd(t *testing.T, mockTable *MockTable) {
 	testutils.AssertOk(t, err)
 }
 
+func TestHashTableGetMissing(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(2, "testgetmissing")
+
+	_, err := mockTable.Add(entries[0])
+	testutils.AssertOk(t, err)
+
+	// missing keys are left out of the results rather than returning an error
+	actualEntries, err := mockTable.Get(entries[1].Key, entries[0].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(actualEntries))
+	testutils.AssertEquals(t, entries[0].Key.Id, actualEntries[0].Key.Id)
+
+	err = mockTable.Delete(entries[0].Key)
+	testutils.AssertOk(t, err)
+}
+
+func TestHashTableAddDuplicate(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(1, "testaddduplicate")
+	entry := entries[0]
+
+	_, err := mockTable.Add(entry)
+	testutils.AssertOk(t, err)
+
+	_, err = mockTable.Add(entry)
+	testutils.AssertErrorEquals(t, datastore.KeyExistsError, err)
+
+	err = mockTable.Delete(entry.Key)
+	testutils.AssertOk(t, err)
+}
+
 func TestHashTableUpdate(t *testing.T, mockTable *MockTable) {
 	t.Helper()
 
@@ -201,3 +265,170 @@ func TestHashTableDelete(t *testing.T, mockTable *MockTable) {
 	testutils.AssertOk(t, err)
 	testutils.AssertEquals(t, 0, len(actualEntriesAfterDelete))
 }
+
+func TestHashTableUpdateMissing(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(1, "testupdatemissing")
+
+	err := mockTable.Update(entries[0])
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+
+	actualEntries, err := mockTable.Get(entries[0].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(actualEntries))
+}
+
+// TestHashTableKeyOnlyUpdate checks an update with no data fields still fails
+// for a missing key
+func TestHashTableKeyOnlyUpdate(t *testing.T, mockTable *MockKeyOnlyTable) {
+	t.Helper()
+
+	entry := &MockKeyOnlyEntry{Key: &MockKey{Id: "testkeyonly"}}
+	_, err := mockTable.Add(entry)
+	testutils.AssertOk(t, err)
+
+	err = mockTable.Update(entry)
+	testutils.AssertOk(t, err)
+
+	missing := &MockKeyOnlyEntry{Key: &MockKey{Id: "testkeyonlymissing"}}
+	err = mockTable.Update(entry, missing)
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+
+	found, err := mockTable.Get(missing.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(found))
+}
+
+func TestHashTableDeleteMissing(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(1, "testdeletemissing")
+
+	err := mockTable.Delete(entries[0].Key)
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+}
+
+func TestHashTableUpsert(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(2, "testupsert")
+
+	_, err := mockTable.Add(entries[0])
+	testutils.AssertOk(t, err)
+
+	entries[0].Data.Data = "updated"
+	upserted, err := mockTable.Upsert(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(upserted))
+
+	assertHashTableData(t, mockTable, entries[0].Key, "updated")
+	assertHashTableData(t, mockTable, entries[1].Key, entries[1].Data.Data)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestHashTableAddIfAbsent(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(2, "testaddifabsent")
+
+	_, err := mockTable.Add(entries[0])
+	testutils.AssertOk(t, err)
+
+	existingData := entries[0].Data.Data
+	entries[0].Data.Data = "updated"
+	added, err := mockTable.AddIfAbsent(entries...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(added))
+	if len(added) == 1 {
+		testutils.AssertEquals(t, entries[1].Key.Id, added[0].Key.Id)
+	}
+
+	// the existing entry is left unchanged
+	assertHashTableData(t, mockTable, entries[0].Key, existingData)
+	assertHashTableData(t, mockTable, entries[1].Key, entries[1].Data.Data)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+// TestHashTableConcurrentUpsert upserts and adds if absent the same new
+// entries from several goroutines, and checks none fail
+func TestHashTableConcurrentUpsert(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	const numWriters = 8
+	entries := GenerateEntries(2, "testconcurrentupsert")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*numWriters)
+	for i := 0; i < numWriters; i += 1 {
+		// each writer has its own copies, since writes set values on entries
+		writerEntries := GenerateEntries(2, "testconcurrentupsert")
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := mockTable.Upsert(writerEntries[0])
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := mockTable.AddIfAbsent(writerEntries[1])
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		testutils.AssertOk(t, err)
+	}
+
+	assertHashTableData(t, mockTable, entries[0].Key, entries[0].Data.Data)
+	assertHashTableData(t, mockTable, entries[1].Key, entries[1].Data.Data)
+
+	err := mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestHashTableUpdateIf(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(2, "testupdateif")
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	isZero := func(existing *MockEntry) bool {
+		return existing.Data.Data == "0"
+	}
+
+	// one entry does not match, so neither is updated
+	entries[0].Data.Data = "updated"
+	entries[1].Data.Data = "updated"
+	err = mockTable.UpdateIf(isZero, entries...)
+	testutils.AssertErrorEquals(t, datastore.ConditionFailedError, err)
+	assertHashTableData(t, mockTable, entries[0].Key, "0")
+	assertHashTableData(t, mockTable, entries[1].Key, "1")
+
+	err = mockTable.UpdateIf(isZero, entries[0])
+	testutils.AssertOk(t, err)
+	assertHashTableData(t, mockTable, entries[0].Key, "updated")
+
+	err = mockTable.UpdateIf(isZero, GenerateEntries(1, "testupdateifmissing")...)
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestHashTableScanCancelled(t *testing.T, mockTable *MockTable) {
+	_, err := mockTable.Add(GenerateEntries(scanCancelledEntries, "testscancancelled")...)
+	testutils.AssertOk(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dataChan, errorChan := mockTable.ScanContext(ctx, 1)
+	assertScanCancelled(t, dataChan, errorChan, cancel, scanCancelledEntries)
+}
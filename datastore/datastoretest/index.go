@@ -0,0 +1,219 @@
+package datastoretest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// HELPERS
+
+func assertIndexedCodes[E any, PE mutator.Mutatable[E]](t *testing.T, getByIndex func(string, PE) ([]PE, error), indexName string, values PE, expected []string) {
+	t.Helper()
+
+	found, err := getByIndex(indexName, values)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(expected), len(found))
+	if len(expected) != len(found) {
+		return
+	}
+
+	for i, entry := range found {
+		testutils.AssertEquals(t, expected[i], entry.Mutator().GetField(CodeKey).(fields.String))
+	}
+}
+
+// MOCKS
+
+const (
+	GroupKey       = "Group"
+	CodeKey        = "Code"
+	GroupIndexName = "GroupIndex"
+	CodeIndexName  = "CodeIndex"
+)
+
+type MockIndexedData struct {
+	Group fields.String
+	Code  fields.String
+}
+
+func (d *MockIndexedData) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(GroupKey, &d.Group),
+		mutator.WithAddress(CodeKey, &d.Code),
+	)
+}
+
+var MockIndexedDataSettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithNumBytes(GroupKey, 63),
+		fields.WithNumBytes(CodeKey, 63),
+	),
+	FieldOrder: fields.OrderedFieldKeys{GroupKey, CodeKey},
+}
+
+type MockIndexedEntry = fields.KeyedEntry[MockKey, *MockKey, MockIndexedData, *MockIndexedData]
+
+type MockIndexedTable = datastore.HashTable[MockKey, *MockKey, MockIndexedEntry, *MockIndexedEntry]
+
+func NewMockIndexedTable(options ...func(*datastore.TableSettings)) *MockIndexedTable {
+	return &MockIndexedTable{
+		Settings: datastore.NewTableSettings(append([]func(*datastore.TableSettings){
+			datastore.WithTableName("TestIndexed"),
+			datastore.WithDataSettings(MockIndexedDataSettings),
+			datastore.WithKeySettings(MockKeySettings),
+			datastore.WithIndex(GroupIndexName, GroupKey),
+			datastore.WithUniqueIndex(CodeIndexName, CodeKey),
+		}, options...)...),
+	}
+}
+
+type MockIndexedSortEntry = fields.KeyedEntry[MockSortKey, *MockSortKey, MockIndexedData, *MockIndexedData]
+
+type MockIndexedSortTable = datastore.SortTable[MockSortKey, *MockSortKey, MockIndexedSortEntry, *MockIndexedSortEntry, MockSortComparator, *MockSortComparator]
+
+func NewMockIndexedSortTable() *MockIndexedSortTable {
+	return &MockIndexedSortTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestIndexedSort"),
+			datastore.WithDataSettings(MockIndexedDataSettings),
+			datastore.WithKeySettings(MockSortKeySettings),
+			datastore.WithSortFieldNames(MockSortFieldNames),
+			datastore.WithIndex(GroupIndexName, GroupKey),
+			datastore.WithUniqueIndex(CodeIndexName, CodeKey),
+		),
+	}
+}
+
+// TESTS
+
+// TestHashTableIndex checks the indexes follow every change to the table,
+// and that a unique index rejects duplicate values
+func TestHashTableIndex(t *testing.T, mockTable *MockIndexedTable) {
+	t.Helper()
+
+	groups := []string{"a", "a", "b"}
+	entries := make([]*MockIndexedEntry, len(groups))
+	for i, group := range groups {
+		entries[i] = &MockIndexedEntry{
+			Key:  &MockKey{Id: "testindex" + strconv.Itoa(i)},
+			Data: &MockIndexedData{Group: group, Code: "c" + strconv.Itoa(i)},
+		}
+	}
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	groupA := &MockIndexedEntry{Data: &MockIndexedData{Group: "a"}}
+	groupB := &MockIndexedEntry{Data: &MockIndexedData{Group: "b"}}
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{"c0", "c1"})
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupB, []string{"c2"})
+
+	entries[0].Data.Group = "b"
+	err = mockTable.Update(entries[0])
+	testutils.AssertOk(t, err)
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{"c1"})
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupB, []string{"c0", "c2"})
+
+	err = mockTable.Delete(entries[2].Key)
+	testutils.AssertOk(t, err)
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupB, []string{"c0"})
+
+	// a unique value may only be used by one entry
+	_, err = mockTable.Add(&MockIndexedEntry{
+		Key:  &MockKey{Id: "testindexduplicate"},
+		Data: &MockIndexedData{Group: "a", Code: "c1"},
+	})
+	testutils.AssertErrorEquals(t, datastore.KeyExistsError, err)
+
+	// only an existing key is skipped by AddIfAbsent, not a duplicate value
+	_, err = mockTable.AddIfAbsent(&MockIndexedEntry{
+		Key:  &MockKey{Id: "testindexduplicate"},
+		Data: &MockIndexedData{Group: "a", Code: "c1"},
+	})
+	testutils.AssertErrorEquals(t, datastore.KeyExistsError, err)
+
+	added, err := mockTable.AddIfAbsent(&MockIndexedEntry{
+		Key:  entries[1].Key,
+		Data: &MockIndexedData{Group: "b", Code: "c1"},
+	})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(added))
+
+	entries[0].Data.Code = "c1"
+	err = mockTable.Update(entries[0])
+	testutils.AssertErrorEquals(t, datastore.KeyExistsError, err)
+
+	codeC0 := &MockIndexedEntry{Data: &MockIndexedData{Code: "c0"}}
+	assertIndexedCodes(t, mockTable.GetByIndex, CodeIndexName, codeC0, []string{"c0"})
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{"c1"})
+
+	_, err = mockTable.GetByIndex("MissingIndex", groupA)
+	testutils.AssertErrorEquals(t, datastore.IndexDoesNotExistError, err)
+
+	err = mockTable.Delete(entries[0].Key, entries[1].Key)
+	testutils.AssertOk(t, err)
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{})
+}
+
+// TestSortTableIndex checks the indexes follow changes made with sort
+// comparators, and entries are found in the same order as the table
+func TestSortTableIndex(t *testing.T, mockTable *MockIndexedSortTable) {
+	t.Helper()
+
+	entries := make([]*MockIndexedSortEntry, 4)
+	for i := range entries {
+		entries[i] = &MockIndexedSortEntry{
+			Key:  &MockSortKey{Id: "testindex", Sort: i},
+			Data: &MockIndexedData{Group: "a", Code: "c" + strconv.Itoa(i)},
+		}
+	}
+
+	// add out of order to check that the results are sorted
+	_, err := mockTable.Add(entries[2], entries[0], entries[3], entries[1])
+	testutils.AssertOk(t, err)
+
+	groupA := &MockIndexedSortEntry{Data: &MockIndexedData{Group: "a"}}
+	groupB := &MockIndexedSortEntry{Data: &MockIndexedData{Group: "b"}}
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{"c0", "c1", "c2", "c3"})
+
+	// every matched entry would get the same code, so none are updated
+	err = mockTable.UpdateWithSortComparator(
+		&MockIndexedSortEntry{
+			Key:  &MockSortKey{Id: "testindex"},
+			Data: &MockIndexedData{Group: "b", Code: "same"},
+		},
+		&MockSortComparator{Sort: compare.Gte(2)},
+	)
+	testutils.AssertErrorEquals(t, datastore.KeyExistsError, err)
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupB, []string{})
+
+	err = mockTable.UpdateWithSortComparator(
+		&MockIndexedSortEntry{
+			Key:  &MockSortKey{Id: "testindex"},
+			Data: &MockIndexedData{Group: "b", Code: "moved"},
+		},
+		&MockSortComparator{Sort: compare.Eq(3)},
+	)
+	testutils.AssertOk(t, err)
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{"c0", "c1", "c2"})
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupB, []string{"moved"})
+
+	err = mockTable.DeleteWithSortComparator(
+		&MockSortKey{Id: "testindex"},
+		&MockSortComparator{Sort: compare.Lt(2)},
+	)
+	testutils.AssertOk(t, err)
+	assertIndexedCodes(t, mockTable.GetByIndex, GroupIndexName, groupA, []string{"c2"})
+
+	codeC0 := &MockIndexedSortEntry{Data: &MockIndexedData{Code: "c0"}}
+	assertIndexedCodes(t, mockTable.GetByIndex, CodeIndexName, codeC0, []string{})
+
+	err = mockTable.Delete(entries[2].Key, entries[3].Key)
+	testutils.AssertOk(t, err)
+}
@@ -0,0 +1,240 @@
+package datastoretest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// MOCKS
+
+const (
+	LegacyKey = "Legacy"
+	CountKey  = "Count"
+)
+
+// MockMigrationData is the data of a table before it is migrated to
+// MockMigratedData, which widens Data, adds Count and drops Legacy
+type MockMigrationData struct {
+	Data   fields.String
+	Legacy fields.String
+}
+
+func (d *MockMigrationData) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(DataKey, &d.Data),
+		mutator.WithAddress(LegacyKey, &d.Legacy),
+	)
+}
+
+type MockMigratedData struct {
+	Data  fields.String
+	Count fields.Int
+}
+
+func (d *MockMigratedData) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(DataKey, &d.Data),
+		mutator.WithAddress(CountKey, &d.Count),
+	)
+}
+
+type MockMigrationEntry = fields.KeyedEntry[MockKey, *MockKey, MockMigrationData, *MockMigrationData]
+
+type MockMigrationTable = datastore.HashTable[MockKey, *MockKey, MockMigrationEntry, *MockMigrationEntry]
+
+type MockMigratedEntry = fields.KeyedEntry[MockKey, *MockKey, MockMigratedData, *MockMigratedData]
+
+type MockMigratedTable = datastore.HashTable[MockKey, *MockKey, MockMigratedEntry, *MockMigratedEntry]
+
+func NewMockMigrationTable() *MockMigrationTable {
+	return &MockMigrationTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestMigration"),
+			datastore.WithKeySettings(MockKeySettings),
+			datastore.WithDataSettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(
+					fields.WithNumBytes(DataKey, 31),
+					fields.WithNumBytes(LegacyKey, 31),
+				),
+				FieldOrder: fields.OrderedFieldKeys{DataKey, LegacyKey},
+			}),
+		),
+	}
+}
+
+// NewMockMigratedTable has the same name as NewMockMigrationTable, so it is
+// registered over it to change its settings
+func NewMockMigratedTable() *MockMigratedTable {
+	return &MockMigratedTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestMigration"),
+			datastore.WithKeySettings(MockKeySettings),
+			datastore.WithDataSettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(
+					fields.WithNumBytes(DataKey, 255),
+				),
+				FieldOrder: fields.OrderedFieldKeys{DataKey, CountKey},
+			}),
+		),
+	}
+}
+
+// NewMockReorderedTable has the fields of NewMockIndexedTable in the opposite
+// order, so registering it over one named TestReorder only moves its fields
+func NewMockReorderedTable() *MockIndexedTable {
+	return &MockIndexedTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestReorder"),
+			datastore.WithKeySettings(MockKeySettings),
+			datastore.WithDataSettings(&fields.RowSettings{
+				FieldSettings: MockIndexedDataSettings.FieldSettings,
+				FieldOrder:    fields.OrderedFieldKeys{CodeKey, GroupKey},
+			}),
+			datastore.WithIndex(GroupIndexName, GroupKey),
+			datastore.WithUniqueIndex(CodeIndexName, CodeKey),
+		),
+	}
+}
+
+// HELPERS
+
+func hasOperation(plan *datastore.MigrationPlan, operationType datastore.MigrationOperationType, fieldName string) bool {
+	for _, operation := range plan.Operations {
+		if operation.Type == operationType && operation.FieldName == fieldName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TESTS
+
+// TestHashTableMigration registers the migrated settings over a table with
+// entries and migrates it
+func TestHashTableMigration(t *testing.T, mockTable *MockMigrationTable, backend any, registerMigrated func(*MockMigratedTable) error) {
+	t.Helper()
+
+	ctx := context.Background()
+	migrator, ok := backend.(datastore.Migrator)
+	if !ok {
+		_, err := datastore.Migrate(ctx, backend)
+		testutils.AssertErrorEquals(t, datastore.MigrationNotSupportedError, err)
+		return
+	}
+
+	entries := []*MockMigrationEntry{
+		{Key: &MockKey{Id: "testmigration0"}, Data: &MockMigrationData{Data: "0", Legacy: "legacy0"}},
+		{Key: &MockKey{Id: "testmigration1"}, Data: &MockMigrationData{Data: "1", Legacy: "legacy1"}},
+	}
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	plan, err := migrator.PlanMigration(ctx)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(plan.Operations))
+
+	historyBefore, err := migrator.MigrationHistory(ctx)
+	testutils.AssertOk(t, err)
+
+	migratedTable := NewMockMigratedTable()
+	testutils.AssertOk(t, registerMigrated(migratedTable))
+
+	plan, err = datastore.Migrate(ctx, migrator, datastore.WithDryRun())
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, "TestMigration", plan.TableName)
+	testutils.AssertTrue(t, hasOperation(plan, datastore.ADD_FIELD, CountKey))
+	testutils.AssertTrue(t, hasOperation(plan, datastore.DROP_FIELD, LegacyKey))
+	testutils.AssertTrue(t, !hasOperation(plan, datastore.ADD_FIELD, IdKey))
+
+	dryRunPlan, err := migrator.PlanMigration(ctx)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(plan.Operations), len(dryRunPlan.Operations))
+
+	plan, err = datastore.Migrate(ctx, migrator)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(dryRunPlan.Operations), len(plan.Operations))
+
+	remaining, err := migrator.PlanMigration(ctx)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(remaining.Operations))
+
+	found, err := migratedTable.Get(entries[0].Key, entries[1].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(found))
+	for i := range found {
+		testutils.AssertEquals(t, entries[i].Data.Data, found[i].Data.Data)
+		testutils.AssertEquals(t, 0, found[i].Data.Count)
+	}
+
+	wide := &MockMigratedEntry{
+		Key:  &MockKey{Id: "testmigrationwide"},
+		Data: &MockMigratedData{Data: strings.Repeat("w", 200), Count: 3},
+	}
+	_, err = migratedTable.Add(wide)
+	testutils.AssertOk(t, err)
+
+	found, err = migratedTable.Get(wide.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(found))
+	if len(found) == 1 {
+		testutils.AssertEquals(t, *wide.Data, *found[0].Data)
+	}
+
+	history, err := migrator.MigrationHistory(ctx)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, len(historyBefore)+1, len(history))
+	if len(history) == len(historyBefore)+1 {
+		record := history[len(history)-1]
+		testutils.AssertEquals(t, "TestMigration", record.TableName)
+		testutils.AssertEquals(t, len(plan.Operations), len(record.Statements))
+		for i, operation := range plan.Operations {
+			testutils.AssertTrue(t, record.Statements[i] == operation.Statement)
+		}
+	}
+}
+
+// TestHashTableReorderMigration registers the fields of a table with entries
+// in a different order and migrates it
+func TestHashTableReorderMigration(t *testing.T, mockTable *MockIndexedTable, backend any, registerReordered func(*MockIndexedTable) error) {
+	t.Helper()
+
+	ctx := context.Background()
+	migrator, ok := backend.(datastore.Migrator)
+	if !ok {
+		return
+	}
+
+	entry := &MockIndexedEntry{
+		Key:  &MockKey{Id: "testreorder0"},
+		Data: &MockIndexedData{Group: "group0", Code: "code0"},
+	}
+	_, err := mockTable.Add(entry)
+	testutils.AssertOk(t, err)
+
+	reorderedTable := NewMockReorderedTable()
+	testutils.AssertOk(t, registerReordered(reorderedTable))
+
+	plan, err := datastore.Migrate(ctx, migrator)
+	testutils.AssertOk(t, err)
+	for _, operation := range plan.Operations {
+		testutils.AssertEquals(t, datastore.MOVE_FIELD, operation.Type)
+	}
+
+	remaining, err := migrator.PlanMigration(ctx)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(remaining.Operations))
+
+	found, err := reorderedTable.Get(entry.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(found))
+	if len(found) == 1 {
+		testutils.AssertEquals(t, *entry.Data, *found[0].Data)
+	}
+}
@@ -0,0 +1,142 @@
+package datastoretest
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// HELPERS
+
+// collectPages reads pages of limit entries starting from cursor until there
+// are no more
+func collectPages[E any](t *testing.T, scanPage func(string, int) ([]E, string, error), cursor string, limit int) []E {
+	t.Helper()
+
+	collected := []E{}
+	for {
+		page, nextCursor, err := scanPage(cursor, limit)
+		testutils.AssertOk(t, err)
+		testutils.AssertTrue(t, len(page) <= limit)
+		collected = append(collected, page...)
+
+		if nextCursor == "" || err != nil {
+			return collected
+		}
+		cursor = nextCursor
+	}
+}
+
+// TESTS
+
+// TestAppendTableScanPage checks entries added between pages are read once
+// they are reached
+func TestAppendTableScanPage(t *testing.T, mockTable *MockAppendTable) {
+	t.Helper()
+
+	_, err := mockTable.Add(GenerateNonKeyedEntries(3, "testpage")...)
+	testutils.AssertOk(t, err)
+
+	page, cursor, err := mockTable.ScanPage("", 2)
+	if err == datastore.PageNotSupportedError {
+		return
+	}
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(page))
+	testutils.AssertEquals(t, "testpage0", page[0].Data.Data)
+	testutils.AssertEquals(t, "testpage1", page[1].Data.Data)
+
+	_, err = mockTable.Add(&MockNonKeyedEntry{Data: &MockData{Data: "testpage3"}})
+	testutils.AssertOk(t, err)
+
+	rest := collectPages(t, mockTable.ScanPage, cursor, 2)
+	testutils.AssertEquals(t, 2, len(rest))
+	if len(rest) == 2 {
+		testutils.AssertEquals(t, "testpage2", rest[0].Data.Data)
+		testutils.AssertEquals(t, "testpage3", rest[1].Data.Data)
+	}
+}
+
+// TestHashTableScanPage checks every entry is read exactly once, even when
+// entries are added between pages
+func TestHashTableScanPage(t *testing.T, mockTable *MockTable) {
+	t.Helper()
+
+	entries := GenerateEntries(5, "testpage")
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	page, cursor, err := mockTable.ScanPage("", 2)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(page))
+	testutils.AssertTrue(t, cursor != "")
+
+	added := GenerateEntries(2, "testpageadded")
+	_, err = mockTable.Add(added...)
+	testutils.AssertOk(t, err)
+
+	scannedIds := map[string]int{}
+	for _, entry := range append(page, collectPages(t, mockTable.ScanPage, cursor, 2)...) {
+		scannedIds[entry.Key.Id] += 1
+	}
+
+	for _, entry := range entries {
+		testutils.AssertEquals(t, 1, scannedIds[entry.Key.Id])
+	}
+	for _, count := range scannedIds {
+		testutils.AssertEquals(t, 1, count)
+	}
+
+	_, _, err = mockTable.ScanPage("invalid", 2)
+	testutils.AssertErrorEquals(t, datastore.InvalidCursorError, err)
+
+	_, _, err = mockTable.ScanPage("", 0)
+	testutils.AssertErrorEquals(t, queries.PageLimitError, err)
+
+	err = mockTable.Delete(fields.KeysOfEntries(append(entries, added...))...)
+	testutils.AssertOk(t, err)
+}
+
+// TestSortTableScanPage checks pages follow the table order, and that entries
+// added before the cursor are not read
+func TestSortTableScanPage(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testpage")
+	_, err := mockTable.Add(entries[3], entries[0], entries[4], entries[2], entries[1])
+	testutils.AssertOk(t, err)
+
+	page, cursor, err := mockTable.ScanPage("", 2)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{0, 1}, page)
+
+	added := []*MockSortEntry{
+		{Key: &MockSortKey{Id: "testpage", Sort: -1}, Data: &MockData{Data: "-1"}},
+		{Key: &MockSortKey{Id: "testpage", Sort: 5}, Data: &MockData{Data: "5"}},
+	}
+	_, err = mockTable.Add(added...)
+	testutils.AssertOk(t, err)
+
+	assertSortValues(t, []int{2, 3, 4, 5}, collectPages(t, mockTable.ScanPage, cursor, 2))
+
+	key := &MockSortKey{Id: "testpage"}
+	getPage := func(cursor string, limit int) ([]*MockSortEntry, string, error) {
+		return mockTable.GetPageWithSortComparator(key, &MockSortComparator{Sort: compare.Btw(0, 4)}, cursor, limit)
+	}
+
+	page, cursor, err = getPage("", 3)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{0, 1, 2}, page)
+
+	page, cursor, err = getPage(cursor, 3)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{3, 4}, page)
+	testutils.AssertEquals(t, "", cursor)
+
+	err = mockTable.Delete(fields.KeysOfEntries(append(entries, added...))...)
+	testutils.AssertOk(t, err)
+}
@@ -0,0 +1,348 @@
+package datastoretest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// MOCKS
+
+// VisibilityTimeout is used by the visibility tests, it is kept short so
+// they can wait for messages to be redelivered
+const VisibilityTimeout = 50 * time.Millisecond
+
+// MaxDeliveryAttempts is used by the dead letter tests
+const MaxDeliveryAttempts = 2
+
+const DeadLetterQueueName = "TestDeadLetterQueue"
+
+// NewMockDeadLetterQueue is registered alongside a queue created with
+// WithDeadLetterQueue(DeadLetterQueueName, MaxDeliveryAttempts)
+func NewMockDeadLetterQueue() *MockQueue {
+	return NewMockQueue(datastore.WithTableName(DeadLetterQueueName))
+}
+
+type MockMessage = MockNonKeyedEntry
+
+type MockQueue = datastore.Queue[MockMessage, *MockMessage]
+
+func NewMockQueue(options ...func(*datastore.TableSettings)) *MockQueue {
+	return &MockQueue{
+		Settings: datastore.NewTableSettings(append([]func(*datastore.TableSettings){
+			datastore.WithTableName("TestQueue"),
+			datastore.WithDataSettings(MockDataSettings),
+		}, options...)...),
+	}
+}
+
+// HELPERS
+
+// MessageReceiver is the part of a Queue or Subscription used to recieve
+// messages, so the same tests can run against both
+type MessageReceiver interface {
+	HasMessage() (bool, error)
+	RecieveMessage() (string, *MockMessage, error)
+	RecieveMessageWithDeliveryCount() (string, *MockMessage, int, error)
+	RecieveMessageWaitContext(ctx context.Context, maxWait time.Duration) (string, *MockMessage, error)
+	RecieveMessages(n int) ([]string, []*MockMessage, error)
+	AckSuccess(messageId ...string) error
+	AckFailure(messageId ...string) error
+	ExtendVisibility(messageId string, duration time.Duration) error
+	DeliveryCount(messageId string) (int, error)
+}
+
+func AssertRecievesMessage(t *testing.T, receiver MessageReceiver, expectedData string) string {
+	t.Helper()
+
+	hasMessage, err := receiver.HasMessage()
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, hasMessage)
+
+	id, message, err := receiver.RecieveMessage()
+	testutils.AssertOk(t, err)
+	if err == nil {
+		testutils.AssertEquals(t, expectedData, message.Data.Data)
+	}
+
+	return id
+}
+
+func AssertNoMessage(t *testing.T, receiver MessageReceiver) {
+	t.Helper()
+
+	hasMessage, err := receiver.HasMessage()
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, !hasMessage)
+}
+
+// TESTS
+
+func TestQueueEmpty(t *testing.T, mockQueue *MockQueue) {
+	t.Helper()
+
+	count, err := mockQueue.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, count)
+
+	AssertNoMessage(t, mockQueue)
+
+	_, _, err = mockQueue.RecieveMessage()
+	testutils.AssertErrorEquals(t, datastore.QueueEmptyError, err)
+}
+
+func TestQueueOrder(t *testing.T, mockQueue *MockQueue) {
+	t.Helper()
+
+	messages := GenerateNonKeyedEntries(3, "testorder")
+
+	err := mockQueue.SendMessage(messages...)
+	testutils.AssertOk(t, err)
+
+	count, err := mockQueue.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, count)
+
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = AssertRecievesMessage(t, mockQueue, message.Data.Data)
+	}
+
+	AssertNoMessage(t, mockQueue)
+	testutils.AssertOk(t, mockQueue.AckSuccess(ids...))
+}
+
+func TestMessageAck(t *testing.T, receiver MessageReceiver, send func(messages ...*MockMessage) error) {
+	t.Helper()
+
+	messages := GenerateNonKeyedEntries(2, "testack")
+
+	err := send(messages...)
+	testutils.AssertOk(t, err)
+
+	testutils.Case(t, "redelivers message after ack failure", func(t *testing.T) {
+		id := AssertRecievesMessage(t, receiver, messages[0].Data.Data)
+		testutils.AssertOk(t, receiver.AckFailure(id))
+
+		redeliveredId := AssertRecievesMessage(t, receiver, messages[0].Data.Data)
+		testutils.AssertEquals(t, id, redeliveredId)
+		testutils.AssertOk(t, receiver.AckSuccess(redeliveredId))
+	})
+
+	testutils.Case(t, "does not redeliver message after ack success", func(t *testing.T) {
+		id := AssertRecievesMessage(t, receiver, messages[1].Data.Data)
+		testutils.AssertOk(t, receiver.AckSuccess(id))
+		AssertNoMessage(t, receiver)
+	})
+
+	testutils.Case(t, "acks repeated ids once", func(t *testing.T) {
+		err := send(GenerateNonKeyedEntries(1, "testackrepeated")...)
+		testutils.AssertOk(t, err)
+
+		id := AssertRecievesMessage(t, receiver, "testackrepeated0")
+		testutils.AssertOk(t, receiver.AckFailure(id, id))
+
+		redeliveredId := AssertRecievesMessage(t, receiver, "testackrepeated0")
+		testutils.AssertEquals(t, id, redeliveredId)
+		testutils.AssertOk(t, receiver.AckSuccess(redeliveredId, redeliveredId))
+		AssertNoMessage(t, receiver)
+	})
+
+	testutils.Case(t, "returns error acking message that is not in flight", func(t *testing.T) {
+		testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, receiver.AckSuccess("0"))
+		testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, receiver.AckFailure("0"))
+	})
+}
+
+func TestMessageWait(t *testing.T, receiver MessageReceiver, send func(messages ...*MockMessage) error) {
+	t.Helper()
+
+	testutils.Case(t, "returns empty queue error after waiting", func(t *testing.T) {
+		start := time.Now()
+		_, _, err := receiver.RecieveMessageWaitContext(context.Background(), 50*time.Millisecond)
+		testutils.AssertErrorEquals(t, datastore.QueueEmptyError, err)
+		testutils.AssertTrue(t, time.Since(start) >= 50*time.Millisecond)
+	})
+
+	testutils.Case(t, "returns message sent while waiting", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testwait")[0]
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			testutils.AssertOk(t, send(message))
+		}()
+
+		id, recieved, err := receiver.RecieveMessageWaitContext(context.Background(), 5*time.Second)
+		testutils.AssertOk(t, err)
+		if err == nil {
+			testutils.AssertEquals(t, message.Data.Data, recieved.Data.Data)
+			testutils.AssertOk(t, receiver.AckSuccess(id))
+		}
+	})
+
+	testutils.Case(t, "returns message already available without waiting", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testavailable")[0]
+		testutils.AssertOk(t, send(message))
+
+		id, recieved, err := receiver.RecieveMessageWaitContext(context.Background(), 0)
+		testutils.AssertOk(t, err)
+		if err == nil {
+			testutils.AssertEquals(t, message.Data.Data, recieved.Data.Data)
+			testutils.AssertOk(t, receiver.AckSuccess(id))
+		}
+	})
+
+	testutils.Case(t, "returns context error when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, _, err := receiver.RecieveMessageWaitContext(ctx, 5*time.Second)
+		testutils.AssertErrorEquals(t, context.DeadlineExceeded, err)
+	})
+}
+
+func TestMessageBatch(t *testing.T, receiver MessageReceiver, send func(messages ...*MockMessage) error) {
+	t.Helper()
+
+	messages := GenerateNonKeyedEntries(3, "testbatch")
+	testutils.AssertOk(t, send(messages...))
+
+	ids, recieved, err := receiver.RecieveMessages(2)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(ids))
+	testutils.AssertEquals(t, 2, len(recieved))
+	for i := range recieved {
+		testutils.AssertEquals(t, messages[i].Data.Data, recieved[i].Data.Data)
+	}
+	testutils.AssertOk(t, receiver.AckSuccess(ids...))
+
+	// only the remaining message is returned when fewer than n are available
+	ids, recieved, err = receiver.RecieveMessages(2)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(ids))
+	testutils.AssertEquals(t, 1, len(recieved))
+	testutils.AssertEquals(t, messages[2].Data.Data, recieved[0].Data.Data)
+	testutils.AssertOk(t, receiver.AckSuccess(ids...))
+
+	ids, recieved, err = receiver.RecieveMessages(2)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(ids))
+	testutils.AssertEquals(t, 0, len(recieved))
+}
+
+// TestMessageVisibility expects receiver to have a visibility timeout of
+// VisibilityTimeout
+func TestMessageVisibility(t *testing.T, receiver MessageReceiver, send func(messages ...*MockMessage) error) {
+	t.Helper()
+
+	testutils.Case(t, "redelivers message after visibility timeout", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testvisibility")[0]
+		testutils.AssertOk(t, send(message))
+
+		AssertRecievesMessage(t, receiver, message.Data.Data)
+		AssertNoMessage(t, receiver)
+
+		time.Sleep(2 * VisibilityTimeout)
+		id := AssertRecievesMessage(t, receiver, message.Data.Data)
+		testutils.AssertOk(t, receiver.AckSuccess(id))
+		AssertNoMessage(t, receiver)
+	})
+
+	testutils.Case(t, "waits for message to become visible", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testvisibilitywait")[0]
+		testutils.AssertOk(t, send(message))
+		AssertRecievesMessage(t, receiver, message.Data.Data)
+
+		id, recieved, err := receiver.RecieveMessageWaitContext(context.Background(), 5*time.Second)
+		testutils.AssertOk(t, err)
+		if err == nil {
+			testutils.AssertEquals(t, message.Data.Data, recieved.Data.Data)
+			testutils.AssertOk(t, receiver.AckSuccess(id))
+		}
+	})
+
+	testutils.Case(t, "extending visibility delays redelivery", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testextend")[0]
+		testutils.AssertOk(t, send(message))
+
+		id := AssertRecievesMessage(t, receiver, message.Data.Data)
+		testutils.AssertOk(t, receiver.ExtendVisibility(id, 5*time.Second))
+
+		time.Sleep(2 * VisibilityTimeout)
+		AssertNoMessage(t, receiver)
+		testutils.AssertOk(t, receiver.AckSuccess(id))
+	})
+
+	testutils.Case(t, "cannot extend a message which is not in flight", func(t *testing.T) {
+		testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, receiver.ExtendVisibility("0", time.Second))
+	})
+}
+
+// TestMessageDeadLetter expects receiver to move messages to deadLetterQueue
+// after MaxDeliveryAttempts
+func TestMessageDeadLetter(t *testing.T, receiver MessageReceiver, send func(messages ...*MockMessage) error, deadLetterQueue *MockQueue) {
+	t.Helper()
+
+	assertDeadLettered := func(t *testing.T, expectedData string) {
+		t.Helper()
+
+		_, _, err := receiver.RecieveMessage()
+		testutils.AssertErrorEquals(t, datastore.QueueEmptyError, err)
+		AssertNoMessage(t, receiver)
+
+		id, message, deliveryCount, err := deadLetterQueue.RecieveMessageWithDeliveryCount()
+		testutils.AssertOk(t, err)
+		if err == nil {
+			testutils.AssertEquals(t, expectedData, message.Data.Data)
+			testutils.AssertEquals(t, 1, deliveryCount)
+			testutils.AssertOk(t, deadLetterQueue.AckSuccess(id))
+		}
+	}
+
+	testutils.Case(t, "counts deliveries", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testdeliverycount")[0]
+		testutils.AssertOk(t, send(message))
+
+		id, recieved, deliveryCount, err := receiver.RecieveMessageWithDeliveryCount()
+		testutils.AssertOk(t, err)
+		if err == nil {
+			testutils.AssertEquals(t, message.Data.Data, recieved.Data.Data)
+			testutils.AssertEquals(t, 1, deliveryCount)
+			testutils.AssertOk(t, receiver.AckFailure(id))
+		}
+
+		id, _, deliveryCount, err = receiver.RecieveMessageWithDeliveryCount()
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 2, deliveryCount)
+
+		testutils.AssertOk(t, receiver.AckSuccess(id))
+		_, err = receiver.DeliveryCount(id)
+		testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+	})
+
+	testutils.Case(t, "moves message after failed attempts", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testdeadletter")[0]
+		testutils.AssertOk(t, send(message))
+
+		for i := 0; i < MaxDeliveryAttempts; i += 1 {
+			id := AssertRecievesMessage(t, receiver, message.Data.Data)
+			testutils.AssertOk(t, receiver.AckFailure(id))
+		}
+
+		assertDeadLettered(t, message.Data.Data)
+	})
+
+	testutils.Case(t, "moves message after visibility timeouts", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testdeadletterexpired")[0]
+		testutils.AssertOk(t, send(message))
+
+		for i := 0; i < MaxDeliveryAttempts; i += 1 {
+			AssertRecievesMessage(t, receiver, message.Data.Data)
+			time.Sleep(2 * VisibilityTimeout)
+		}
+
+		assertDeadLettered(t, message.Data.Data)
+	})
+}
@@ -0,0 +1,366 @@
+package datastoretest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// HELPERS
+
+// GenerateSortEntries creates entries sharing the same Id with Sort values
+// from 0 to numEntries - 1
+func GenerateSortEntries(numEntries int, id string) []*MockSortEntry {
+	entries := make([]*MockSortEntry, numEntries)
+
+	for i := 0; i < numEntries; i += 1 {
+		entries[i] = &MockSortEntry{
+			Key: &MockSortKey{
+				Id:   id,
+				Sort: i,
+			},
+			Data: &MockData{
+				Data: strconv.Itoa(i),
+			},
+		}
+	}
+
+	return entries
+}
+
+func assertSortValues(t *testing.T, expected []int, actual []*MockSortEntry) {
+	t.Helper()
+
+	testutils.AssertEquals(t, len(expected), len(actual))
+	if len(expected) != len(actual) {
+		return
+	}
+
+	for i := range expected {
+		testutils.AssertEquals(t, expected[i], actual[i].Key.Sort)
+	}
+}
+
+// MOCKS
+
+const SortKey = "Sort"
+
+type MockSortKey struct {
+	Id   fields.String
+	Sort fields.Int
+}
+
+func (k *MockSortKey) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(IdKey, &k.Id),
+		mutator.WithAddress(SortKey, &k.Sort),
+	)
+}
+
+var MockSortKeySettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithNumBytes(IdKey, 63),
+	),
+	FieldOrder: fields.OrderedFieldKeys{IdKey, SortKey},
+}
+
+type MockSortComparator struct {
+	Sort *compare.Comparator[fields.Int]
+}
+
+func (c *MockSortComparator) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(SortKey, &c.Sort),
+	)
+}
+
+var MockSortFieldNames = fields.SortFieldNames{SortKey}
+
+type MockSortEntry = fields.KeyedEntry[MockSortKey, *MockSortKey, MockData, *MockData]
+
+type MockSortTable = datastore.SortTable[MockSortKey, *MockSortKey, MockSortEntry, *MockSortEntry, MockSortComparator, *MockSortComparator]
+
+func NewMockSortTable() *MockSortTable {
+	return &MockSortTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestSort"),
+			datastore.WithDataSettings(MockDataSettings),
+			datastore.WithKeySettings(MockSortKeySettings),
+			datastore.WithSortFieldNames(MockSortFieldNames),
+		),
+	}
+}
+
+// TESTS
+
+func TestSortTableScan(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testscan")
+
+	// add out of order to check that the table sorts them
+	_, err := mockTable.Add(entries[3], entries[0], entries[4], entries[2], entries[1])
+	testutils.AssertOk(t, err)
+
+	dataChan, errorChan := mockTable.Scan(2)
+	scanned := CollectScan(t, dataChan, errorChan)
+	assertSortValues(t, []int{0, 1, 2, 3, 4}, scanned)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestSortTableGetWithSortComparator(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testgetsort")
+	otherEntries := GenerateSortEntries(5, "testgetsortother")
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	_, err = mockTable.Add(otherEntries...)
+	testutils.AssertOk(t, err)
+
+	tests := &testutils.Tests[*compare.Comparator[fields.Int], []int]{
+		Cases: []testutils.TestCase[*compare.Comparator[fields.Int], []int]{
+			{Name: "no comparator", Input: nil, Expected: []int{0, 1, 2, 3, 4}},
+			{Name: "eq", Input: compare.Eq(2), Expected: []int{2}},
+			{Name: "lt", Input: compare.Lt(2), Expected: []int{0, 1}},
+			{Name: "lte", Input: compare.Lte(2), Expected: []int{0, 1, 2}},
+			{Name: "gt", Input: compare.Gt(2), Expected: []int{3, 4}},
+			{Name: "gte", Input: compare.Gte(2), Expected: []int{2, 3, 4}},
+			{Name: "btw", Input: compare.Btw(1, 3), Expected: []int{1, 2, 3}},
+			{Name: "no matches", Input: compare.Gt(10), Expected: []int{}},
+			{Name: "lt lowest value", Input: compare.Lt(0), Expected: []int{}},
+			{Name: "gte lowest value", Input: compare.Gte(0), Expected: []int{0, 1, 2, 3, 4}},
+			{Name: "lte highest value", Input: compare.Lte(4), Expected: []int{0, 1, 2, 3, 4}},
+			{Name: "eq missing value", Input: compare.Eq(10), Expected: []int{}},
+			{Name: "btw single value", Input: compare.Btw(2, 2), Expected: []int{2}},
+			{Name: "btw reversed bounds", Input: compare.Btw(3, 1), Expected: []int{}},
+		},
+		Func: func(t *testing.T, input *compare.Comparator[fields.Int], expected []int) {
+			actual, err := mockTable.GetWithSortComparator(
+				&MockSortKey{Id: "testgetsort"},
+				&MockSortComparator{Sort: input},
+			)
+			testutils.AssertOk(t, err)
+			assertSortValues(t, expected, actual)
+		},
+	}
+	tests.Run(t)
+
+	testutils.Case(t, "missing hash key", func(t *testing.T) {
+		actual, err := mockTable.GetWithSortComparator(
+			&MockSortKey{Id: "testgetsortmissing"},
+			&MockSortComparator{},
+		)
+		testutils.AssertOk(t, err)
+		assertSortValues(t, []int{}, actual)
+	})
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+	err = mockTable.Delete(fields.KeysOfEntries(otherEntries)...)
+	testutils.AssertOk(t, err)
+}
+
+// TestSortTableGetWithSortOptions checks descending order and limits, which
+// only keep entries of the requested hash key
+func TestSortTableGetWithSortOptions(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testsortoptions")
+	otherEntries := GenerateSortEntries(5, "testsortoptionsother")
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+	_, err = mockTable.Add(otherEntries...)
+	testutils.AssertOk(t, err)
+
+	tests := &testutils.Tests[[]func(*queries.SortOptions), []int]{
+		Cases: []testutils.TestCase[[]func(*queries.SortOptions), []int]{
+			{Name: "no options", Input: nil, Expected: []int{1, 2, 3, 4}},
+			{Name: "ascending", Input: []func(*queries.SortOptions){queries.WithOrder(queries.ASCENDING)}, Expected: []int{1, 2, 3, 4}},
+			{Name: "descending", Input: []func(*queries.SortOptions){queries.WithDescending()}, Expected: []int{4, 3, 2, 1}},
+			{Name: "limit", Input: []func(*queries.SortOptions){queries.WithLimit(2)}, Expected: []int{1, 2}},
+			{Name: "descending limit", Input: []func(*queries.SortOptions){queries.WithDescending(), queries.WithLimit(3)}, Expected: []int{4, 3, 2}},
+			{Name: "limit over matches", Input: []func(*queries.SortOptions){queries.WithLimit(10)}, Expected: []int{1, 2, 3, 4}},
+		},
+		Func: func(t *testing.T, input []func(*queries.SortOptions), expected []int) {
+			actual, err := mockTable.GetWithSortComparator(
+				&MockSortKey{Id: "testsortoptions"},
+				&MockSortComparator{Sort: compare.Gte(1)},
+				input...,
+			)
+			testutils.AssertOk(t, err)
+			assertSortValues(t, expected, actual)
+		},
+	}
+	tests.Run(t)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+	err = mockTable.Delete(fields.KeysOfEntries(otherEntries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestSortTableUpdateWithSortComparator(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testupdatesort")
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	err = mockTable.UpdateWithSortComparator(
+		&MockSortEntry{
+			Key:  &MockSortKey{Id: "testupdatesort"},
+			Data: &MockData{Data: "updated"},
+		},
+		&MockSortComparator{Sort: compare.Gte(3)},
+	)
+	testutils.AssertOk(t, err)
+
+	actual, err := mockTable.GetWithSortComparator(
+		&MockSortKey{Id: "testupdatesort"},
+		&MockSortComparator{},
+	)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{0, 1, 2, 3, 4}, actual)
+
+	for _, entry := range actual {
+		if entry.Key.Sort >= 3 {
+			testutils.AssertEquals(t, "updated", entry.Data.Data)
+		} else {
+			testutils.AssertEquals(t, strconv.Itoa(entry.Key.Sort), entry.Data.Data)
+		}
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestSortTableDeleteWithSortComparator(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(5, "testdeletesort")
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	err = mockTable.DeleteWithSortComparator(
+		&MockSortKey{Id: "testdeletesort"},
+		&MockSortComparator{Sort: compare.Btw(1, 3)},
+	)
+	testutils.AssertOk(t, err)
+
+	actual, err := mockTable.GetWithSortComparator(
+		&MockSortKey{Id: "testdeletesort"},
+		&MockSortComparator{},
+	)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{0, 4}, actual)
+
+	err = mockTable.Delete(actual[0].Key, actual[1].Key)
+	testutils.AssertOk(t, err)
+}
+
+func TestSortTableCRUD(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(2, "testsortcrud")
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	_, err = mockTable.Add(entries[1])
+	testutils.AssertErrorEquals(t, datastore.KeyExistsError, err)
+
+	actual, err := mockTable.Get(entries[1].Key, entries[0].Key)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{1, 0}, actual)
+
+	entries[1].Data.Data = "updated"
+	err = mockTable.Update(entries[1])
+	testutils.AssertOk(t, err)
+
+	actual, err = mockTable.Get(entries[1].Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(actual))
+	testutils.AssertEquals(t, "updated", actual[0].Data.Data)
+
+	count, err := mockTable.Count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, count)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+
+	err = mockTable.Delete(entries[0].Key)
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+
+	err = mockTable.Update(entries[0])
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+}
+
+// TestSortTableConditionalWrites checks Upsert, AddIfAbsent and UpdateIf
+// keep the table sorted and only change the expected entries
+func TestSortTableConditionalWrites(t *testing.T, mockTable *MockSortTable) {
+	t.Helper()
+
+	entries := GenerateSortEntries(4, "testconditional")
+
+	_, err := mockTable.Add(entries[1], entries[3])
+	testutils.AssertOk(t, err)
+
+	added, err := mockTable.AddIfAbsent(entries[2], entries[3])
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{2}, added)
+
+	entries[3].Data.Data = "upserted"
+	_, err = mockTable.Upsert(entries[0], entries[3])
+	testutils.AssertOk(t, err)
+
+	entries[1].Data.Data = "updated"
+	err = mockTable.UpdateIf(func(existing *MockSortEntry) bool {
+		return existing.Data.Data == "1"
+	}, entries[1])
+	testutils.AssertOk(t, err)
+
+	err = mockTable.UpdateIf(func(existing *MockSortEntry) bool {
+		return false
+	}, entries[2])
+	testutils.AssertErrorEquals(t, datastore.ConditionFailedError, err)
+
+	actual, err := mockTable.GetWithSortComparator(
+		&MockSortKey{Id: "testconditional"},
+		&MockSortComparator{},
+	)
+	testutils.AssertOk(t, err)
+	assertSortValues(t, []int{0, 1, 2, 3}, actual)
+	if len(actual) == 4 {
+		testutils.AssertEquals(t, "0", actual[0].Data.Data)
+		testutils.AssertEquals(t, "updated", actual[1].Data.Data)
+		testutils.AssertEquals(t, "2", actual[2].Data.Data)
+		testutils.AssertEquals(t, "upserted", actual[3].Data.Data)
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+func TestSortTableScanCancelled(t *testing.T, mockTable *MockSortTable) {
+	_, err := mockTable.Add(GenerateSortEntries(scanCancelledEntries, "testscancancelled")...)
+	testutils.AssertOk(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dataChan, errorChan := mockTable.ScanContext(ctx, 1)
+	assertScanCancelled(t, dataChan, errorChan, cancel, scanCancelledEntries)
+}
@@ -0,0 +1,537 @@
+package datastoretest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// BackendFactory creates a connection and an unregistered backend for each
+// test in a suite
+type BackendFactory[C datastore.Connection, B datastore.TableBackend[C]] func(t *testing.T) (C, B)
+
+// CollectScan reads every entry from the channels returned by Scan, failing
+// the test on any error
+func CollectScan[E any](t *testing.T, dataChan chan E, errorChan chan error) []E {
+	t.Helper()
+
+	scanned := []E{}
+	for dataChan != nil || errorChan != nil {
+		select {
+		case entry, more := <-dataChan:
+			if !more {
+				dataChan = nil
+				continue
+			}
+
+			scanned = append(scanned, entry)
+		case err, more := <-errorChan:
+			if !more {
+				errorChan = nil
+				continue
+			}
+
+			testutils.AssertOk(t, err)
+		}
+	}
+
+	return scanned
+}
+
+// scanCancelledEntries should be more than fit in the buffers between the
+// backend and the caller, so a cancelled scan always stops early
+const scanCancelledEntries = 50
+
+// assertScanCancelled reads one entry, cancels the scan and checks that the
+// channels are closed early with the context error
+func assertScanCancelled[E any](t *testing.T, dataChan chan E, errorChan chan error, cancel context.CancelFunc, numEntries int) {
+	t.Helper()
+
+	_, more := <-dataChan
+	testutils.AssertTrue(t, more)
+	cancel()
+
+	numScanned := 1
+	errs := []error{}
+	for dataChan != nil || errorChan != nil {
+		select {
+		case _, more := <-dataChan:
+			if !more {
+				dataChan = nil
+				continue
+			}
+
+			numScanned += 1
+		case err, more := <-errorChan:
+			if !more {
+				errorChan = nil
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	testutils.AssertTrue(t, numScanned < numEntries)
+	testutils.AssertEquals(t, 1, len(errs))
+	testutils.AssertErrorEquals(t, context.Canceled, errs[0])
+}
+
+func register[C datastore.Connection, B datastore.TableBackend[C]](t *testing.T, conn C, backend B, registerFunc func(*datastore.ConnectionGroup[C]) error) {
+	t.Helper()
+
+	group := datastore.NewConnectionGroup(
+		datastore.WithConnection(conn),
+	)
+	testutils.AssertOk(t, group.RegisterTables(registerFunc))
+
+	t.Cleanup(func() {
+		testutils.AssertOk(t, backend.Drop())
+	})
+}
+
+// testRegisterDrop checks that Register and Drop can be repeated
+func testRegisterDrop[C datastore.Connection](t *testing.T, backend datastore.TableBackend[C], add func() error, count func() (int, error)) {
+	t.Helper()
+
+	testutils.AssertOk(t, add())
+	testutils.AssertOk(t, backend.Register())
+
+	actualCount, err := count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, actualCount)
+
+	testutils.AssertOk(t, backend.Drop())
+	testutils.AssertOk(t, backend.Drop())
+	testutils.AssertOk(t, backend.Register())
+
+	actualCount, err = count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, actualCount)
+}
+
+func RunAppendTableSuite[C datastore.Connection, B datastore.AppendTableBackend[C]](t *testing.T, newBackend BackendFactory[C, B]) {
+	newTable := func(t *testing.T) (*MockAppendTable, B) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockAppendTable()
+		register(t, conn, backend, datastore.RegisterAppendTable[C](mockTable, backend))
+		return mockTable, backend
+	}
+
+	testutils.Case(t, "add and scan", func(t *testing.T) {
+		mockTable, _ := newTable(t)
+		TestAppendTableAddAndScan(t, mockTable)
+	})
+	testutils.Case(t, "scan empty", func(t *testing.T) {
+		mockTable, _ := newTable(t)
+		TestAppendTableScanEmpty(t, mockTable)
+	})
+	testutils.Case(t, "scan cancelled", func(t *testing.T) {
+		mockTable, _ := newTable(t)
+		TestAppendTableScanCancelled(t, mockTable)
+	})
+	testutils.Case(t, "scan with filter", func(t *testing.T) {
+		mockTable, _ := newTable(t)
+		TestAppendTableScanWithFilter(t, mockTable)
+	})
+	testutils.Case(t, "scan page", func(t *testing.T) {
+		mockTable, _ := newTable(t)
+		TestAppendTableScanPage(t, mockTable)
+	})
+	testutils.Case(t, "aggregate", func(t *testing.T) {
+		mockTable, _ := newTable(t)
+		TestAppendTableAggregate(t, mockTable)
+	})
+	testutils.Case(t, "register and drop", func(t *testing.T) {
+		mockTable, backend := newTable(t)
+		testRegisterDrop[C](t, backend, func() error {
+			_, err := mockTable.Add(GenerateNonKeyedEntries(1, "testregister")...)
+			return err
+		}, func() (int, error) {
+			dataChan, errorChan := mockTable.Scan(1)
+			return len(CollectScan(t, dataChan, errorChan)), nil
+		})
+	})
+}
+
+func RunHashTableSuite[C datastore.Connection, B datastore.HashTableBackend[C]](t *testing.T, newBackend BackendFactory[C, B]) {
+	newTable := func(t *testing.T) (*MockTable, B) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		return mockTable, backend
+	}
+
+	tests := []struct {
+		name string
+		test func(*testing.T, *MockTable)
+	}{
+		{"count", TestHashTableCount},
+		{"scan", TestHashTableScan},
+		{"scan cancelled", TestHashTableScanCancelled},
+		{"scan with filter", TestHashTableScanWithFilter},
+		{"scan page", TestHashTableScanPage},
+		{"get", TestHashTableGet},
+		{"get missing", TestHashTableGetMissing},
+		{"add", TestHashTableAdd},
+		{"add duplicate", TestHashTableAddDuplicate},
+		{"update", TestHashTableUpdate},
+		{"update missing", TestHashTableUpdateMissing},
+		{"delete", TestHashTableDelete},
+		{"delete missing", TestHashTableDeleteMissing},
+		{"upsert", TestHashTableUpsert},
+		{"add if absent", TestHashTableAddIfAbsent},
+		{"concurrent upsert", TestHashTableConcurrentUpsert},
+		{"update if", TestHashTableUpdateIf},
+	}
+
+	for _, test := range tests {
+		test := test
+		testutils.Case(t, test.name, func(t *testing.T) {
+			mockTable, _ := newTable(t)
+			test.test(t, mockTable)
+		})
+	}
+
+	testutils.Case(t, "version", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockVersionedTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableVersion(t, mockTable)
+	})
+
+	testutils.Case(t, "version conflict in batch", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockVersionedTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableVersionConflictInBatch(t, mockTable)
+	})
+
+	testutils.Case(t, "key only update", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockKeyOnlyTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableKeyOnlyUpdate(t, mockTable)
+	})
+
+	testutils.Case(t, "aggregate", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockAggregateTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableAggregate(t, mockTable)
+	})
+
+	testutils.Case(t, "index", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockIndexedTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableIndex(t, mockTable)
+	})
+
+	testutils.Case(t, "invalid index", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockIndexedTable(datastore.WithIndex("MissingFieldIndex", "MissingField"))
+		group := datastore.NewConnectionGroup(
+			datastore.WithConnection(conn),
+		)
+		err := group.RegisterTables(datastore.RegisterHashTable[C](mockTable, backend))
+		testutils.AssertErrorEquals(t, datastore.InvalidIndexError, err)
+	})
+
+	testutils.Case(t, "auto generate", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockAutoGenerateTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableAutoGenerate(t, mockTable)
+	})
+
+	testutils.Case(t, "generated", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockGeneratedTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		TestHashTableGenerated(t, mockTable)
+	})
+
+	testutils.Case(t, "add in batches", func(t *testing.T) {
+		mockTable, backend := newTable(t)
+		TestHashTableAddInBatches(t, mockTable, backend)
+	})
+
+	testutils.Case(t, "migration", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockMigrationTable()
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		group := datastore.NewConnectionGroup(
+			datastore.WithConnection(conn),
+		)
+		TestHashTableMigration(t, mockTable, backend, func(migratedTable *MockMigratedTable) error {
+			return group.RegisterTables(datastore.RegisterHashTable[C](migratedTable, backend))
+		})
+	})
+
+	testutils.Case(t, "reorder migration", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockIndexedTable(datastore.WithTableName("TestReorder"))
+		register(t, conn, backend, datastore.RegisterHashTable[C](mockTable, backend))
+		group := datastore.NewConnectionGroup(
+			datastore.WithConnection(conn),
+		)
+		TestHashTableReorderMigration(t, mockTable, backend, func(reorderedTable *MockIndexedTable) error {
+			return group.RegisterTables(datastore.RegisterHashTable[C](reorderedTable, backend))
+		})
+	})
+
+	testutils.Case(t, "register and drop", func(t *testing.T) {
+		mockTable, backend := newTable(t)
+		testRegisterDrop[C](t, backend, func() error {
+			_, err := mockTable.Add(GenerateEntries(1, "testregister")...)
+			return err
+		}, mockTable.Count)
+	})
+}
+
+func RunSortTableSuite[C datastore.Connection, B datastore.SortTableBackend[C]](t *testing.T, newBackend BackendFactory[C, B]) {
+	newTable := func(t *testing.T) (*MockSortTable, B) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockSortTable()
+		register(t, conn, backend, datastore.RegisterSortTable[C](mockTable, backend))
+		return mockTable, backend
+	}
+
+	tests := []struct {
+		name string
+		test func(*testing.T, *MockSortTable)
+	}{
+		{"crud", TestSortTableCRUD},
+		{"scan", TestSortTableScan},
+		{"scan cancelled", TestSortTableScanCancelled},
+		{"scan with filter", TestSortTableScanWithFilter},
+		{"scan page", TestSortTableScanPage},
+		{"aggregate", TestSortTableAggregate},
+		{"get with sort comparator", TestSortTableGetWithSortComparator},
+		{"get with sort options", TestSortTableGetWithSortOptions},
+		{"update with sort comparator", TestSortTableUpdateWithSortComparator},
+		{"delete with sort comparator", TestSortTableDeleteWithSortComparator},
+		{"conditional writes", TestSortTableConditionalWrites},
+	}
+
+	for _, test := range tests {
+		test := test
+		testutils.Case(t, test.name, func(t *testing.T) {
+			mockTable, _ := newTable(t)
+			test.test(t, mockTable)
+		})
+	}
+
+	testutils.Case(t, "version", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockVersionedSortTable()
+		register(t, conn, backend, datastore.RegisterSortTable[C](mockTable, backend))
+		TestSortTableVersion(t, mockTable)
+	})
+
+	testutils.Case(t, "version conflict in batch", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockVersionedSortTable()
+		register(t, conn, backend, datastore.RegisterSortTable[C](mockTable, backend))
+		TestSortTableVersionConflictInBatch(t, mockTable)
+	})
+
+	testutils.Case(t, "index", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockIndexedSortTable()
+		register(t, conn, backend, datastore.RegisterSortTable[C](mockTable, backend))
+		TestSortTableIndex(t, mockTable)
+	})
+
+	testutils.Case(t, "generated", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTable := NewMockGeneratedSortTable()
+		register(t, conn, backend, datastore.RegisterSortTable[C](mockTable, backend))
+		TestSortTableGenerated(t, mockTable)
+	})
+
+	testutils.Case(t, "add in batches", func(t *testing.T) {
+		mockTable, backend := newTable(t)
+		TestSortTableAddInBatches(t, mockTable, backend)
+	})
+
+	testutils.Case(t, "register and drop", func(t *testing.T) {
+		mockTable, backend := newTable(t)
+		testRegisterDrop[C](t, backend, func() error {
+			_, err := mockTable.Add(GenerateSortEntries(1, "testregister")...)
+			return err
+		}, mockTable.Count)
+	})
+}
+
+func RunQueueSuite[C datastore.Connection, B datastore.QueueBackend[C]](t *testing.T, newBackend BackendFactory[C, B]) {
+	newQueue := func(t *testing.T) (*MockQueue, B) {
+		conn, backend := newBackend(t)
+		mockQueue := NewMockQueue()
+		register(t, conn, backend, datastore.RegisterQueue[C](mockQueue, backend))
+		return mockQueue, backend
+	}
+
+	testutils.Case(t, "empty", func(t *testing.T) {
+		mockQueue, _ := newQueue(t)
+		TestQueueEmpty(t, mockQueue)
+	})
+	testutils.Case(t, "order", func(t *testing.T) {
+		mockQueue, _ := newQueue(t)
+		TestQueueOrder(t, mockQueue)
+	})
+	testutils.Case(t, "ack", func(t *testing.T) {
+		mockQueue, _ := newQueue(t)
+		TestMessageAck(t, mockQueue, mockQueue.SendMessage)
+	})
+	testutils.Case(t, "wait", func(t *testing.T) {
+		mockQueue, _ := newQueue(t)
+		TestMessageWait(t, mockQueue, mockQueue.SendMessage)
+	})
+	testutils.Case(t, "batch", func(t *testing.T) {
+		mockQueue, _ := newQueue(t)
+		TestMessageBatch(t, mockQueue, mockQueue.SendMessage)
+	})
+	testutils.Case(t, "visibility", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockQueue := NewMockQueue(datastore.WithVisibilityTimeout(VisibilityTimeout))
+		register(t, conn, backend, datastore.RegisterQueue[C](mockQueue, backend))
+		TestMessageVisibility(t, mockQueue, mockQueue.SendMessage)
+	})
+	testutils.Case(t, "dead letter", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		_, deadLetterBackend := newBackend(t)
+		deadLetterQueue := NewMockDeadLetterQueue()
+		mockQueue := NewMockQueue(
+			datastore.WithVisibilityTimeout(VisibilityTimeout),
+			datastore.WithDeadLetterQueue(DeadLetterQueueName, MaxDeliveryAttempts),
+		)
+		register(t, conn, deadLetterBackend, datastore.RegisterQueue[C](deadLetterQueue, deadLetterBackend))
+		register(t, conn, backend, datastore.RegisterQueue[C](mockQueue, backend))
+		TestMessageDeadLetter(t, mockQueue, mockQueue.SendMessage, deadLetterQueue)
+	})
+	testutils.Case(t, "invalid dead letter queue", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockQueue := NewMockQueue(datastore.WithDeadLetterQueue("TestQueue", MaxDeliveryAttempts))
+		group := datastore.NewConnectionGroup(
+			datastore.WithConnection(conn),
+		)
+		err := group.RegisterTables(datastore.RegisterQueue[C](mockQueue, backend))
+		testutils.AssertErrorEquals(t, datastore.InvalidDeadLetterQueueError, err)
+	})
+	testutils.Case(t, "missing dead letter queue", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockQueue := NewMockQueue(datastore.WithDeadLetterQueue("TestMissingDeadLetterQueue", MaxDeliveryAttempts))
+		group := datastore.NewConnectionGroup(
+			datastore.WithConnection(conn),
+		)
+		err := group.RegisterTables(datastore.RegisterQueue[C](mockQueue, backend))
+		testutils.AssertErrorEquals(t, datastore.DeadLetterQueueDoesNotExistError, err)
+	})
+	testutils.Case(t, "transaction", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockQueue := NewMockQueue()
+		register(t, conn, backend, datastore.RegisterQueue[C](mockQueue, backend))
+		TestQueueTx(t, datastore.NewConnectionGroup(datastore.WithConnection(conn)), mockQueue)
+	})
+	testutils.Case(t, "register and drop", func(t *testing.T) {
+		mockQueue, backend := newQueue(t)
+		testRegisterDrop[C](t, backend, func() error {
+			return mockQueue.SendMessage(GenerateNonKeyedEntries(1, "testregister")...)
+		}, mockQueue.Count)
+	})
+}
+
+func RunTopicSuite[C datastore.Connection, B datastore.TopicBackend[C]](t *testing.T, newBackend BackendFactory[C, B]) {
+	newTopic := func(t *testing.T) (*MockTopic, B) {
+		conn, backend := newBackend(t)
+		mockTopic := NewMockTopic()
+		register(t, conn, backend, datastore.RegisterTopic[C](mockTopic, backend))
+		return mockTopic, backend
+	}
+
+	testutils.Case(t, "fan out", func(t *testing.T) {
+		mockTopic, _ := newTopic(t)
+		TestTopicFanOut(t, mockTopic)
+	})
+	testutils.Case(t, "subscribe after publish", func(t *testing.T) {
+		mockTopic, _ := newTopic(t)
+		TestTopicSubscribeAfterPublish(t, mockTopic)
+	})
+	testutils.Case(t, "unsubscribe", func(t *testing.T) {
+		mockTopic, _ := newTopic(t)
+		TestTopicUnsubscribe(t, mockTopic)
+	})
+	testutils.Case(t, "ack", func(t *testing.T) {
+		mockTopic, _ := newTopic(t)
+		subscription, err := mockTopic.Subscribe("testack")
+		testutils.AssertOk(t, err)
+		TestMessageAck(t, subscription, mockTopic.Publish)
+	})
+	testutils.Case(t, "wait", func(t *testing.T) {
+		mockTopic, _ := newTopic(t)
+		subscription, err := mockTopic.Subscribe("testwait")
+		testutils.AssertOk(t, err)
+		TestMessageWait(t, subscription, mockTopic.Publish)
+	})
+	testutils.Case(t, "batch", func(t *testing.T) {
+		mockTopic, _ := newTopic(t)
+		subscription, err := mockTopic.Subscribe("testbatch")
+		testutils.AssertOk(t, err)
+		TestMessageBatch(t, subscription, mockTopic.Publish)
+	})
+	testutils.Case(t, "visibility", func(t *testing.T) {
+		conn, backend := newBackend(t)
+		mockTopic := NewMockTopic(datastore.WithVisibilityTimeout(VisibilityTimeout))
+		register(t, conn, backend, datastore.RegisterTopic[C](mockTopic, backend))
+		subscription, err := mockTopic.Subscribe("testvisibility")
+		testutils.AssertOk(t, err)
+		TestMessageVisibility(t, subscription, mockTopic.Publish)
+	})
+	testutils.Case(t, "register and drop", func(t *testing.T) {
+		mockTopic, backend := newTopic(t)
+		subscription, err := mockTopic.Subscribe("testregister")
+		testutils.AssertOk(t, err)
+
+		testRegisterDrop[C](t, backend, func() error {
+			return mockTopic.Publish(GenerateNonKeyedEntries(1, "testregister")...)
+		}, func() (int, error) {
+			hasMessage, err := subscription.HasMessage()
+			if err == datastore.SubscriptionDoesNotExistError {
+				// dropping the topic removes its subscriptions
+				return 0, nil
+			} else if hasMessage {
+				return 1, err
+			}
+
+			return 0, err
+		})
+	})
+}
+
+// RunTxSuite runs the transaction tests against a hash table and a sort
+// table registered on the same connection
+func RunTxSuite[C datastore.Connection, HB datastore.HashTableBackend[C], SB datastore.SortTableBackend[C]](t *testing.T, newConnection func(t *testing.T) C, newHashTableBackend func() HB, newSortTableBackend func() SB) {
+	newTables := func(t *testing.T) (*datastore.ConnectionGroup[C], *MockTable, *MockSortTable) {
+		conn := newConnection(t)
+		hashTableBackend := newHashTableBackend()
+		sortTableBackend := newSortTableBackend()
+		mockTable := NewMockTable()
+		mockSortTable := NewMockSortTable()
+		register(t, conn, hashTableBackend, datastore.RegisterHashTable[C](mockTable, hashTableBackend))
+		register(t, conn, sortTableBackend, datastore.RegisterSortTable[C](mockSortTable, sortTableBackend))
+		return datastore.NewConnectionGroup(datastore.WithConnection(conn)), mockTable, mockSortTable
+	}
+
+	testutils.Case(t, "commit", func(t *testing.T) {
+		group, mockTable, mockSortTable := newTables(t)
+		TestTxCommit(t, group, mockTable, mockSortTable)
+	})
+	testutils.Case(t, "rollback", func(t *testing.T) {
+		group, mockTable, mockSortTable := newTables(t)
+		TestTxRollback(t, group, mockTable, mockSortTable)
+	})
+}
@@ -0,0 +1,84 @@
+package datastoretest
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// MOCKS
+
+type MockTopic = datastore.Topic[MockMessage, *MockMessage]
+
+type MockSubscription = datastore.Subscription[MockMessage, *MockMessage]
+
+func NewMockTopic(options ...func(*datastore.TableSettings)) *MockTopic {
+	return &MockTopic{
+		Settings: datastore.NewTableSettings(append([]func(*datastore.TableSettings){
+			datastore.WithTableName("TestTopic"),
+			datastore.WithDataSettings(MockDataSettings),
+		}, options...)...),
+	}
+}
+
+// TESTS
+
+func TestTopicFanOut(t *testing.T, mockTopic *MockTopic) {
+	t.Helper()
+
+	subscription1, err := mockTopic.Subscribe("testfanout1")
+	testutils.AssertOk(t, err)
+	subscription2, err := mockTopic.Subscribe("testfanout2")
+	testutils.AssertOk(t, err)
+
+	messages := GenerateNonKeyedEntries(2, "testfanout")
+	err = mockTopic.Publish(messages...)
+	testutils.AssertOk(t, err)
+
+	for _, subscription := range []*MockSubscription{subscription1, subscription2} {
+		id1 := AssertRecievesMessage(t, subscription, messages[0].Data.Data)
+		id2 := AssertRecievesMessage(t, subscription, messages[1].Data.Data)
+		testutils.AssertOk(t, subscription.AckSuccess(id1, id2))
+		AssertNoMessage(t, subscription)
+	}
+
+	testutils.AssertOk(t, subscription1.Unsubscribe())
+	testutils.AssertOk(t, subscription2.Unsubscribe())
+}
+
+func TestTopicSubscribeAfterPublish(t *testing.T, mockTopic *MockTopic) {
+	t.Helper()
+
+	err := mockTopic.Publish(GenerateNonKeyedEntries(1, "testsubscribeafter")...)
+	testutils.AssertOk(t, err)
+
+	subscription, err := mockTopic.Subscribe("testsubscribeafter")
+	testutils.AssertOk(t, err)
+	AssertNoMessage(t, subscription)
+
+	testutils.AssertOk(t, subscription.Unsubscribe())
+}
+
+func TestTopicUnsubscribe(t *testing.T, mockTopic *MockTopic) {
+	t.Helper()
+
+	subscription1, err := mockTopic.Subscribe("testunsubscribe1")
+	testutils.AssertOk(t, err)
+	subscription2, err := mockTopic.Subscribe("testunsubscribe2")
+	testutils.AssertOk(t, err)
+
+	testutils.AssertOk(t, subscription1.Unsubscribe())
+
+	messages := GenerateNonKeyedEntries(1, "testunsubscribe")
+	err = mockTopic.Publish(messages...)
+	testutils.AssertOk(t, err)
+
+	_, err = subscription1.HasMessage()
+	testutils.AssertErrorEquals(t, datastore.SubscriptionDoesNotExistError, err)
+	testutils.AssertErrorEquals(t, datastore.SubscriptionDoesNotExistError, subscription1.Unsubscribe())
+
+	id := AssertRecievesMessage(t, subscription2, messages[0].Data.Data)
+	testutils.AssertOk(t, subscription2.AckSuccess(id))
+	testutils.AssertOk(t, subscription2.Unsubscribe())
+}
@@ -0,0 +1,207 @@
+package datastoretest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+var mockTxError = errors.New("mock tx error")
+
+func assertHashTableData(t *testing.T, mockTable *MockTable, key *MockKey, expectedData string) {
+	t.Helper()
+
+	found, err := mockTable.Get(key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(found))
+	if len(found) == 1 {
+		testutils.AssertEquals(t, expectedData, found[0].Data.Data)
+	}
+}
+
+func assertCount(t *testing.T, count func() (int, error), expected int) {
+	t.Helper()
+
+	actual, err := count()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, expected, actual)
+}
+
+// TESTS
+
+// TestTxCommit adds to, updates and deletes from both tables in one
+// transaction, and checks every change is kept once it commits
+func TestTxCommit[C datastore.Connection](t *testing.T, group *datastore.ConnectionGroup[C], mockTable *MockTable, mockSortTable *MockSortTable) {
+	t.Helper()
+
+	existing := GenerateEntries(2, "testtxcommitexisting")
+	_, err := mockTable.Add(existing...)
+	testutils.AssertOk(t, err)
+
+	entries := GenerateEntries(1, "testtxcommit")
+	sortEntries := GenerateSortEntries(3, "testtxcommit")
+
+	err = group.WithTx(context.Background(), func(ctx context.Context) error {
+		if _, err := mockTable.AddContext(ctx, entries...); err != nil {
+			return err
+		}
+
+		existing[0].Data.Data = "updated"
+		if err := mockTable.UpdateContext(ctx, existing[0]); err != nil {
+			return err
+		} else if err := mockTable.DeleteContext(ctx, existing[1].Key); err != nil {
+			return err
+		}
+
+		_, err := mockSortTable.AddContext(ctx, sortEntries...)
+		return err
+	})
+	testutils.AssertOk(t, err)
+
+	assertCount(t, mockTable.Count, 2)
+	assertHashTableData(t, mockTable, entries[0].Key, entries[0].Data.Data)
+	assertHashTableData(t, mockTable, existing[0].Key, "updated")
+	assertCount(t, mockSortTable.Count, len(sortEntries))
+}
+
+// TestTxRollback makes the same changes as TestTxCommit, and checks none of
+// them are kept when the transaction fails or panics
+func TestTxRollback[C datastore.Connection](t *testing.T, group *datastore.ConnectionGroup[C], mockTable *MockTable, mockSortTable *MockSortTable) {
+	t.Helper()
+
+	existing := GenerateEntries(2, "testtxrollbackexisting")
+	_, err := mockTable.Add(existing...)
+	testutils.AssertOk(t, err)
+
+	change := func(ctx context.Context) error {
+		if _, err := mockTable.AddContext(ctx, GenerateEntries(1, "testtxrollback")...); err != nil {
+			return err
+		}
+
+		updated := GenerateEntries(2, "testtxrollbackexisting")[0]
+		updated.Data.Data = "updated"
+		if err := mockTable.UpdateContext(ctx, updated); err != nil {
+			return err
+		} else if err := mockTable.DeleteContext(ctx, existing[1].Key); err != nil {
+			return err
+		}
+
+		_, err := mockSortTable.AddContext(ctx, GenerateSortEntries(3, "testtxrollback")...)
+		return err
+	}
+
+	assertUnchanged := func(t *testing.T) {
+		t.Helper()
+
+		assertCount(t, mockTable.Count, len(existing))
+		for _, entry := range existing {
+			assertHashTableData(t, mockTable, entry.Key, entry.Data.Data)
+		}
+		assertCount(t, mockSortTable.Count, 0)
+	}
+
+	testutils.Case(t, "returns error", func(t *testing.T) {
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			if err := change(ctx); err != nil {
+				return err
+			}
+
+			return mockTxError
+		})
+		testutils.AssertErrorEquals(t, mockTxError, err)
+		assertUnchanged(t)
+	})
+
+	testutils.Case(t, "fails partway", func(t *testing.T) {
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			if err := change(ctx); err != nil {
+				return err
+			}
+
+			// the key was already deleted in this transaction
+			return mockTable.DeleteContext(ctx, existing[1].Key)
+		})
+		testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+		assertUnchanged(t)
+	})
+
+	testutils.Case(t, "panics", func(t *testing.T) {
+		func() {
+			defer func() {
+				testutils.AssertTrue(t, recover() == mockTxError)
+			}()
+
+			group.WithTx(context.Background(), func(ctx context.Context) error {
+				testutils.AssertOk(t, change(ctx))
+				panic(mockTxError)
+			})
+		}()
+		assertUnchanged(t)
+	})
+
+	testutils.Case(t, "nested transaction fails", func(t *testing.T) {
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			// the inner transaction joins the outer one, so its changes are
+			// rolled back with it even though it succeeded
+			if err := group.WithTx(ctx, change); err != nil {
+				return err
+			}
+
+			return mockTxError
+		})
+		testutils.AssertErrorEquals(t, mockTxError, err)
+		assertUnchanged(t)
+	})
+}
+
+// TestQueueTx checks that sending and recieving messages in a transaction is
+// undone when it fails
+func TestQueueTx[C datastore.Connection](t *testing.T, group *datastore.ConnectionGroup[C], mockQueue *MockQueue) {
+	t.Helper()
+
+	testutils.Case(t, "send is rolled back", func(t *testing.T) {
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			if err := mockQueue.SendMessageContext(ctx, GenerateNonKeyedEntries(2, "testtxsend")...); err != nil {
+				return err
+			}
+
+			return mockTxError
+		})
+		testutils.AssertErrorEquals(t, mockTxError, err)
+		AssertNoMessage(t, mockQueue)
+	})
+
+	testutils.Case(t, "recieve is rolled back", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testtxrecieve")[0]
+		testutils.AssertOk(t, mockQueue.SendMessage(message))
+
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			id, _, err := mockQueue.RecieveMessageContext(ctx)
+			if err != nil {
+				return err
+			} else if err := mockQueue.AckSuccessContext(ctx, id); err != nil {
+				return err
+			}
+
+			return mockTxError
+		})
+		testutils.AssertErrorEquals(t, mockTxError, err)
+
+		id := AssertRecievesMessage(t, mockQueue, message.Data.Data)
+		testutils.AssertOk(t, mockQueue.AckSuccess(id))
+	})
+
+	testutils.Case(t, "send is committed", func(t *testing.T) {
+		message := GenerateNonKeyedEntries(1, "testtxcommit")[0]
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			return mockQueue.SendMessageContext(ctx, message)
+		})
+		testutils.AssertOk(t, err)
+
+		id := AssertRecievesMessage(t, mockQueue, message.Data.Data)
+		testutils.AssertOk(t, mockQueue.AckSuccess(id))
+	})
+}
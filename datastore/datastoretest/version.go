@@ -0,0 +1,284 @@
+package datastoretest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// HELPERS
+
+func assertConflict(t *testing.T, err error, expected, actual fields.Int) {
+	t.Helper()
+
+	var conflictErr *datastore.ConflictError
+	testutils.AssertTrue(t, errors.As(err, &conflictErr))
+	if conflictErr != nil {
+		testutils.AssertTrue(t, conflictErr.Expected == expected)
+		testutils.AssertTrue(t, conflictErr.Actual == actual)
+	}
+}
+
+// MOCKS
+
+const VersionKey = "Version"
+
+type MockVersionedData struct {
+	Data    fields.String
+	Version fields.Int
+}
+
+func (d *MockVersionedData) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(DataKey, &d.Data),
+		mutator.WithAddress(VersionKey, &d.Version),
+	)
+}
+
+var MockVersionedDataSettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithNumBytes(DataKey, 63),
+		fields.WithVersion(VersionKey),
+	),
+	FieldOrder: fields.OrderedFieldKeys{DataKey, VersionKey},
+}
+
+type MockVersionedEntry = fields.KeyedEntry[MockKey, *MockKey, MockVersionedData, *MockVersionedData]
+
+type MockVersionedTable = datastore.HashTable[MockKey, *MockKey, MockVersionedEntry, *MockVersionedEntry]
+
+func NewMockVersionedTable() *MockVersionedTable {
+	return &MockVersionedTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestVersioned"),
+			datastore.WithDataSettings(MockVersionedDataSettings),
+			datastore.WithKeySettings(MockKeySettings),
+		),
+	}
+}
+
+type MockVersionedSortEntry = fields.KeyedEntry[MockSortKey, *MockSortKey, MockVersionedData, *MockVersionedData]
+
+type MockVersionedSortTable = datastore.SortTable[MockSortKey, *MockSortKey, MockVersionedSortEntry, *MockVersionedSortEntry, MockSortComparator, *MockSortComparator]
+
+func NewMockVersionedSortTable() *MockVersionedSortTable {
+	return &MockVersionedSortTable{
+		Settings: datastore.NewTableSettings(
+			datastore.WithTableName("TestVersionedSort"),
+			datastore.WithDataSettings(MockVersionedDataSettings),
+			datastore.WithKeySettings(MockSortKeySettings),
+			datastore.WithSortFieldNames(MockSortFieldNames),
+		),
+	}
+}
+
+// TESTS
+
+// TestHashTableVersion updates an entry twice with the version it was read
+// with, and checks only the first update succeeds
+func TestHashTableVersion(t *testing.T, mockTable *MockVersionedTable) {
+	t.Helper()
+
+	entry := &MockVersionedEntry{
+		Key:  &MockKey{Id: "testversion"},
+		Data: &MockVersionedData{Data: "0"},
+	}
+
+	_, err := mockTable.Add(entry)
+	testutils.AssertOk(t, err)
+
+	found, err := mockTable.Get(entry.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(found))
+	stale := found[0]
+
+	entry.Data.Data = "1"
+	err = mockTable.Update(entry)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, entry.Data.Version)
+
+	stale.Data.Data = "stale"
+	err = mockTable.Update(stale)
+	assertConflict(t, err, 0, 1)
+	testutils.AssertEquals(t, 0, stale.Data.Version)
+
+	// the updated entry has the new version so can be updated again
+	entry.Data.Data = "2"
+	err = mockTable.Update(entry)
+	testutils.AssertOk(t, err)
+
+	found, err = mockTable.Get(entry.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(found))
+	testutils.AssertEquals(t, "2", found[0].Data.Data)
+	testutils.AssertEquals(t, 2, found[0].Data.Version)
+
+	// upserting an existing entry checks and bumps its version too
+	upserted, err := mockTable.Upsert(stale)
+	assertConflict(t, err, 0, 2)
+	testutils.AssertEquals(t, 0, len(upserted))
+
+	upserted, err = mockTable.Upsert(entry)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(upserted))
+	if len(upserted) == 1 {
+		testutils.AssertEquals(t, 3, upserted[0].Data.Version)
+	}
+
+	err = mockTable.Update(&MockVersionedEntry{
+		Key:  &MockKey{Id: "testversionmissing"},
+		Data: &MockVersionedData{},
+	})
+	testutils.AssertErrorEquals(t, datastore.KeyDoesNotExistError, err)
+
+	err = mockTable.Delete(entry.Key)
+	testutils.AssertOk(t, err)
+}
+
+// TestSortTableVersion checks Update and UpdateWithSortComparator only
+// change rows with the expected version
+func TestSortTableVersion(t *testing.T, mockTable *MockVersionedSortTable) {
+	t.Helper()
+
+	entries := make([]*MockVersionedSortEntry, 3)
+	for i := range entries {
+		entries[i] = &MockVersionedSortEntry{
+			Key:  &MockSortKey{Id: "testversion", Sort: i},
+			Data: &MockVersionedData{Data: "0"},
+		}
+	}
+
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	entries[2].Data.Data = "1"
+	err = mockTable.Update(entries[2])
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, entries[2].Data.Version)
+
+	// one of the matched rows has a newer version, so none are updated
+	update := &MockVersionedSortEntry{
+		Key:  &MockSortKey{Id: "testversion"},
+		Data: &MockVersionedData{Data: "all"},
+	}
+	err = mockTable.UpdateWithSortComparator(update, &MockSortComparator{Sort: compare.Gte(1)})
+	assertConflict(t, err, 0, 1)
+
+	found, err := mockTable.GetWithSortComparator(&MockSortKey{Id: "testversion"}, &MockSortComparator{})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, len(found))
+	for i, entry := range found {
+		testutils.AssertEquals(t, entries[i].Data.Data, entry.Data.Data)
+	}
+
+	err = mockTable.UpdateWithSortComparator(update, &MockSortComparator{Sort: compare.Lt(2)})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, update.Data.Version)
+
+	found, err = mockTable.GetWithSortComparator(&MockSortKey{Id: "testversion"}, &MockSortComparator{})
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 3, len(found))
+	for _, entry := range found {
+		testutils.AssertEquals(t, 1, entry.Data.Version)
+	}
+	testutils.AssertEquals(t, "all", found[0].Data.Data)
+	testutils.AssertEquals(t, "1", found[2].Data.Data)
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+// TestHashTableVersionConflictInBatch updates and upserts two entries where
+// only the second has a stale version, and checks neither is changed
+func TestHashTableVersionConflictInBatch(t *testing.T, mockTable *MockVersionedTable) {
+	t.Helper()
+
+	entries := []*MockVersionedEntry{
+		{Key: &MockKey{Id: "testversionbatch0"}, Data: &MockVersionedData{Data: "0"}},
+		{Key: &MockKey{Id: "testversionbatch1"}, Data: &MockVersionedData{Data: "0"}},
+	}
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	stale := &MockVersionedEntry{Key: entries[1].Key, Data: &MockVersionedData{Data: "0"}}
+	err = mockTable.Update(stale)
+	testutils.AssertOk(t, err)
+
+	entries[0].Data.Data = "1"
+	entries[1].Data.Data = "1"
+	err = mockTable.Update(entries...)
+	assertConflict(t, err, 0, 1)
+
+	_, err = mockTable.Upsert(entries...)
+	assertConflict(t, err, 0, 1)
+
+	// an entry added by the failed upsert is removed too
+	added := &MockVersionedEntry{Key: &MockKey{Id: "testversionbatch2"}, Data: &MockVersionedData{Data: "0"}}
+	_, err = mockTable.Upsert(added, entries[1])
+	assertConflict(t, err, 0, 1)
+
+	found, err := mockTable.Get(added.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(found))
+
+	found, err = mockTable.Get(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(found))
+	if len(found) == 2 {
+		testutils.AssertEquals(t, "0", found[0].Data.Data)
+		testutils.AssertEquals(t, 0, found[0].Data.Version)
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
+
+// TestSortTableVersionConflictInBatch is TestHashTableVersionConflictInBatch
+// for sort tables
+func TestSortTableVersionConflictInBatch(t *testing.T, mockTable *MockVersionedSortTable) {
+	t.Helper()
+
+	entries := []*MockVersionedSortEntry{
+		{Key: &MockSortKey{Id: "testversionbatch", Sort: 0}, Data: &MockVersionedData{Data: "0"}},
+		{Key: &MockSortKey{Id: "testversionbatch", Sort: 1}, Data: &MockVersionedData{Data: "0"}},
+	}
+	_, err := mockTable.Add(entries...)
+	testutils.AssertOk(t, err)
+
+	stale := &MockVersionedSortEntry{Key: entries[1].Key, Data: &MockVersionedData{Data: "0"}}
+	err = mockTable.Update(stale)
+	testutils.AssertOk(t, err)
+
+	entries[0].Data.Data = "1"
+	entries[1].Data.Data = "1"
+	err = mockTable.Update(entries...)
+	assertConflict(t, err, 0, 1)
+
+	_, err = mockTable.Upsert(entries...)
+	assertConflict(t, err, 0, 1)
+
+	// an entry added by the failed upsert is removed too
+	added := &MockVersionedSortEntry{Key: &MockSortKey{Id: "testversionbatch", Sort: 2}, Data: &MockVersionedData{Data: "0"}}
+	_, err = mockTable.Upsert(added, entries[1])
+	assertConflict(t, err, 0, 1)
+
+	found, err := mockTable.Get(added.Key)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 0, len(found))
+
+	found, err = mockTable.Get(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(found))
+	if len(found) == 2 {
+		testutils.AssertEquals(t, "0", found[0].Data.Data)
+		testutils.AssertEquals(t, 0, found[0].Data.Version)
+	}
+
+	err = mockTable.Delete(fields.KeysOfEntries(entries)...)
+	testutils.AssertOk(t, err)
+}
@@ -1,7 +1,90 @@
 package datastore
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 var InputLengthMismatchError = errors.New("the number of keys and values input must match")
 
 var OutputLengthMismatchError = errors.New("the number of keys or values output must exactly match how many were input")
+
+var KeyExistsError = errors.New("cannot add a key that already exists")
+
+var KeyDoesNotExistError = errors.New("cannot update a key that does not already exist")
+
+var QueueEmptyError = errors.New("cannot recieve a message from an empty queue")
+
+var QueueDoesNotExistError = errors.New("queue does not exist or has been dropped")
+
+var TopicDoesNotExistError = errors.New("topic does not exist or has been dropped")
+
+var SubscriptionDoesNotExistError = errors.New("subscription does not exist or has been unsubscribed")
+
+var DeadLetterQueueDoesNotExistError = errors.New("dead letter queue does not exist or has not been registered")
+
+var InvalidDeadLetterQueueError = errors.New("a queue cannot be its own dead letter queue")
+
+var IndexDoesNotExistError = errors.New("index does not exist in the table settings")
+
+var InvalidIndexError = errors.New("an index must have a unique name and only include key or data fields")
+
+var FieldDoesNotExistError = errors.New("field does not exist in the key or data settings")
+
+var InvalidCursorError = errors.New("cursor was not returned by a page of this table")
+
+var PageNotSupportedError = errors.New("the table has no fields to order pages by")
+
+var AggregateTypeError = errors.New("aggregate function does not support the type of its field")
+
+var ConditionFailedError = errors.New("cannot update an entry which does not match the condition")
+
+var TxNotSupportedError = errors.New("the connection does not support transactions")
+
+var OutboxDestinationDoesNotExistError = errors.New("outbox destination has not been added with AddQueue or AddTopic")
+
+var MigrationNotSupportedError = errors.New("the backend cannot make this change to the schema of a table")
+
+var InvalidSettingsError = errors.New("table settings do not match the fields of the entry")
+
+// ConflictError is returned when an update is made with a version which does
+// not match the stored version of the row with Key
+type ConflictError struct {
+	Key      mutator.MappedFieldValues
+	Expected any
+	Actual   any
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("cannot update a row with version %v, the stored version is %v", e.Expected, e.Actual)
+}
+
+// SettingsProblem is one inconsistency between the settings of a table and
+// the fields of its entry
+type SettingsProblem struct {
+	FieldName string
+	Message   string
+}
+
+// SettingsError lists every problem with the settings of a table, it wraps
+// InvalidSettingsError
+type SettingsError struct {
+	TableName string
+	Problems  []*SettingsProblem
+}
+
+func (e *SettingsError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = fmt.Sprintf("%s: %s", problem.FieldName, problem.Message)
+	}
+
+	return fmt.Sprintf("invalid settings for table %s: %s", e.TableName, strings.Join(problems, "; "))
+}
+
+func (e *SettingsError) Unwrap() error {
+	return InvalidSettingsError
+}
@@ -5,32 +5,17 @@ import (
 	"github.com/sophielizg/go-libs/datastore/mutator"
 )
 
-const (
-	DepartmentKey  = "Department"
-	PriceKey       = "Price"
-	QuantityKey    = "Quantity"
-	LastUpdatedKey = "LastUpdated"
-)
-
+// Data derives its mutator and settings from its ds tags, instead of
+// listing every field in both
 type Data struct {
-	Department  fields.String
-	Price       fields.Float
-	Quantity    fields.Int
-	LastUpdated fields.Time
+	Department  fields.String `ds:"Department,bytes=63"`
+	Price       fields.Float  `ds:"Price"`
+	Quantity    fields.Int    `ds:"Quantity"`
+	LastUpdated fields.Time   `ds:"LastUpdated"`
 }
 
 func (d *Data) Mutator() *mutator.FieldMutator {
-	return mutator.NewFieldMutator(
-		mutator.WithAddress(DepartmentKey, &d.Department),
-		mutator.WithAddress(PriceKey, &d.Price),
-		mutator.WithAddress(QuantityKey, &d.Quantity),
-		mutator.WithAddress(LastUpdatedKey, &d.LastUpdated),
-	)
+	return mutator.NewTaggedFieldMutator(d)
 }
 
-var DataSettings = &fields.RowSettings{
-	FieldSettings: fields.NewFieldSettings(
-		fields.WithNumBytes(DepartmentKey, 63),
-	),
-	FieldOrder: fields.OrderedFieldKeys{DepartmentKey, PriceKey, QuantityKey, LastUpdatedKey},
-}
+var DataSettings = fields.MustTaggedRowSettings[Data]()
@@ -5,27 +5,13 @@ import (
 	"github.com/sophielizg/go-libs/datastore/mutator"
 )
 
-const (
-	BrandKey = "Brand"
-	NameKey  = "Name"
-)
-
 type Key struct {
-	Brand fields.String
-	Name  fields.String
+	Brand fields.String `ds:"Brand,bytes=63"`
+	Name  fields.String `ds:"Name,bytes=255"`
 }
 
 func (k *Key) Mutator() *mutator.FieldMutator {
-	return mutator.NewFieldMutator(
-		mutator.WithAddress(BrandKey, &k.Brand),
-		mutator.WithAddress(NameKey, &k.Name),
-	)
+	return mutator.NewTaggedFieldMutator(k)
 }
 
-var KeySettings = &fields.RowSettings{
-	FieldSettings: fields.NewFieldSettings(
-		fields.WithNumBytes(BrandKey, 63),
-		fields.WithNumBytes(NameKey, 255),
-	),
-	FieldOrder: fields.OrderedFieldKeys{BrandKey, NameKey},
-}
+var KeySettings = fields.MustTaggedRowSettings[Key]()
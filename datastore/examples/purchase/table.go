@@ -1,6 +1,9 @@
 package purchase
 
-import "github.com/sophielizg/go-libs/datastore"
+import (
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/queries"
+)
 
 type PurchaseTable = datastore.SortTable[Key, *Key, Entry, *Entry, SortComparator, *SortComparator]
 
@@ -14,3 +17,22 @@ func NewTable() *PurchaseTable {
 		),
 	}
 }
+
+// Latest returns the n most recent purchases by customerName, newest first
+func Latest(table *PurchaseTable, customerName string, n int) ([]*Entry, error) {
+	return table.GetWithSortComparator(
+		&Key{CustomerName: customerName},
+		&SortComparator{},
+		queries.WithDescending(),
+		queries.WithLimit(n),
+	)
+}
+
+// DepartmentTotals returns the number of purchases, the total price and the
+// total quantity sold by each Department
+func DepartmentTotals(table *PurchaseTable) ([]*queries.AggregateResult[Entry, *Entry], error) {
+	return table.Aggregate(
+		[]*queries.Aggregate{queries.Count(), queries.Sum(PriceKey), queries.Sum(QuantityKey)},
+		queries.WithGroupBy(DepartmentKey),
+	)
+}
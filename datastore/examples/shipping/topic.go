@@ -12,3 +12,5 @@ func NewShippedTopic() *Topic {
 		),
 	}
 }
+
+type Subscription = datastore.Subscription[Message, *Message]
@@ -0,0 +1,13 @@
+package fields
+
+import "errors"
+
+var VersionTypeError = errors.New("a version field must be a non null int type")
+
+var GeneratorTypeError = errors.New("generator does not support the field's type")
+
+var BackendGeneratedError = errors.New("field is generated by the backend when an entry is added")
+
+var InvalidTagError = errors.New("ds struct tag has an unknown or malformed option")
+
+var TaggedTypeError = errors.New("row settings can only be derived from the tags of a struct")
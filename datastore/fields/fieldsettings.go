@@ -9,8 +9,16 @@ const (
 type FieldSettings = map[string]*FieldSetting
 
 type FieldSetting struct {
+	// NumBytes is the size of the field's column. For strings in mysql it is
+	// the maximum number of characters rather than bytes.
 	NumBytes     int
 	AutoGenerate bool
+	// Generator creates the values of an AutoGenerate field, when it is nil
+	// the default for the field's type is used
+	Generator Generator
+	// Version marks the field as the row's version, which must match the
+	// stored value for an update to succeed and is incremented by each one
+	Version bool
 }
 
 func NewFieldSettings(options ...func(FieldSettings)) FieldSettings {
@@ -38,9 +46,58 @@ func WithAutoGenerate(fieldName string) func(settings FieldSettings) {
 	}
 }
 
+// WithGenerator auto generates the field with generator
+func WithGenerator(fieldName string, generator Generator) func(settings FieldSettings) {
+	return func(settings FieldSettings) {
+		setting := settingForFieldName(settings, fieldName)
+		setting.AutoGenerate = true
+		setting.Generator = generator
+	}
+}
+
 func WithNumBytes(fieldName string, numBytes int) func(settings FieldSettings) {
 	return func(settings FieldSettings) {
 		setting := settingForFieldName(settings, fieldName)
 		setting.NumBytes = numBytes
 	}
 }
+
+func WithVersion(fieldName string) func(settings FieldSettings) {
+	return func(settings FieldSettings) {
+		setting := settingForFieldName(settings, fieldName)
+		setting.Version = true
+	}
+}
+
+// VersionFieldName returns the name of the first field in the field order
+// marked with WithVersion, or an empty string if there is none
+func VersionFieldName(settings *RowSettings) string {
+	if settings == nil {
+		return ""
+	}
+
+	for _, fieldName := range settings.FieldOrder {
+		if setting := settings.FieldSettings[fieldName]; setting != nil && setting.Version {
+			return fieldName
+		}
+	}
+
+	return ""
+}
+
+// NextVersion returns the version following value, which must be one of the
+// non null int types
+func NextVersion(value any) (any, error) {
+	switch version := value.(type) {
+	case Int:
+		return version + 1, nil
+	case UInt:
+		return version + 1, nil
+	case BigInt:
+		return version + 1, nil
+	case BigUInt:
+		return version + 1, nil
+	default:
+		return nil, VersionTypeError
+	}
+}
@@ -17,3 +17,31 @@ func TestNewFieldSettings(t *testing.T) {
 	testutils.AssertTrue(t, setting.AutoGenerate)
 	testutils.AssertEquals(t, 31, setting.NumBytes)
 }
+
+func TestVersionFieldName(t *testing.T) {
+	settings := &fields.RowSettings{
+		FieldSettings: fields.NewFieldSettings(
+			fields.WithNumBytes("name", 31),
+			fields.WithVersion("version"),
+		),
+		FieldOrder: fields.OrderedFieldKeys{"name", "version"},
+	}
+
+	testutils.AssertTrue(t, settings.FieldSettings["version"].Version)
+	testutils.AssertEquals(t, "version", fields.VersionFieldName(settings))
+	testutils.AssertEquals(t, "", fields.VersionFieldName(&fields.RowSettings{}))
+	testutils.AssertEquals(t, "", fields.VersionFieldName(nil))
+}
+
+func TestNextVersion(t *testing.T) {
+	next, err := fields.NextVersion(fields.Int(1))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, next == fields.Int(2))
+
+	next, err = fields.NextVersion(fields.BigUInt(0))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, next == fields.BigUInt(1))
+
+	_, err = fields.NextVersion(fields.String("1"))
+	testutils.AssertErrorEquals(t, fields.VersionTypeError, err)
+}
@@ -0,0 +1,186 @@
+package fields
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// Generator creates the value of an auto generated field, with the type of
+// emptyValue
+type Generator interface {
+	Generate(emptyValue any) (any, error)
+}
+
+// GeneratorFunc lets a function be used as a custom Generator
+type GeneratorFunc func(emptyValue any) (any, error)
+
+func (f GeneratorFunc) Generate(emptyValue any) (any, error) {
+	return f(emptyValue)
+}
+
+type autoIncrement struct{}
+
+func (autoIncrement) Generate(emptyValue any) (any, error) {
+	return nil, BackendGeneratedError
+}
+
+// AutoIncrement generates increasing integers in the backend, it is the
+// default for int fields
+var AutoIncrement Generator = autoIncrement{}
+
+// stringGenerator is used by reference, so the generators can be compared
+type stringGenerator struct {
+	generate func() (string, error)
+}
+
+func (g *stringGenerator) Generate(emptyValue any) (any, error) {
+	value, err := g.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	switch emptyValue.(type) {
+	case String:
+		return value, nil
+	case NullString:
+		return &value, nil
+	default:
+		return nil, GeneratorTypeError
+	}
+}
+
+// UUIDv4 generates random UUID strings. It is the default for string fields
+// marked with WithAutoGenerate.
+var UUIDv4 Generator = &stringGenerator{func() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+
+	return formatUUID(uuid, 4), nil
+}}
+
+// UUIDv7 generates UUID strings which start with the time they were created,
+// so they sort in the order they were generated to the millisecond
+var UUIDv7 Generator = &stringGenerator{func() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		return "", err
+	}
+
+	putMillis(uuid[:6], time.Now())
+	return formatUUID(uuid, 7), nil
+}}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates 26 character ULID strings, which like UUIDv7 start with the
+// time they were created
+var ULID Generator = &stringGenerator{func() (string, error) {
+	var ulid [16]byte
+	if _, err := rand.Read(ulid[6:]); err != nil {
+		return "", err
+	}
+
+	putMillis(ulid[:6], time.Now())
+
+	encoded := make([]byte, 26)
+	value := new(big.Int).SetBytes(ulid[:])
+	mask := big.NewInt(31)
+	digit := new(big.Int)
+	for i := len(encoded) - 1; i >= 0; i -= 1 {
+		encoded[i] = crockfordAlphabet[digit.And(value, mask).Int64()]
+		value.Rsh(value, 5)
+	}
+
+	return string(encoded), nil
+}}
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	MaxSnowflakeNode      = 1<<snowflakeNodeBits - 1
+)
+
+// SnowflakeEpoch is the time snowflake timestamps are counted from
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	millis   int64
+	sequence int64
+}
+
+// NewSnowflake generates 64 bit integers from the time, node and a sequence.
+// Each process must use a different node up to MaxSnowflakeNode.
+func NewSnowflake(node int64) Generator {
+	return &snowflake{node: node & MaxSnowflakeNode}
+}
+
+func (s *snowflake) next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	millis := time.Since(SnowflakeEpoch).Milliseconds()
+	if millis <= s.millis {
+		millis = s.millis
+		s.sequence = (s.sequence + 1) & (1<<snowflakeSequenceBits - 1)
+		// the sequence ran out for this millisecond, so the next one is used
+		if s.sequence == 0 {
+			millis += 1
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.millis = millis
+
+	return millis<<(snowflakeNodeBits+snowflakeSequenceBits) | s.node<<snowflakeSequenceBits | s.sequence
+}
+
+func (s *snowflake) Generate(emptyValue any) (any, error) {
+	return IntValue(emptyValue, s.next())
+}
+
+func putMillis(dest []byte, now time.Time) {
+	var millis [8]byte
+	binary.BigEndian.PutUint64(millis[:], uint64(now.UnixMilli()))
+	copy(dest, millis[2:])
+}
+
+func formatUUID(uuid [16]byte, version byte) string {
+	uuid[6] = uuid[6]&0x0f | version<<4
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+// GeneratorFor returns the generator of a field with this setting and empty
+// value, or nil if it is not generated
+func (s *FieldSetting) GeneratorFor(emptyValue any) Generator {
+	if s == nil || !s.AutoGenerate {
+		return nil
+	} else if s.Generator != nil {
+		return s.Generator
+	}
+
+	switch emptyValue.(type) {
+	case Int, NullInt, UInt, NullUInt, BigInt, NullBigInt, BigUInt, NullBigUInt:
+		return AutoIncrement
+	case String, NullString:
+		return UUIDv4
+	default:
+		return nil
+	}
+}
+
+// IsAutoIncrement reports whether the backend generates the values of a
+// field with this setting and empty value
+func (s *FieldSetting) IsAutoIncrement(emptyValue any) bool {
+	_, ok := s.GeneratorFor(emptyValue).(autoIncrement)
+	return ok
+}
@@ -0,0 +1,101 @@
+package fields_test
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestUUIDGenerators(t *testing.T) {
+	uuidV4Pattern := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+	uuidV7Pattern := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+
+	value, err := fields.UUIDv4.Generate(fields.String(""))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, uuidV4Pattern.MatchString(value.(fields.String)))
+
+	nullValue, err := fields.UUIDv4.Generate(fields.NullString(nil))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, uuidV4Pattern.MatchString(*nullValue.(fields.NullString)))
+
+	first, err := fields.UUIDv7.Generate(fields.String(""))
+	testutils.AssertOk(t, err)
+	time.Sleep(2 * time.Millisecond)
+	second, err := fields.UUIDv7.Generate(fields.String(""))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, uuidV7Pattern.MatchString(first.(fields.String)))
+	testutils.AssertTrue(t, first.(fields.String) < second.(fields.String))
+
+	_, err = fields.UUIDv4.Generate(fields.Int(0))
+	testutils.AssertErrorEquals(t, fields.GeneratorTypeError, err)
+}
+
+func TestULIDGenerator(t *testing.T) {
+	ulidPattern := regexp.MustCompile("^[0-9A-HJKMNP-TV-Z]{26}$")
+
+	first, err := fields.ULID.Generate(fields.String(""))
+	testutils.AssertOk(t, err)
+	time.Sleep(2 * time.Millisecond)
+	second, err := fields.ULID.Generate(fields.String(""))
+	testutils.AssertOk(t, err)
+
+	testutils.AssertTrue(t, ulidPattern.MatchString(first.(fields.String)))
+	testutils.AssertTrue(t, first.(fields.String) < second.(fields.String))
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	generator := fields.NewSnowflake(3)
+
+	ids := make([]fields.BigInt, 5000)
+	for i := range ids {
+		value, err := generator.Generate(fields.BigInt(0))
+		testutils.AssertOk(t, err)
+		ids[i] = value.(fields.BigInt)
+	}
+
+	testutils.AssertTrue(t, sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }))
+	for i := 1; i < len(ids); i += 1 {
+		testutils.AssertTrue(t, ids[i-1] != ids[i])
+	}
+	testutils.AssertEquals(t, fields.BigInt(3), ids[0]>>12&fields.MaxSnowflakeNode)
+
+	value, err := generator.Generate(fields.NullUInt(nil))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, fields.BigInt(*value.(fields.NullUInt)) > ids[len(ids)-1])
+
+	_, err = generator.Generate(fields.String(""))
+	testutils.AssertErrorEquals(t, fields.GeneratorTypeError, err)
+}
+
+func TestGeneratorFor(t *testing.T) {
+	custom := fields.GeneratorFunc(func(emptyValue any) (any, error) {
+		return "custom", nil
+	})
+
+	settings := fields.NewFieldSettings(
+		fields.WithAutoGenerate("id"),
+		fields.WithGenerator("custom", custom),
+		fields.WithNumBytes("plain", 31),
+	)
+
+	testutils.AssertTrue(t, settings["id"].GeneratorFor(fields.BigInt(0)) == fields.AutoIncrement)
+	testutils.AssertTrue(t, settings["id"].IsAutoIncrement(fields.Int(0)))
+	testutils.AssertTrue(t, settings["id"].GeneratorFor(fields.String("")) == fields.UUIDv4)
+	testutils.AssertTrue(t, settings["id"].GeneratorFor(fields.Float(0)) == nil)
+	testutils.AssertTrue(t, settings["custom"].AutoGenerate)
+	testutils.AssertTrue(t, !settings["custom"].IsAutoIncrement(fields.Int(0)))
+	testutils.AssertTrue(t, settings["plain"].GeneratorFor(fields.String("")) == nil)
+	testutils.AssertTrue(t, settings["missing"].GeneratorFor(fields.String("")) == nil)
+
+	value, err := settings["custom"].GeneratorFor(fields.String("")).Generate(fields.String(""))
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, value == "custom")
+
+	_, err = fields.AutoIncrement.Generate(fields.Int(0))
+	testutils.AssertTrue(t, errors.Is(err, fields.BackendGeneratedError))
+}
@@ -0,0 +1,96 @@
+package fields
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+// generatorsByName are the generators which can be named in a ds tag
+var generatorsByName = map[string]Generator{
+	"autoincrement": AutoIncrement,
+	"uuidv4":        UUIDv4,
+	"uuidv7":        UUIDv7,
+	"ulid":          ULID,
+}
+
+func applyTagOption(setting *FieldSetting, option string) error {
+	name, value, hasValue := strings.Cut(strings.TrimSpace(option), "=")
+
+	switch {
+	case name == "bytes" && hasValue:
+		numBytes, err := strconv.Atoi(value)
+		if err != nil || numBytes < 0 {
+			return InvalidTagError
+		}
+
+		setting.NumBytes = numBytes
+	case name == "autogen" && !hasValue:
+		setting.AutoGenerate = true
+	case name == "generator" && hasValue:
+		generator, ok := generatorsByName[strings.ToLower(value)]
+		if !ok {
+			return InvalidTagError
+		}
+
+		setting.AutoGenerate = true
+		setting.Generator = generator
+	case name == "version" && !hasValue:
+		setting.Version = true
+	default:
+		return InvalidTagError
+	}
+
+	return nil
+}
+
+// TaggedRowSettings derives RowSettings from the ds tags of the struct T,
+// with its fields in the order they are declared. The options after the
+// field name in a tag set its FieldSetting:
+//
+//	bytes=N      sets NumBytes to N
+//	autogen      auto generates the field with the default for its type
+//	generator=G  auto generates the field with autoincrement, uuidv4,
+//	             uuidv7 or ulid
+//	version      marks the field as the row's version
+func TaggedRowSettings[T any]() (*RowSettings, error) {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	if structType.Kind() != reflect.Struct {
+		return nil, TaggedTypeError
+	}
+
+	settings := &RowSettings{
+		FieldSettings: FieldSettings{},
+		FieldOrder:    OrderedFieldKeys{},
+	}
+
+	for _, taggedField := range mutator.TaggedFields(structType) {
+		settings.FieldOrder = append(settings.FieldOrder, taggedField.Name)
+		if len(taggedField.Options) == 0 {
+			continue
+		}
+
+		setting := settingForFieldName(settings.FieldSettings, taggedField.Name)
+		for _, option := range taggedField.Options {
+			if err := applyTagOption(setting, option); err != nil {
+				return nil, fmt.Errorf("%s: %q: %w", taggedField.Name, option, err)
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+// MustTaggedRowSettings is TaggedRowSettings for package level settings,
+// which panics if a tag is invalid
+func MustTaggedRowSettings[T any]() *RowSettings {
+	settings, err := TaggedRowSettings[T]()
+	if err != nil {
+		panic(err)
+	}
+
+	return settings
+}
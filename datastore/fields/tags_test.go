@@ -0,0 +1,72 @@
+package fields_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/examples/product"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type MockTaggedRow struct {
+	Id       fields.String `ds:"Id,bytes=63,generator=uuidv7"`
+	Sequence fields.BigInt `ds:",autogen"`
+	Version  fields.Int    `ds:"Version, version"`
+	Untagged fields.Float
+	Skipped  fields.String `ds:"-"`
+	unlisted fields.String
+}
+
+type MockInvalidTaggedRow struct {
+	Id fields.String `ds:"Id,bytes=many"`
+}
+
+type MockUnknownTaggedRow struct {
+	Id fields.String `ds:"Id,generator=random"`
+}
+
+func TestTaggedRowSettings(t *testing.T) {
+	settings, err := fields.TaggedRowSettings[MockTaggedRow]()
+	testutils.AssertOk(t, err)
+
+	expectedOrder := fields.OrderedFieldKeys{"Id", "Sequence", "Version", "Untagged"}
+	testutils.AssertEquals(t, len(expectedOrder), len(settings.FieldOrder))
+	for i := range settings.FieldOrder {
+		testutils.AssertEquals(t, expectedOrder[i], settings.FieldOrder[i])
+	}
+
+	testutils.AssertEquals(t, 3, len(settings.FieldSettings))
+	testutils.AssertEquals(t, 63, settings.FieldSettings["Id"].NumBytes)
+	testutils.AssertTrue(t, settings.FieldSettings["Id"].GeneratorFor(fields.String("")) == fields.UUIDv7)
+	testutils.AssertTrue(t, settings.FieldSettings["Sequence"].IsAutoIncrement(fields.BigInt(0)))
+	testutils.AssertTrue(t, settings.FieldSettings["Version"].Version)
+	testutils.AssertEquals(t, "Version", fields.VersionFieldName(settings))
+
+	_, err = fields.TaggedRowSettings[MockInvalidTaggedRow]()
+	testutils.AssertErrorEquals(t, fields.InvalidTagError, err)
+
+	_, err = fields.TaggedRowSettings[MockUnknownTaggedRow]()
+	testutils.AssertErrorEquals(t, fields.InvalidTagError, err)
+
+	_, err = fields.TaggedRowSettings[fields.String]()
+	testutils.AssertErrorEquals(t, fields.TaggedTypeError, err)
+}
+
+func TestTaggedRowSettingsMatchMutator(t *testing.T) {
+	data := &product.Data{}
+	values := data.Mutator().GetFields()
+
+	testutils.AssertEquals(t, len(values), len(product.DataSettings.FieldOrder))
+	for _, fieldName := range product.DataSettings.FieldOrder {
+		_, ok := values[fieldName]
+		testutils.AssertTrue(t, ok)
+	}
+	testutils.AssertEquals(t, 63, product.DataSettings.FieldSettings["Department"].NumBytes)
+	testutils.AssertEquals(t, 255, product.KeySettings.FieldSettings["Name"].NumBytes)
+
+	defer func() {
+		testutils.AssertTrue(t, errors.Is(recover().(error), fields.InvalidTagError))
+	}()
+	fields.MustTaggedRowSettings[MockInvalidTaggedRow]()
+}
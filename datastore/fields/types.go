@@ -21,6 +21,40 @@ type NullBigInt = *int64
 type BigUInt = uint64
 type NullBigUInt = *uint64
 
+// IntValue converts value to the type of an int field with emptyValue, or
+// returns GeneratorTypeError if it is not an int field
+func IntValue(emptyValue any, value int64) (any, error) {
+	switch emptyValue.(type) {
+	case Int:
+		return Int(value), nil
+	case NullInt:
+		converted := Int(value)
+		return &converted, nil
+	case UInt:
+		return UInt(value), nil
+	case NullUInt:
+		converted := UInt(value)
+		return &converted, nil
+	case BigInt:
+		return value, nil
+	case NullBigInt:
+		return &value, nil
+	case BigUInt:
+		return BigUInt(value), nil
+	case NullBigUInt:
+		converted := BigUInt(value)
+		return &converted, nil
+	default:
+		return nil, GeneratorTypeError
+	}
+}
+
+// IsIntField reports whether emptyValue is the empty value of an int field
+func IsIntField(emptyValue any) bool {
+	_, err := IntValue(emptyValue, 0)
+	return err == nil
+}
+
 type FloatField interface {
 	SmallFloat | NullSmallFloat | Float | NullFloat
 }
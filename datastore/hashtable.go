@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
 )
@@ -8,16 +9,32 @@ import (
 type HashTable[K any, PK mutator.Mutatable[K], E any, PE mutator.Mutatable[E]] struct {
 	Settings *TableSettings
 	*queries.Scanable[E, PE]
+	*queries.Aggregatable[E, PE]
 	*queries.Countable
 	*queries.CRUDable[K, PK, E, PE]
+	*queries.Indexable[E, PE]
 	*queries.Transferable[E, PE]
 }
 
 func (t *HashTable[K, PK, E, PE]) Init() {
 	t.Settings.ApplyOption(WithEntry[E, PE]())
+	t.Settings.ApplyOption(withKey[K, PK]())
 	t.Scanable = &queries.Scanable[E, PE]{}
+	t.Aggregatable = &queries.Aggregatable[E, PE]{}
 	t.Countable = &queries.Countable{}
-	t.CRUDable = &queries.CRUDable[K, PK, E, PE]{}
+	generators := t.Settings.Generators()
+	t.CRUDable = &queries.CRUDable[K, PK, E, PE]{
+		Addable: queries.Addable[E, PE]{
+			Generators: generators,
+		},
+		Updateable: queries.Updateable[E, PE]{
+			VersionFieldName: fields.VersionFieldName(t.Settings.DataSettings),
+		},
+		Upsertable: queries.Upsertable[E, PE]{
+			Generators: generators,
+		},
+	}
+	t.Indexable = &queries.Indexable[E, PE]{}
 	t.Transferable = &queries.Transferable[E, PE]{
 		Scanable: t.Scanable,
 		Addable:  &t.CRUDable.Addable,
@@ -30,6 +47,8 @@ func (t *HashTable[K, PK, E, PE]) GetSettings() *TableSettings {
 
 func (t *HashTable[K, PK, E, PE]) SetBackend(tableBackend HashTableBackendQueries) {
 	t.Scanable.SetBackend(tableBackend)
+	t.Aggregatable.SetBackend(tableBackend)
 	t.Countable.SetBackend(tableBackend)
 	t.CRUDable.SetBackend(tableBackend)
+	t.Indexable.SetBackend(tableBackend)
 }
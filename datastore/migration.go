@@ -0,0 +1,130 @@
+package datastore
+
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+type MigrationOperationType = int8
+
+const (
+	ADD_FIELD MigrationOperationType = iota
+	ALTER_FIELD
+	DROP_FIELD
+	ALTER_KEY
+	MOVE_FIELD
+)
+
+// MigrationOperation is one change to the stored schema of a table
+type MigrationOperation struct {
+	Type      MigrationOperationType
+	FieldName string
+	Statement string
+}
+
+// MigrationPlan lists the operations which bring the stored schema of a table
+// in line with its settings, in the order they are applied
+type MigrationPlan struct {
+	TableName  string
+	Operations []*MigrationOperation
+}
+
+// Migrator is implemented by table backends which can change the schema of
+// a registered table
+type Migrator interface {
+	// PlanMigration compares the table settings to the stored schema. The
+	// plan is empty if they match or the table does not exist, and it fails
+	// with MigrationNotSupportedError if a change cannot be made.
+	PlanMigration(ctx context.Context) (*MigrationPlan, error)
+	// ApplyMigration runs the operations of plan and records them in the
+	// migration history
+	ApplyMigration(ctx context.Context, plan *MigrationPlan) error
+	// MigrationHistory returns the migrations applied to the table, oldest
+	// first
+	MigrationHistory(ctx context.Context) ([]*MigrationRecord, error)
+}
+
+const (
+	MigrationHistoryTableName = "_Migrations"
+
+	MigrationIdKey         = "_MigrationId"
+	MigrationTableNameKey  = "_MigrationTableName"
+	MigrationStatementsKey = "_MigrationStatements"
+	MigrationAppliedAtKey  = "_MigrationAppliedAt"
+)
+
+// MigrationRecord is a plan which has been applied, stored in the migration
+// history table shared by every table on a connection
+type MigrationRecord struct {
+	Id         fields.BigInt
+	TableName  fields.String
+	Statements fields.JsonList
+	AppliedAt  fields.Time
+}
+
+func (r *MigrationRecord) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(MigrationIdKey, &r.Id),
+		mutator.WithAddress(MigrationTableNameKey, &r.TableName),
+		mutator.WithAddress(MigrationStatementsKey, &r.Statements),
+		mutator.WithAddress(MigrationAppliedAtKey, &r.AppliedAt),
+	)
+}
+
+// NewMigrationHistoryTable creates the table backends record applied
+// migrations in, which must be registered before it is used
+func NewMigrationHistoryTable() *AppendTable[MigrationRecord, *MigrationRecord] {
+	return &AppendTable[MigrationRecord, *MigrationRecord]{
+		Settings: NewTableSettings(
+			WithTableName(MigrationHistoryTableName),
+			WithDataSettings(&fields.RowSettings{
+				FieldSettings: fields.NewFieldSettings(
+					fields.WithAutoGenerate(MigrationIdKey),
+					fields.WithNumBytes(MigrationTableNameKey, 255),
+				),
+				FieldOrder: fields.OrderedFieldKeys{
+					MigrationIdKey,
+					MigrationTableNameKey,
+					MigrationStatementsKey,
+					MigrationAppliedAtKey,
+				},
+			}),
+		),
+	}
+}
+
+type MigrateOptions struct {
+	DryRun bool
+}
+
+// WithDryRun plans a migration without applying it
+func WithDryRun() func(*MigrateOptions) {
+	return func(options *MigrateOptions) {
+		options.DryRun = true
+	}
+}
+
+// Migrate plans and applies the changes to the schema of the table
+// registered with tableBackend, returning the plan
+func Migrate(ctx context.Context, tableBackend any, options ...func(*MigrateOptions)) (*MigrationPlan, error) {
+	migrator, ok := tableBackend.(Migrator)
+	if !ok {
+		return nil, MigrationNotSupportedError
+	}
+
+	migrateOptions := &MigrateOptions{}
+	for _, option := range options {
+		option(migrateOptions)
+	}
+
+	plan, err := migrator.PlanMigration(ctx)
+	if err != nil {
+		return nil, err
+	} else if migrateOptions.DryRun || len(plan.Operations) == 0 {
+		return plan, nil
+	}
+
+	return plan, migrator.ApplyMigration(ctx, plan)
+}
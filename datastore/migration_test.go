@@ -0,0 +1,70 @@
+package datastore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type MockMigrator struct {
+	PlanRval *datastore.MigrationPlan
+	Applied  []*datastore.MigrationPlan
+}
+
+func (m *MockMigrator) PlanMigration(ctx context.Context) (*datastore.MigrationPlan, error) {
+	return m.PlanRval, nil
+}
+
+func (m *MockMigrator) ApplyMigration(ctx context.Context, plan *datastore.MigrationPlan) error {
+	m.Applied = append(m.Applied, plan)
+	return nil
+}
+
+func (m *MockMigrator) MigrationHistory(ctx context.Context) ([]*datastore.MigrationRecord, error) {
+	return nil, nil
+}
+
+func TestMigrate(t *testing.T) {
+	newMigrator := func(numOperations int) *MockMigrator {
+		plan := &datastore.MigrationPlan{TableName: "Test"}
+		for i := 0; i < numOperations; i += 1 {
+			plan.Operations = append(plan.Operations, &datastore.MigrationOperation{
+				Type:      datastore.ADD_FIELD,
+				FieldName: "Field",
+				Statement: "mock statement",
+			})
+		}
+
+		return &MockMigrator{PlanRval: plan}
+	}
+
+	testutils.Case(t, "applies the plan", func(t *testing.T) {
+		migrator := newMigrator(2)
+		plan, err := datastore.Migrate(context.Background(), migrator)
+		testutils.AssertOk(t, err)
+		testutils.AssertTrue(t, plan == migrator.PlanRval)
+		testutils.AssertEquals(t, 1, len(migrator.Applied))
+	})
+
+	testutils.Case(t, "dry run only plans", func(t *testing.T) {
+		migrator := newMigrator(2)
+		plan, err := datastore.Migrate(context.Background(), migrator, datastore.WithDryRun())
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 2, len(plan.Operations))
+		testutils.AssertEquals(t, 0, len(migrator.Applied))
+	})
+
+	testutils.Case(t, "does not apply an empty plan", func(t *testing.T) {
+		migrator := newMigrator(0)
+		_, err := datastore.Migrate(context.Background(), migrator)
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 0, len(migrator.Applied))
+	})
+
+	testutils.Case(t, "requires a migrator", func(t *testing.T) {
+		_, err := datastore.Migrate(context.Background(), &MockConnection{})
+		testutils.AssertErrorEquals(t, datastore.MigrationNotSupportedError, err)
+	})
+}
@@ -0,0 +1,90 @@
+package mutator
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag which names the fields of tagged structs
+const TagName = "ds"
+
+// TaggedField is an exported field of a tagged struct, named by its ds tag
+// or its struct field name
+type TaggedField struct {
+	Name    string
+	Index   int
+	Type    reflect.Type
+	Options []string
+}
+
+var taggedFieldsCache sync.Map
+
+// TaggedFields returns the fields of a struct type in the order they are
+// declared, skipping unexported fields and fields tagged `ds:"-"`
+func TaggedFields(structType reflect.Type) []*TaggedField {
+	if cached, ok := taggedFieldsCache.Load(structType); ok {
+		return cached.([]*TaggedField)
+	}
+
+	taggedFields := []*TaggedField{}
+	for i := 0; i < structType.NumField(); i += 1 {
+		structField := structType.Field(i)
+		tag := structField.Tag.Get(TagName)
+		if !structField.IsExported() || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		taggedField := &TaggedField{
+			Name:    strings.TrimSpace(parts[0]),
+			Index:   i,
+			Type:    structField.Type,
+			Options: parts[1:],
+		}
+		if taggedField.Name == "" {
+			taggedField.Name = structField.Name
+		}
+
+		taggedFields = append(taggedFields, taggedField)
+	}
+
+	taggedFieldsCache.Store(structType, taggedFields)
+	return taggedFields
+}
+
+// NewTaggedFieldMutator creates a FieldMutator for the struct address points
+// to from its TaggedFields, so a Mutator method can be written as
+//
+//	func (d *Data) Mutator() *mutator.FieldMutator {
+//		return mutator.NewTaggedFieldMutator(d)
+//	}
+//
+// It panics if address is not a pointer to a struct.
+func NewTaggedFieldMutator(address any) *FieldMutator {
+	addressValue := reflect.ValueOf(address)
+	if addressValue.Kind() != reflect.Pointer || addressValue.Elem().Kind() != reflect.Struct {
+		panic("mutator: NewTaggedFieldMutator requires a pointer to a struct")
+	}
+
+	structValue := addressValue.Elem()
+	m := NewFieldMutator()
+
+	for _, taggedField := range TaggedFields(structValue.Type()) {
+		fieldValue := structValue.Field(taggedField.Index)
+		fieldType := taggedField.Type
+
+		m.fieldSetters[taggedField.Name] = func(value any) error {
+			if value == nil || reflect.TypeOf(value) != fieldType {
+				return SetFieldTypeError
+			}
+
+			fieldValue.Set(reflect.ValueOf(value))
+			return nil
+		}
+
+		m.fieldGetters[taggedField.Name] = fieldValue.Interface
+	}
+
+	return m
+}
@@ -0,0 +1,72 @@
+package mutator_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type MockTaggedData struct {
+	Named    string   `ds:"1,bytes=63"`
+	Default  int      `ds:",autogen"`
+	Nullable *float32 `ds:"3"`
+	Skipped  string   `ds:"-"`
+	Untagged bool
+	private  string
+}
+
+func (d *MockTaggedData) Mutator() *mutator.FieldMutator {
+	return mutator.NewTaggedFieldMutator(d)
+}
+
+func TestTaggedFields(t *testing.T) {
+	taggedFields := mutator.TaggedFields(reflect.TypeOf(MockTaggedData{}))
+
+	expectedNames := []string{"1", "Default", "3", "Untagged"}
+	testutils.AssertEquals(t, len(expectedNames), len(taggedFields))
+	for i, taggedField := range taggedFields {
+		testutils.AssertEquals(t, expectedNames[i], taggedField.Name)
+	}
+
+	testutils.AssertEquals(t, 1, len(taggedFields[0].Options))
+	testutils.AssertEquals(t, "bytes=63", taggedFields[0].Options[0])
+	testutils.AssertEquals(t, 0, len(taggedFields[3].Options))
+}
+
+func TestTaggedFieldMutator(t *testing.T) {
+	value := float32(1.5)
+	mockData := &MockTaggedData{Named: "test", Default: 1, Skipped: "skipped", private: "private"}
+	m := mockData.Mutator()
+
+	testutils.Case(t, "gets tagged fields", func(t *testing.T) {
+		fields := m.GetFields()
+		testutils.AssertEquals(t, 4, len(fields))
+		testutils.AssertTrue(t, fields["1"] == "test")
+		testutils.AssertTrue(t, fields["Default"] == 1)
+		testutils.AssertTrue(t, fields["3"] == (*float32)(nil))
+		testutils.AssertTrue(t, fields["Untagged"] == false)
+	})
+
+	testutils.Case(t, "sets tagged fields", func(t *testing.T) {
+		err := m.SetFields(mutator.MappedFieldValues{
+			"1":        "updated",
+			"Default":  2,
+			"3":        &value,
+			"Untagged": true,
+		})
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "updated", mockData.Named)
+		testutils.AssertEquals(t, 2, mockData.Default)
+		testutils.AssertEquals(t, &value, mockData.Nullable)
+		testutils.AssertTrue(t, mockData.Untagged)
+		testutils.AssertTrue(t, m.GetField("1") == "updated")
+	})
+
+	testutils.Case(t, "returns error for wrong type", func(t *testing.T) {
+		testutils.AssertErrorEquals(t, mutator.SetFieldTypeError, m.SetField("1", 1))
+		testutils.AssertErrorEquals(t, mutator.SetFieldTypeError, m.SetField("3", nil))
+		testutils.AssertEquals(t, "updated", mockData.Named)
+	})
+}
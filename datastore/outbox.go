@@ -0,0 +1,291 @@
+package datastore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+const (
+	OutboxIdKey          = "_OutboxId"
+	OutboxDestinationKey = "_OutboxDestination"
+)
+
+type OutboxKey struct {
+	OutboxId fields.String
+}
+
+func (k *OutboxKey) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(OutboxIdKey, &k.OutboxId),
+	)
+}
+
+var outboxKeySettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithNumBytes(OutboxIdKey, 63),
+	),
+	FieldOrder: fields.OrderedFieldKeys{OutboxIdKey},
+}
+
+// OutboxEntry is a message waiting in an Outbox
+type OutboxEntry[M any, PM mutator.Mutatable[M]] struct {
+	OutboxId    fields.String
+	Destination fields.String
+	Message     PM
+	factory     mutator.MutatableFactory[M, PM]
+	mutator     *mutator.FieldMutator
+}
+
+func (e *OutboxEntry[M, PM]) Mutator() *mutator.FieldMutator {
+	if e.Message == nil {
+		e.Message = e.factory.Create()
+	}
+
+	if e.mutator == nil {
+		e.mutator = mutator.MergeFieldMutators(
+			mutator.NewFieldMutator(
+				mutator.WithAddress(OutboxIdKey, &e.OutboxId),
+				mutator.WithAddress(OutboxDestinationKey, &e.Destination),
+			),
+			e.Message.Mutator(),
+		)
+	}
+
+	return e.mutator
+}
+
+// outboxDataSettings adds the outbox fields to the settings for a message
+func outboxDataSettings(messageSettings *fields.RowSettings) *fields.RowSettings {
+	settings := &fields.RowSettings{
+		FieldSettings: fields.NewFieldSettings(
+			fields.WithNumBytes(OutboxDestinationKey, 255),
+		),
+		FieldOrder: fields.OrderedFieldKeys{OutboxDestinationKey},
+	}
+
+	if messageSettings != nil {
+		for fieldName, setting := range messageSettings.FieldSettings {
+			settings.FieldSettings[fieldName] = setting
+		}
+
+		settings.FieldOrder = append(settings.FieldOrder, messageSettings.FieldOrder...)
+	}
+
+	return settings
+}
+
+// newOutboxIds creates n ids which sort in the order they were created
+func newOutboxIds(n int) []string {
+	now := time.Now().UnixNano()
+
+	var suffix uint64
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err == nil {
+		suffix = binary.BigEndian.Uint64(bytes)
+	} else {
+		suffix = uint64(now)
+	}
+
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%016x%08x%016x", now, i, suffix)
+	}
+
+	return ids
+}
+
+// Outbox stores messages for Queues and Topics in a HashTable, so they are
+// written in the same transaction as other tables, until Relay forwards them
+// at least once. Destinations are added with AddQueue or AddTopic.
+type Outbox[M any, PM mutator.Mutatable[M]] struct {
+	*HashTable[OutboxKey, *OutboxKey, OutboxEntry[M, PM], *OutboxEntry[M, PM]]
+	idFieldName    string
+	destinationsMu sync.RWMutex
+	destinations   map[string]func(ctx context.Context, messages ...PM) error
+}
+
+// NewOutbox creates an outbox stored in the table name, for messages with
+// messageSettings
+func NewOutbox[M any, PM mutator.Mutatable[M]](name string, messageSettings *fields.RowSettings, options ...func(*Outbox[M, PM])) *Outbox[M, PM] {
+	outbox := &Outbox[M, PM]{
+		HashTable: &HashTable[OutboxKey, *OutboxKey, OutboxEntry[M, PM], *OutboxEntry[M, PM]]{
+			Settings: NewTableSettings(
+				WithTableName(name),
+				WithKeySettings(outboxKeySettings),
+				WithDataSettings(outboxDataSettings(messageSettings)),
+			),
+		},
+		destinations: map[string]func(ctx context.Context, messages ...PM) error{},
+	}
+
+	for _, option := range options {
+		option(outbox)
+	}
+
+	return outbox
+}
+
+// WithOutboxIdField sets the String field fieldName of each message to its
+// outbox id when it is added, so consumers can recognise a message which is
+// forwarded more than once
+func WithOutboxIdField[M any, PM mutator.Mutatable[M]](fieldName string) func(*Outbox[M, PM]) {
+	return func(o *Outbox[M, PM]) {
+		o.idFieldName = fieldName
+	}
+}
+
+func (o *Outbox[M, PM]) addDestination(name string, send func(ctx context.Context, messages ...PM) error) {
+	o.destinationsMu.Lock()
+	defer o.destinationsMu.Unlock()
+	o.destinations[name] = send
+}
+
+func (o *Outbox[M, PM]) destination(name string) func(ctx context.Context, messages ...PM) error {
+	o.destinationsMu.RLock()
+	defer o.destinationsMu.RUnlock()
+	return o.destinations[name]
+}
+
+// AddQueue lets messages be sent to queue through the outbox, it is
+// identified by its table name
+func (o *Outbox[M, PM]) AddQueue(queue *Queue[M, PM]) {
+	o.addDestination(queue.Settings.Name, queue.SendMessageContext)
+}
+
+// AddTopic lets messages be published to topic through the outbox, it is
+// identified by its table name
+func (o *Outbox[M, PM]) AddTopic(topic *Topic[M, PM]) {
+	o.addDestination(topic.Settings.Name, topic.PublishContext)
+}
+
+func (o *Outbox[M, PM]) add(ctx context.Context, destination string, messages []PM) error {
+	if o.destination(destination) == nil {
+		return OutboxDestinationDoesNotExistError
+	}
+
+	outboxIds := newOutboxIds(len(messages))
+	entries := make([]*OutboxEntry[M, PM], len(messages))
+	for i, message := range messages {
+		if o.idFieldName != "" {
+			messageMutator := message.Mutator()
+			if _, ok := messageMutator.GetFields()[o.idFieldName]; !ok {
+				return FieldDoesNotExistError
+			} else if err := messageMutator.SetField(o.idFieldName, fields.String(outboxIds[i])); err != nil {
+				return err
+			}
+		}
+
+		entries[i] = &OutboxEntry[M, PM]{
+			OutboxId:    outboxIds[i],
+			Destination: destination,
+			Message:     message,
+		}
+	}
+
+	_, err := o.AddContext(ctx, entries...)
+	return err
+}
+
+// SendMessage stores messages in the outbox to be sent to queue
+func (o *Outbox[M, PM]) SendMessage(queue *Queue[M, PM], messages ...PM) error {
+	return o.SendMessageContext(context.Background(), queue, messages...)
+}
+
+func (o *Outbox[M, PM]) SendMessageContext(ctx context.Context, queue *Queue[M, PM], messages ...PM) error {
+	return o.add(ctx, queue.Settings.Name, messages)
+}
+
+// Publish stores messages in the outbox to be published to topic
+func (o *Outbox[M, PM]) Publish(topic *Topic[M, PM], messages ...PM) error {
+	return o.PublishContext(context.Background(), topic, messages...)
+}
+
+func (o *Outbox[M, PM]) PublishContext(ctx context.Context, topic *Topic[M, PM], messages ...PM) error {
+	return o.add(ctx, topic.Settings.Name, messages)
+}
+
+// forward sends entries, which all have the same destination, and then
+// deletes them from the outbox
+func (o *Outbox[M, PM]) forward(ctx context.Context, entries []*OutboxEntry[M, PM]) error {
+	send := o.destination(entries[0].Destination)
+	if send == nil {
+		return OutboxDestinationDoesNotExistError
+	}
+
+	messages := make([]PM, len(entries))
+	keys := make([]*OutboxKey, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+		keys[i] = &OutboxKey{OutboxId: entry.OutboxId}
+	}
+
+	if err := send(ctx, messages...); err != nil {
+		return err
+	}
+
+	return o.DeleteContext(ctx, keys...)
+}
+
+// Relay forwards every undelivered message in the outbox to its destination,
+// in batches of up to batchSize, and returns how many were forwarded
+func (o *Outbox[M, PM]) Relay(batchSize int) (int, error) {
+	return o.RelayContext(context.Background(), batchSize)
+}
+
+func (o *Outbox[M, PM]) RelayContext(ctx context.Context, batchSize int) (int, error) {
+	// pages are ordered by outbox id, which is the order messages were added
+	numForwarded := 0
+	cursor := ""
+	for {
+		pending, nextCursor, err := o.ScanPageContext(ctx, cursor, batchSize)
+		if err != nil {
+			return numForwarded, err
+		}
+
+		// consecutive messages for the same destination are sent together
+		for start := 0; start < len(pending); {
+			end := start + 1
+			for end < len(pending) && pending[end].Destination == pending[start].Destination {
+				end += 1
+			}
+
+			if err := o.forward(ctx, pending[start:end]); err != nil {
+				return numForwarded, err
+			}
+
+			numForwarded += end - start
+			start = end
+		}
+
+		if nextCursor == "" {
+			return numForwarded, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// RunRelay calls Relay every interval until ctx is done, returning the
+// context error, or the first error from relaying
+func (o *Outbox[M, PM]) RunRelay(ctx context.Context, interval time.Duration, batchSize int) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := o.RelayContext(ctx, batchSize); err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
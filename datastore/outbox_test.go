@@ -0,0 +1,188 @@
+package datastore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/examples/shipping"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type shippingOutbox = datastore.Outbox[shipping.Message, *shipping.Message]
+
+const (
+	trackedOutboxIdKey = "OutboxId"
+	trackedNameKey     = "Name"
+)
+
+type trackedMessage struct {
+	OutboxId fields.String
+	Name     fields.String
+}
+
+func (m *trackedMessage) Mutator() *mutator.FieldMutator {
+	return mutator.NewFieldMutator(
+		mutator.WithAddress(trackedOutboxIdKey, &m.OutboxId),
+		mutator.WithAddress(trackedNameKey, &m.Name),
+	)
+}
+
+var trackedMessageSettings = &fields.RowSettings{
+	FieldSettings: fields.NewFieldSettings(
+		fields.WithNumBytes(trackedOutboxIdKey, 63),
+		fields.WithNumBytes(trackedNameKey, 63),
+	),
+	FieldOrder: fields.OrderedFieldKeys{trackedOutboxIdKey, trackedNameKey},
+}
+
+func newShippingOutbox(t *testing.T) (*datastore.ConnectionGroup[*inmemory.Connection], *shippingOutbox, *shipping.Queue, *shipping.Topic) {
+	t.Helper()
+
+	outbox := datastore.NewOutbox[shipping.Message]("ShippingOutbox", shipping.DataSettings)
+	queue := shipping.NewPendingShipmentQueue()
+	topic := shipping.NewShippedTopic()
+	group := datastore.NewConnectionGroup(
+		datastore.WithConnection(inmemory.NewConnection()),
+	)
+	err := group.RegisterTables(
+		datastore.RegisterHashTable[*inmemory.Connection](outbox, &inmemory.HashTableBackend{}),
+		datastore.RegisterQueue[*inmemory.Connection](queue, &inmemory.QueueBackend{}),
+		datastore.RegisterTopic[*inmemory.Connection](topic, &inmemory.TopicBackend{}),
+	)
+	testutils.AssertOk(t, err)
+
+	outbox.AddQueue(queue)
+	outbox.AddTopic(topic)
+	return group, outbox, queue, topic
+}
+
+func assertRelays(t *testing.T, outbox *shippingOutbox, expected int) {
+	t.Helper()
+
+	numForwarded, err := outbox.Relay(2)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, expected, numForwarded)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	testutils.Case(t, "forwards messages once", func(t *testing.T) {
+		_, outbox, queue, topic := newShippingOutbox(t)
+		subscription, err := topic.Subscribe("test")
+		testutils.AssertOk(t, err)
+
+		testutils.AssertOk(t, outbox.SendMessage(queue, newShippingMessage("queued1"), newShippingMessage("queued2"), newShippingMessage("queued3")))
+		testutils.AssertOk(t, outbox.Publish(topic, newShippingMessage("published")))
+
+		// nothing is sent until the outbox is relayed
+		count, err := queue.Count()
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 0, count)
+
+		assertRelays(t, outbox, 4)
+		assertRelays(t, outbox, 0)
+
+		// forwarded messages are removed from the outbox
+		count, err = outbox.Count()
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 0, count)
+
+		for _, expected := range []string{"queued1", "queued2", "queued3"} {
+			_, message, err := queue.RecieveMessage()
+			testutils.AssertOk(t, err)
+			testutils.AssertEquals(t, expected, message.Data.Name)
+		}
+
+		id := assertRecievesMessage(t, subscription, "published")
+		testutils.AssertOk(t, subscription.AckSuccess(id))
+	})
+
+	testutils.Case(t, "only forwards messages from committed transactions", func(t *testing.T) {
+		group, outbox, queue, _ := newShippingOutbox(t)
+		mockErr := errors.New("mock error")
+
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			if err := outbox.SendMessageContext(ctx, queue, newShippingMessage("rolledback")); err != nil {
+				return err
+			}
+
+			return mockErr
+		})
+		testutils.AssertErrorEquals(t, mockErr, err)
+
+		err = group.WithTx(context.Background(), func(ctx context.Context) error {
+			return outbox.SendMessageContext(ctx, queue, newShippingMessage("committed"))
+		})
+		testutils.AssertOk(t, err)
+
+		assertRelays(t, outbox, 1)
+		_, message, err := queue.RecieveMessage()
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "committed", message.Data.Name)
+	})
+
+	testutils.Case(t, "sets the outbox id of forwarded messages", func(t *testing.T) {
+		outbox := datastore.NewOutbox[trackedMessage]("TrackedOutbox", trackedMessageSettings, datastore.WithOutboxIdField[trackedMessage, *trackedMessage](trackedOutboxIdKey))
+		queue := &datastore.Queue[trackedMessage, *trackedMessage]{
+			Settings: datastore.NewTableSettings(
+				datastore.WithTableName("Tracked"),
+				datastore.WithDataSettings(trackedMessageSettings),
+			),
+		}
+		group := datastore.NewConnectionGroup(
+			datastore.WithConnection(inmemory.NewConnection()),
+		)
+		err := group.RegisterTables(
+			datastore.RegisterHashTable[*inmemory.Connection](outbox, &inmemory.HashTableBackend{}),
+			datastore.RegisterQueue[*inmemory.Connection](queue, &inmemory.QueueBackend{}),
+		)
+		testutils.AssertOk(t, err)
+		outbox.AddQueue(queue)
+
+		sent := []*trackedMessage{{Name: "tracked1"}, {Name: "tracked2"}}
+		testutils.AssertOk(t, outbox.SendMessage(queue, sent...))
+		testutils.AssertTrue(t, sent[0].OutboxId != "" && sent[0].OutboxId != sent[1].OutboxId)
+
+		numForwarded, err := outbox.Relay(1)
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 2, numForwarded)
+
+		for _, expected := range sent {
+			_, message, err := queue.RecieveMessage()
+			testutils.AssertOk(t, err)
+			testutils.AssertEquals(t, expected.OutboxId, message.OutboxId)
+		}
+	})
+
+	testutils.Case(t, "returns error for unknown destination", func(t *testing.T) {
+		_, outbox, _, _ := newShippingOutbox(t)
+		other := shipping.NewPendingShipmentQueue()
+		other.Settings.Name = "Other"
+
+		err := outbox.SendMessage(other, newShippingMessage("unknown"))
+		testutils.AssertErrorEquals(t, datastore.OutboxDestinationDoesNotExistError, err)
+	})
+}
+
+func TestOutboxRunRelay(t *testing.T) {
+	_, outbox, queue, _ := newShippingOutbox(t)
+	testutils.AssertOk(t, outbox.SendMessage(queue, newShippingMessage("relayed")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- outbox.RunRelay(ctx, 10*time.Millisecond, 10)
+	}()
+
+	_, message, err := queue.RecieveMessageWait(5 * time.Second)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, "relayed", message.Data.Name)
+
+	cancel()
+	testutils.AssertErrorEquals(t, context.Canceled, <-done)
+}
@@ -1,12 +1,18 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+	"errors"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type AddableBackend interface {
-	Add(entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
+	Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
 }
 
 type Addable[E any, PE mutator.Mutatable[E]] struct {
+	Generators   *Generators
 	backend      AddableBackend
 	entryFactory mutator.MutatableFactory[E, PE]
 }
@@ -16,13 +22,32 @@ func (a *Addable[E, PE]) SetBackend(tableBackend AddableBackend) {
 }
 
 func (a *Addable[E, PE]) Add(entries ...PE) ([]PE, error) {
-	entryFieldsList, err := a.backend.Add(
-		a.entryFactory.CreateFieldValuesList(entries),
-	)
+	return a.AddContext(context.Background(), entries...)
+}
+
+// AddContext returns the entries already added along with a BatchError
+func (a *Addable[E, PE]) AddContext(ctx context.Context, entries ...PE) ([]PE, error) {
+	entryFieldsList := a.entryFactory.CreateFieldValuesList(entries)
+	if err := a.Generators.generate(entryFieldsList); err != nil {
+		return nil, err
+	}
+
+	added := make([]mutator.MappedFieldValues, 0, len(entryFieldsList))
+	err := inBatches(a.backend, len(entryFieldsList), func(start, end int) error {
+		batch, err := a.backend.Add(ctx, entryFieldsList[start:end])
+		added = append(added, batch...)
+		return err
+	})
 
-	if err != nil {
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
 		return nil, err
 	}
 
-	return a.entryFactory.CreateFromFieldsList(entryFieldsList)
+	entries, createErr := a.entryFactory.CreateFromFieldsList(added)
+	if createErr != nil {
+		return nil, createErr
+	}
+
+	return entries, err
 }
@@ -1,9 +1,11 @@
 package queries_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
 	"github.com/sophielizg/go-libs/datastore/queries/queriestest"
@@ -16,7 +18,7 @@ type MockAddableBackend struct {
 	EntriesInput []mutator.MappedFieldValues
 }
 
-func (b *MockAddableBackend) Add(entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+func (b *MockAddableBackend) Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
 	b.EntriesInput = entries
 	return b.EntriesRval, b.ErrorRval
 }
@@ -153,3 +155,33 @@ func TestAdd(t *testing.T) {
 
 	tests.Run(t)
 }
+
+func TestAddGenerators(t *testing.T) {
+	backend := &MockAddableBackend{}
+	addable := queries.Addable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{
+		Generators: &queries.Generators{
+			FieldGenerators: map[string]fields.Generator{
+				queriestest.DataKey: fields.GeneratorFunc(func(emptyValue any) (any, error) {
+					return "generated", nil
+				}),
+			},
+			EmptyValues: mutator.MappedFieldValues{queriestest.DataKey: ""},
+		},
+	}
+	addable.SetBackend(backend)
+
+	_, err := addable.Add(
+		&queriestest.MockNonKeyedEntry{Data: &queriestest.MockData{}},
+		&queriestest.MockNonKeyedEntry{Data: &queriestest.MockData{Data: "set"}},
+	)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(backend.EntriesInput))
+	if len(backend.EntriesInput) == 2 {
+		testutils.AssertTrue(t, backend.EntriesInput[0][queriestest.DataKey] == "generated")
+		testutils.AssertTrue(t, backend.EntriesInput[1][queriestest.DataKey] == "set")
+	}
+
+	addable.Generators.FieldGenerators[queriestest.DataKey] = fields.NewSnowflake(0)
+	_, err = addable.Add(&queriestest.MockNonKeyedEntry{Data: &queriestest.MockData{}})
+	testutils.AssertErrorEquals(t, fields.GeneratorTypeError, err)
+}
@@ -0,0 +1,176 @@
+package queries
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+type AggregateFunc = int8
+
+const (
+	COUNT AggregateFunc = iota
+	SUM
+	MIN
+	MAX
+	AVG
+)
+
+// Aggregate is a function computed over the field named FieldName of every
+// entry in a group, stored in the results under Name
+type Aggregate struct {
+	Func      AggregateFunc
+	FieldName string
+	Name      string
+}
+
+// Count counts the entries in each group, its result is a fields.Int
+func Count() *Aggregate {
+	return &Aggregate{Func: COUNT, Name: "Count"}
+}
+
+// Sum adds the values of a numeric field, its result has the field's type
+func Sum(fieldName string) *Aggregate {
+	return &Aggregate{Func: SUM, FieldName: fieldName, Name: "Sum" + fieldName}
+}
+
+// Min finds the lowest value of a numeric or time field, its result has the
+// field's type
+func Min(fieldName string) *Aggregate {
+	return &Aggregate{Func: MIN, FieldName: fieldName, Name: "Min" + fieldName}
+}
+
+// Max finds the highest value of a numeric or time field, its result has the
+// field's type
+func Max(fieldName string) *Aggregate {
+	return &Aggregate{Func: MAX, FieldName: fieldName, Name: "Max" + fieldName}
+}
+
+// Avg averages the values of a numeric field, its result is a fields.Float
+func Avg(fieldName string) *Aggregate {
+	return &Aggregate{Func: AVG, FieldName: fieldName, Name: "Avg" + fieldName}
+}
+
+// EmptyResult returns the result for a group with no entries, or false if
+// the type of emptyValue is not supported
+func (a *Aggregate) EmptyResult(emptyValue any) (any, bool) {
+	if a.Func == COUNT {
+		return fields.Int(0), true
+	}
+
+	isNumeric := false
+	switch emptyValue.(type) {
+	case fields.Int, fields.UInt, fields.BigInt, fields.BigUInt, fields.SmallFloat, fields.Float:
+		isNumeric = true
+	case fields.Time:
+		if a.Func != MIN && a.Func != MAX {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	switch a.Func {
+	case SUM, MIN, MAX:
+		return reflect.Zero(reflect.TypeOf(emptyValue)).Interface(), true
+	case AVG:
+		return fields.Float(0), isNumeric
+	default:
+		return nil, false
+	}
+}
+
+// AggregateOptions filter the entries which are aggregated and group them
+// by the GroupBy fields
+type AggregateOptions struct {
+	Filter  *compare.Filter
+	GroupBy []string
+}
+
+func NewAggregateOptions(options ...func(*AggregateOptions)) *AggregateOptions {
+	aggregateOptions := &AggregateOptions{}
+
+	for _, option := range options {
+		option(aggregateOptions)
+	}
+
+	return aggregateOptions
+}
+
+func WithFilter(filter *compare.Filter) func(*AggregateOptions) {
+	return func(options *AggregateOptions) {
+		options.Filter = filter
+	}
+}
+
+func WithGroupBy(fieldNames ...string) func(*AggregateOptions) {
+	return func(options *AggregateOptions) {
+		options.GroupBy = append(options.GroupBy, fieldNames...)
+	}
+}
+
+// AggregateFields holds the values of the group by fields of one group, and
+// the result of each aggregate by name
+type AggregateFields struct {
+	Group  mutator.MappedFieldValues
+	Values mutator.MappedFieldValues
+}
+
+type AggregatableBackend interface {
+	// Aggregate returns one AggregateFields for each group, ordered by the
+	// group by fields. Without group by fields there is always exactly one.
+	Aggregate(ctx context.Context, aggregates []*Aggregate, options *AggregateOptions) ([]*AggregateFields, error)
+}
+
+// AggregateResult holds one group of results, where Group is an entry with
+// only the group by fields set
+type AggregateResult[E any, PE mutator.Mutatable[E]] struct {
+	Group  PE
+	Values mutator.MappedFieldValues
+}
+
+type Aggregatable[E any, PE mutator.Mutatable[E]] struct {
+	backend      AggregatableBackend
+	entryFactory mutator.MutatableFactory[E, PE]
+}
+
+func (a *Aggregatable[E, PE]) SetBackend(tableBackend AggregatableBackend) {
+	a.backend = tableBackend
+}
+
+func (a *Aggregatable[E, PE]) Aggregate(aggregates []*Aggregate, options ...func(*AggregateOptions)) ([]*AggregateResult[E, PE], error) {
+	return a.AggregateContext(context.Background(), aggregates, options...)
+}
+
+func (a *Aggregatable[E, PE]) AggregateContext(ctx context.Context, aggregates []*Aggregate, options ...func(*AggregateOptions)) ([]*AggregateResult[E, PE], error) {
+	names := map[string]bool{}
+	for _, aggregate := range aggregates {
+		if names[aggregate.Name] || aggregate.Name == "" {
+			return nil, AggregateNameError
+		}
+		names[aggregate.Name] = true
+	}
+
+	aggregateFieldsList, err := a.backend.Aggregate(ctx, aggregates, NewAggregateOptions(options...))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*AggregateResult[E, PE], len(aggregateFieldsList))
+	for i, aggregateFields := range aggregateFieldsList {
+		group, err := a.entryFactory.CreateFromFields(aggregateFields.Group)
+		if err != nil {
+			return nil, err
+		}
+
+		results[i] = &AggregateResult[E, PE]{
+			Group:  group,
+			Values: aggregateFields.Values,
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package queries
+
+import "fmt"
+
+// BatchSizer is implemented by backends which limit how many entries or keys
+// can be passed to one Add, Get or Delete call
+type BatchSizer interface {
+	// MaxBatchSize returns the most entries or keys the backend accepts in
+	// one call, or zero if there is no limit
+	MaxBatchSize() int
+}
+
+// BatchError is returned when a batch fails after the input at Succeeded
+type BatchError struct {
+	Succeeded []int
+	Err       error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch failed after %d succeeded: %v", len(e.Succeeded), e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+func maxBatchSize(backend any) int {
+	if batchSizer, ok := backend.(BatchSizer); ok {
+		return batchSizer.MaxBatchSize()
+	}
+
+	return 0
+}
+
+// inBatches calls run with ranges of an input of length n no larger than the
+// backend allows, only wrapping errors in a BatchError for multiple batches
+func inBatches(backend any, n int, run func(start, end int) error) error {
+	size := maxBatchSize(backend)
+	if size <= 0 || n <= size {
+		return run(0, n)
+	}
+
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+
+		if err := run(start, end); err != nil {
+			succeeded := make([]int, start)
+			for i := range succeeded {
+				succeeded[i] = i
+			}
+
+			return &BatchError{Succeeded: succeeded, Err: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package queries_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/datastore/queries/queriestest"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type MockBatchBackend struct {
+	MaxBatchSizeRval int
+	FailOnBatch      int
+	ErrorRval        error
+	Batches          [][]mutator.MappedFieldValues
+}
+
+func (b *MockBatchBackend) MaxBatchSize() int {
+	return b.MaxBatchSizeRval
+}
+
+func (b *MockBatchBackend) call(values []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	b.Batches = append(b.Batches, values)
+	if len(b.Batches) == b.FailOnBatch {
+		return nil, b.ErrorRval
+	}
+
+	return values, nil
+}
+
+func (b *MockBatchBackend) Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	return b.call(entries)
+}
+
+func (b *MockBatchBackend) Get(ctx context.Context, keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	entries, err := b.call(keys)
+	for i, entry := range entries {
+		entries[i] = mutator.MappedFieldValues{
+			queriestest.IdKey:   entry[queriestest.IdKey],
+			queriestest.DataKey: entry[queriestest.IdKey],
+		}
+	}
+
+	return entries, err
+}
+
+func (b *MockBatchBackend) Delete(ctx context.Context, keys []mutator.MappedFieldValues) error {
+	_, err := b.call(keys)
+	return err
+}
+
+func TestBatches(t *testing.T) {
+	type batchInputVal struct {
+		numInput     int
+		maxBatchSize int
+		failOnBatch  int
+	}
+
+	type batchExpectedVal struct {
+		batchSizes []int
+		numOutput  int
+		succeeded  int
+		isBatchErr bool
+	}
+
+	mockErr := errors.New("mock error")
+
+	tests := &testutils.Tests[*batchInputVal, *batchExpectedVal]{
+		Cases: []testutils.TestCase[*batchInputVal, *batchExpectedVal]{
+			{
+				Name:     "passes everything in one call without a limit",
+				Input:    &batchInputVal{numInput: 5},
+				Expected: &batchExpectedVal{batchSizes: []int{5}, numOutput: 5},
+			},
+			{
+				Name:     "passes everything in one call within the limit",
+				Input:    &batchInputVal{numInput: 5, maxBatchSize: 5},
+				Expected: &batchExpectedVal{batchSizes: []int{5}, numOutput: 5},
+			},
+			{
+				Name:     "splits calls over the limit",
+				Input:    &batchInputVal{numInput: 5, maxBatchSize: 2},
+				Expected: &batchExpectedVal{batchSizes: []int{2, 2, 1}, numOutput: 5},
+			},
+			{
+				Name:     "returns unwrapped error from one call",
+				Input:    &batchInputVal{numInput: 5, maxBatchSize: 5, failOnBatch: 1},
+				Expected: &batchExpectedVal{batchSizes: []int{5}},
+			},
+			{
+				Name:     "reports indices completed before a failed batch",
+				Input:    &batchInputVal{numInput: 5, maxBatchSize: 2, failOnBatch: 2},
+				Expected: &batchExpectedVal{batchSizes: []int{2, 2}, succeeded: 2, isBatchErr: true},
+			},
+			{
+				Name:     "reports no indices completed when the first batch fails",
+				Input:    &batchInputVal{numInput: 5, maxBatchSize: 2, failOnBatch: 1},
+				Expected: &batchExpectedVal{batchSizes: []int{2}, isBatchErr: true},
+			},
+		},
+		Func: func(t *testing.T, input *batchInputVal, expected *batchExpectedVal) {
+			newBackend := func() *MockBatchBackend {
+				return &MockBatchBackend{
+					MaxBatchSizeRval: input.maxBatchSize,
+					FailOnBatch:      input.failOnBatch,
+					ErrorRval:        mockErr,
+				}
+			}
+
+			entries := make([]*queriestest.MockNonKeyedEntry, input.numInput)
+			keys := make([]*queriestest.MockKey, input.numInput)
+			for i := range entries {
+				entries[i] = &queriestest.MockNonKeyedEntry{Data: &queriestest.MockData{Data: strconv.Itoa(i)}}
+				keys[i] = &queriestest.MockKey{Id: strconv.Itoa(i)}
+			}
+
+			assertBatches := func(backend *MockBatchBackend, err error) {
+				t.Helper()
+
+				testutils.AssertEquals(t, len(expected.batchSizes), len(backend.Batches))
+				for i := range backend.Batches {
+					testutils.AssertEquals(t, expected.batchSizes[i], len(backend.Batches[i]))
+				}
+
+				if input.failOnBatch == 0 {
+					testutils.AssertOk(t, err)
+					return
+				}
+
+				testutils.AssertErrorEquals(t, mockErr, err)
+
+				var batchErr *queries.BatchError
+				testutils.AssertEquals(t, expected.isBatchErr, errors.As(err, &batchErr))
+				if batchErr != nil {
+					testutils.AssertEquals(t, expected.succeeded, len(batchErr.Succeeded))
+					for i, index := range batchErr.Succeeded {
+						testutils.AssertEquals(t, i, index)
+					}
+				}
+			}
+
+			addBackend := newBackend()
+			addable := queries.Addable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+			addable.SetBackend(addBackend)
+			added, err := addable.Add(entries...)
+			assertBatches(addBackend, err)
+			// entries from the batches before a failure are still returned
+			testutils.AssertEquals(t, expected.numOutput+expected.succeeded, len(added))
+			for i := range added {
+				queriestest.AssertMockNonKeyedEntryEquals(t, entries[i], added[i])
+			}
+
+			getBackend := newBackend()
+			getable := queries.Getable[queriestest.MockKey, *queriestest.MockKey, queriestest.MockKeyedEntry, *queriestest.MockKeyedEntry]{}
+			getable.SetBackend(getBackend)
+			found, err := getable.Get(keys...)
+			assertBatches(getBackend, err)
+			testutils.AssertEquals(t, expected.numOutput+expected.succeeded, len(found))
+			for i := range found {
+				queriestest.AssertMockKeyEquals(t, keys[i], found[i].Key)
+			}
+
+			deleteBackend := newBackend()
+			deleteable := queries.Deleteable[queriestest.MockKey, *queriestest.MockKey]{}
+			deleteable.SetBackend(deleteBackend)
+			assertBatches(deleteBackend, deleteable.Delete(keys...))
+		},
+	}
+
+	tests.Run(t)
+}
@@ -1,7 +1,9 @@
 package queries
 
+import "context"
+
 type CountableBackend interface {
-	Count() (int, error)
+	Count(ctx context.Context) (int, error)
 }
 
 type Countable struct {
@@ -13,5 +15,9 @@ func (a *Countable) SetBackend(tableBackend CountableBackend) {
 }
 
 func (a *Countable) Count() (int, error) {
-	return a.backend.Count()
+	return a.CountContext(context.Background())
+}
+
+func (a *Countable) CountContext(ctx context.Context) (int, error) {
+	return a.backend.Count(ctx)
 }
@@ -7,6 +7,7 @@ type CRUDableBackend interface {
 	AddableBackend
 	UpdateableBackend
 	DeleteableBackend
+	UpsertableBackend
 }
 
 type CRUDable[K any, PK mutator.Mutatable[K], E any, PE mutator.Mutatable[E]] struct {
@@ -14,6 +15,7 @@ type CRUDable[K any, PK mutator.Mutatable[K], E any, PE mutator.Mutatable[E]] st
 	Addable[E, PE]
 	Updateable[E, PE]
 	Deleteable[K, PK]
+	Upsertable[E, PE]
 }
 
 func (t *CRUDable[K, PK, E, PE]) SetBackend(tableBackend CRUDableBackend) {
@@ -21,4 +23,5 @@ func (t *CRUDable[K, PK, E, PE]) SetBackend(tableBackend CRUDableBackend) {
 	t.Addable.SetBackend(tableBackend)
 	t.Updateable.SetBackend(tableBackend)
 	t.Deleteable.SetBackend(tableBackend)
+	t.Upsertable.SetBackend(tableBackend)
 }
@@ -1,9 +1,13 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type DeleteableBackend interface {
-	Delete(keys []mutator.MappedFieldValues) error
+	Delete(ctx context.Context, keys []mutator.MappedFieldValues) error
 }
 
 type Deleteable[K any, PK mutator.Mutatable[K]] struct {
@@ -16,7 +20,12 @@ func (a *Deleteable[K, PK]) SetBackend(tableBackend DeleteableBackend) {
 }
 
 func (a *Deleteable[K, PK]) Delete(keys ...PK) error {
-	return a.backend.Delete(
-		a.keyFactory.CreateFieldValuesList(keys),
-	)
+	return a.DeleteContext(context.Background(), keys...)
+}
+
+func (a *Deleteable[K, PK]) DeleteContext(ctx context.Context, keys ...PK) error {
+	keyFieldsList := a.keyFactory.CreateFieldValuesList(keys)
+	return inBatches(a.backend, len(keyFieldsList), func(start, end int) error {
+		return a.backend.Delete(ctx, keyFieldsList[start:end])
+	})
 }
@@ -3,3 +3,7 @@ package queries
 import "errors"
 
 var ComparatorMissingFieldsError = errors.New("all SortKey fields on the left side must be included in comparator")
+
+var PageLimitError = errors.New("page limit must be greater than zero")
+
+var AggregateNameError = errors.New("every aggregate must have a unique, non empty name")
@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"reflect"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+// Generators create the values of auto generated fields which still have
+// their empty value, before entries are passed to the backend
+type Generators struct {
+	FieldGenerators map[string]fields.Generator
+	EmptyValues     mutator.MappedFieldValues
+}
+
+func isEmptyValue(value any) bool {
+	reflectValue := reflect.ValueOf(value)
+	return !reflectValue.IsValid() || reflectValue.IsZero()
+}
+
+func (g *Generators) generate(entries []mutator.MappedFieldValues) error {
+	if g == nil {
+		return nil
+	}
+
+	for _, entry := range entries {
+		for fieldName, generator := range g.FieldGenerators {
+			if !isEmptyValue(entry[fieldName]) {
+				continue
+			}
+
+			value, err := generator.Generate(g.EmptyValues[fieldName])
+			if err != nil {
+				return err
+			}
+
+			entry[fieldName] = value
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,14 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+	"errors"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type GetableBackend interface {
-	Get(keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
+	Get(ctx context.Context, keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
 }
 
 type Getable[K any, PK mutator.Mutatable[K], E any, PE mutator.Mutatable[E]] struct {
@@ -17,13 +22,28 @@ func (a *Getable[K, PK, E, PE]) SetBackend(tableBackend GetableBackend) {
 }
 
 func (a *Getable[K, PK, E, PE]) Get(keys ...PK) ([]PE, error) {
-	entryFieldsList, err := a.backend.Get(
-		a.keyFactory.CreateFieldValuesList(keys),
-	)
+	return a.GetContext(context.Background(), keys...)
+}
+
+// GetContext returns the entries already found along with a BatchError
+func (a *Getable[K, PK, E, PE]) GetContext(ctx context.Context, keys ...PK) ([]PE, error) {
+	keyFieldsList := a.keyFactory.CreateFieldValuesList(keys)
+	entryFieldsList := make([]mutator.MappedFieldValues, 0, len(keyFieldsList))
+	err := inBatches(a.backend, len(keyFieldsList), func(start, end int) error {
+		batch, err := a.backend.Get(ctx, keyFieldsList[start:end])
+		entryFieldsList = append(entryFieldsList, batch...)
+		return err
+	})
 
-	if err != nil {
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
 		return nil, err
 	}
 
-	return a.entryFactory.CreateFromFieldsList(entryFieldsList)
+	entries, createErr := a.entryFactory.CreateFromFieldsList(entryFieldsList)
+	if createErr != nil {
+		return nil, createErr
+	}
+
+	return entries, err
 }
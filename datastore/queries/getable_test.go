@@ -1,6 +1,7 @@
 package queries_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -16,7 +17,7 @@ type MockGetableBackend struct {
 	KeysInput   []mutator.MappedFieldValues
 }
 
-func (b *MockGetableBackend) Get(keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+func (b *MockGetableBackend) Get(ctx context.Context, keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
 	b.KeysInput = keys
 	return b.EntriesRval, b.ErrorRval
 }
@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+type IndexableBackend interface {
+	GetByIndex(ctx context.Context, indexName string, values mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
+}
+
+type Indexable[E any, PE mutator.Mutatable[E]] struct {
+	backend      IndexableBackend
+	entryFactory mutator.MutatableFactory[E, PE]
+}
+
+func (i *Indexable[E, PE]) SetBackend(tableBackend IndexableBackend) {
+	i.backend = tableBackend
+}
+
+// GetByIndex returns every entry with the same values as values for the
+// fields of the index named indexName, other fields of values are ignored
+func (i *Indexable[E, PE]) GetByIndex(indexName string, values PE) ([]PE, error) {
+	return i.GetByIndexContext(context.Background(), indexName, values)
+}
+
+func (i *Indexable[E, PE]) GetByIndexContext(ctx context.Context, indexName string, values PE) ([]PE, error) {
+	entryFieldsList, err := i.backend.GetByIndex(
+		ctx,
+		indexName,
+		i.entryFactory.CreateFieldValues(values),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.entryFactory.CreateFromFieldsList(entryFieldsList)
+}
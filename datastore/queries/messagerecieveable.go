@@ -1,12 +1,31 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type MessageReceiveableBackend interface {
-	HasMessage() (bool, error)
-	RecieveMessage() (string, mutator.MappedFieldValues, error)
-	AckSuccess(messageId []string) error
-	AckFailure(messageId []string) error
+	HasMessage(ctx context.Context) (bool, error)
+	RecieveMessage(ctx context.Context) (string, mutator.MappedFieldValues, error)
+	// RecieveMessageWithDeliveryCount works like RecieveMessage, and also
+	// returns how many times the message has been recieved
+	RecieveMessageWithDeliveryCount(ctx context.Context) (string, mutator.MappedFieldValues, int, error)
+	// RecieveMessageWait blocks for up to maxWait until a message is available
+	RecieveMessageWait(ctx context.Context, maxWait time.Duration) (string, mutator.MappedFieldValues, error)
+	// RecieveMessages returns up to n messages without waiting, which may
+	// be none
+	RecieveMessages(ctx context.Context, n int) ([]string, []mutator.MappedFieldValues, error)
+	AckSuccess(ctx context.Context, messageId []string) error
+	AckFailure(ctx context.Context, messageId []string) error
+	// ExtendVisibility keeps an in flight message from being redelivered
+	// until duration from now
+	ExtendVisibility(ctx context.Context, messageId string, duration time.Duration) error
+	// DeliveryCount is how many times an in flight message has been
+	// recieved, including the current delivery
+	DeliveryCount(ctx context.Context, messageId string) (int, error)
 }
 
 type MessageReceiveable[M any, PM mutator.Mutatable[M]] struct {
@@ -19,11 +38,55 @@ func (s *MessageReceiveable[M, PM]) SetBackend(backend MessageReceiveableBackend
 }
 
 func (m *MessageReceiveable[M, PM]) HasMessage() (bool, error) {
-	return m.backend.HasMessage()
+	return m.HasMessageContext(context.Background())
+}
+
+func (m *MessageReceiveable[M, PM]) HasMessageContext(ctx context.Context) (bool, error) {
+	return m.backend.HasMessage(ctx)
 }
 
 func (m *MessageReceiveable[M, PM]) RecieveMessage() (string, PM, error) {
-	messageId, messageFields, err := m.backend.RecieveMessage()
+	return m.RecieveMessageContext(context.Background())
+}
+
+func (m *MessageReceiveable[M, PM]) RecieveMessageContext(ctx context.Context) (string, PM, error) {
+	messageId, messageFields, err := m.backend.RecieveMessage(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	message, err := m.messageFactory.CreateFromFields(messageFields)
+	return messageId, message, err
+}
+
+// RecieveMessageWithDeliveryCount works like RecieveMessage, and also returns
+// how many times the message has been recieved, including this time
+func (m *MessageReceiveable[M, PM]) RecieveMessageWithDeliveryCount() (string, PM, int, error) {
+	return m.RecieveMessageWithDeliveryCountContext(context.Background())
+}
+
+func (m *MessageReceiveable[M, PM]) RecieveMessageWithDeliveryCountContext(ctx context.Context) (string, PM, int, error) {
+	messageId, messageFields, deliveryCount, err := m.backend.RecieveMessageWithDeliveryCount(ctx)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	message, err := m.messageFactory.CreateFromFields(messageFields)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	return messageId, message, deliveryCount, nil
+}
+
+// RecieveMessageWait works like RecieveMessage, but waits up to maxWait for
+// a message
+func (m *MessageReceiveable[M, PM]) RecieveMessageWait(maxWait time.Duration) (string, PM, error) {
+	return m.RecieveMessageWaitContext(context.Background(), maxWait)
+}
+
+func (m *MessageReceiveable[M, PM]) RecieveMessageWaitContext(ctx context.Context, maxWait time.Duration) (string, PM, error) {
+	messageId, messageFields, err := m.backend.RecieveMessageWait(ctx, maxWait)
 	if err != nil {
 		return "", nil, err
 	}
@@ -32,10 +95,56 @@ func (m *MessageReceiveable[M, PM]) RecieveMessage() (string, PM, error) {
 	return messageId, message, err
 }
 
+// RecieveMessages recieves up to n messages, returning their ids and the
+// messages in the same order. There are fewer than n if the queue runs out.
+func (m *MessageReceiveable[M, PM]) RecieveMessages(n int) ([]string, []PM, error) {
+	return m.RecieveMessagesContext(context.Background(), n)
+}
+
+func (m *MessageReceiveable[M, PM]) RecieveMessagesContext(ctx context.Context, n int) ([]string, []PM, error) {
+	messageIds, messageFieldsList, err := m.backend.RecieveMessages(ctx, n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messages, err := m.messageFactory.CreateFromFieldsList(messageFieldsList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return messageIds, messages, nil
+}
+
 func (m *MessageReceiveable[M, PM]) AckSuccess(messageId ...string) error {
-	return m.backend.AckSuccess(messageId)
+	return m.AckSuccessContext(context.Background(), messageId...)
+}
+
+func (m *MessageReceiveable[M, PM]) AckSuccessContext(ctx context.Context, messageId ...string) error {
+	return m.backend.AckSuccess(ctx, messageId)
 }
 
 func (m *MessageReceiveable[M, PM]) AckFailure(messageId ...string) error {
-	return m.backend.AckFailure(messageId)
+	return m.AckFailureContext(context.Background(), messageId...)
+}
+
+func (m *MessageReceiveable[M, PM]) AckFailureContext(ctx context.Context, messageId ...string) error {
+	return m.backend.AckFailure(ctx, messageId)
+}
+
+// ExtendVisibility keeps a recieved message from being redelivered until
+// duration from now
+func (m *MessageReceiveable[M, PM]) ExtendVisibility(messageId string, duration time.Duration) error {
+	return m.ExtendVisibilityContext(context.Background(), messageId, duration)
+}
+
+func (m *MessageReceiveable[M, PM]) ExtendVisibilityContext(ctx context.Context, messageId string, duration time.Duration) error {
+	return m.backend.ExtendVisibility(ctx, messageId, duration)
+}
+
+func (m *MessageReceiveable[M, PM]) DeliveryCount(messageId string) (int, error) {
+	return m.DeliveryCountContext(context.Background(), messageId)
+}
+
+func (m *MessageReceiveable[M, PM]) DeliveryCountContext(ctx context.Context, messageId string) (int, error) {
+	return m.backend.DeliveryCount(ctx, messageId)
 }
@@ -1,9 +1,11 @@
 package queries_test
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
@@ -12,26 +14,55 @@ import (
 )
 
 type MockMessageRecieveableBackend struct {
-	ErrorRval    error
-	MessagesRval []mutator.MappedFieldValues
-	messageIdx   int
+	ErrorRval         error
+	MessagesRval      []mutator.MappedFieldValues
+	DeliveryCountRval int
+	messageIdx        int
 }
 
-func (b *MockMessageRecieveableBackend) HasMessage() (bool, error) {
+func (b *MockMessageRecieveableBackend) HasMessage(ctx context.Context) (bool, error) {
 	return b.messageIdx < len(b.MessagesRval), b.ErrorRval
 }
 
-func (b *MockMessageRecieveableBackend) RecieveMessage() (string, mutator.MappedFieldValues, error) {
+func (b *MockMessageRecieveableBackend) RecieveMessage(ctx context.Context) (string, mutator.MappedFieldValues, error) {
 	id := strconv.Itoa(b.messageIdx)
 	message := b.MessagesRval[b.messageIdx]
 	return id, message, b.ErrorRval
 }
 
-func (b *MockMessageRecieveableBackend) AckSuccess(messageId []string) error {
+func (b *MockMessageRecieveableBackend) RecieveMessageWithDeliveryCount(ctx context.Context) (string, mutator.MappedFieldValues, int, error) {
+	id, message, err := b.RecieveMessage(ctx)
+	return id, message, b.DeliveryCountRval, err
+}
+
+func (b *MockMessageRecieveableBackend) RecieveMessageWait(ctx context.Context, maxWait time.Duration) (string, mutator.MappedFieldValues, error) {
+	return b.RecieveMessage(ctx)
+}
+
+func (b *MockMessageRecieveableBackend) RecieveMessages(ctx context.Context, n int) ([]string, []mutator.MappedFieldValues, error) {
+	ids := []string{}
+	messages := []mutator.MappedFieldValues{}
+	for i := 0; i < n && i < len(b.MessagesRval); i += 1 {
+		ids = append(ids, strconv.Itoa(i))
+		messages = append(messages, b.MessagesRval[i])
+	}
+
+	return ids, messages, b.ErrorRval
+}
+
+func (b *MockMessageRecieveableBackend) ExtendVisibility(ctx context.Context, messageId string, duration time.Duration) error {
 	return b.ErrorRval
 }
 
-func (b *MockMessageRecieveableBackend) AckFailure(messageId []string) error {
+func (b *MockMessageRecieveableBackend) DeliveryCount(ctx context.Context, messageId string) (int, error) {
+	return b.DeliveryCountRval, b.ErrorRval
+}
+
+func (b *MockMessageRecieveableBackend) AckSuccess(ctx context.Context, messageId []string) error {
+	return b.ErrorRval
+}
+
+func (b *MockMessageRecieveableBackend) AckFailure(ctx context.Context, messageId []string) error {
 	return b.ErrorRval
 }
 
@@ -117,3 +148,75 @@ func TestRecieveMessage(t *testing.T) {
 
 	tests.Run(t)
 }
+
+func TestRecieveMessages(t *testing.T) {
+	mockErr := errors.New("mock error")
+	messages := []mutator.MappedFieldValues{
+		{queriestest.DataKey: "test1"},
+		{queriestest.DataKey: "test2"},
+		{queriestest.DataKey: "test3"},
+	}
+
+	testutils.Case(t, "recieves up to n messages", func(t *testing.T) {
+		recieveable := queries.MessageReceiveable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		recieveable.SetBackend(&MockMessageRecieveableBackend{
+			MessagesRval: messages,
+		})
+
+		ids, actualMessages, err := recieveable.RecieveMessages(2)
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, 2, len(ids))
+		testutils.AssertEquals(t, 2, len(actualMessages))
+		testutils.AssertEquals(t, "test1", actualMessages[0].Data.Data)
+		testutils.AssertEquals(t, "test2", actualMessages[1].Data.Data)
+	})
+
+	testutils.Case(t, "returns error from backend", func(t *testing.T) {
+		recieveable := queries.MessageReceiveable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		recieveable.SetBackend(&MockMessageRecieveableBackend{
+			MessagesRval: messages,
+			ErrorRval:    mockErr,
+		})
+
+		ids, actualMessages, err := recieveable.RecieveMessages(2)
+		testutils.AssertErrorEquals(t, mockErr, err)
+		testutils.AssertTrue(t, ids == nil)
+		testutils.AssertTrue(t, actualMessages == nil)
+	})
+}
+
+func TestRecieveMessageWithDeliveryCount(t *testing.T) {
+	mockErr := errors.New("mock error")
+	messages := []mutator.MappedFieldValues{
+		{queriestest.DataKey: "test1"},
+	}
+
+	testutils.Case(t, "returns delivery count with message", func(t *testing.T) {
+		recieveable := queries.MessageReceiveable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		recieveable.SetBackend(&MockMessageRecieveableBackend{
+			MessagesRval:      messages,
+			DeliveryCountRval: 3,
+		})
+
+		id, message, deliveryCount, err := recieveable.RecieveMessageWithDeliveryCount()
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "0", id)
+		testutils.AssertEquals(t, "test1", message.Data.Data)
+		testutils.AssertEquals(t, 3, deliveryCount)
+	})
+
+	testutils.Case(t, "returns error from backend", func(t *testing.T) {
+		recieveable := queries.MessageReceiveable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		recieveable.SetBackend(&MockMessageRecieveableBackend{
+			MessagesRval:      messages,
+			DeliveryCountRval: 3,
+			ErrorRval:         mockErr,
+		})
+
+		id, message, deliveryCount, err := recieveable.RecieveMessageWithDeliveryCount()
+		testutils.AssertErrorEquals(t, mockErr, err)
+		testutils.AssertEquals(t, "", id)
+		testutils.AssertTrue(t, message == nil)
+		testutils.AssertEquals(t, 0, deliveryCount)
+	})
+}
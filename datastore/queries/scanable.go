@@ -1,9 +1,19 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type ScanableBackend interface {
-	Scan(batchSize int) (chan mutator.MappedFieldValues, chan error)
+	// Scan sends the entries matching filter, projected to fieldNames if
+	// given, and closes both channels when done or ctx is done
+	Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error)
+	// ScanPage returns up to limit entries after cursor, and the cursor of the
+	// last entry if there are more
+	ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error)
 }
 
 type Scanable[E any, PE mutator.Mutatable[E]] struct {
@@ -16,43 +26,103 @@ func (s *Scanable[E, PE]) SetBackend(tableBackend ScanableBackend) {
 }
 
 func (s *Scanable[E, PE]) Scan(batchSize int) (chan PE, chan error) {
-	inChan, inErrorChan := s.backend.Scan(batchSize)
+	return s.ScanContext(context.Background(), batchSize)
+}
+
+// ScanContext works like Scan, but stops when ctx is done. The context error
+// is sent on the error channel if there is room, and both channels are closed.
+func (s *Scanable[E, PE]) ScanContext(ctx context.Context, batchSize int) (chan PE, chan error) {
+	return s.ScanWithFilterContext(ctx, batchSize, nil)
+}
+
+// ScanWithFilter works like Scan, but only returns entries matching filter.
+// When fieldNames are given, only those fields are set on the entries.
+func (s *Scanable[E, PE]) ScanWithFilter(batchSize int, filter *compare.Filter, fieldNames ...string) (chan PE, chan error) {
+	return s.ScanWithFilterContext(context.Background(), batchSize, filter, fieldNames...)
+}
+
+func (s *Scanable[E, PE]) ScanWithFilterContext(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames ...string) (chan PE, chan error) {
+	ctx, cancel := context.WithCancel(ctx)
+	inChan, inErrorChan := s.backend.Scan(ctx, batchSize, filter, fieldNames)
 
 	outChan := make(chan PE, 1)
 	outErrorChan := make(chan error, 1)
 	go func() {
 		defer close(outChan)
 		defer close(outErrorChan)
+		defer cancel()
 
-		for {
+		for (inErrorChan != nil || inChan != nil) && ctx.Err() == nil {
 			select {
+			case <-ctx.Done():
+				continue
+
 			case err, more := <-inErrorChan:
 				if !more {
 					inErrorChan = nil
-					break
+					continue
+				} else if ctx.Err() != nil {
+					// the context error is sent once the loop ends
+					continue
 				}
 
-				outErrorChan <- err
+				select {
+				case outErrorChan <- err:
+				case <-ctx.Done():
+				}
 
 			case inFields, more := <-inChan:
 				if !more {
 					inChan = nil
-					break
+					continue
 				}
 
 				entry, err := s.entryFactory.CreateFromFields(inFields)
 				if err != nil {
-					outErrorChan <- err
+					select {
+					case outErrorChan <- err:
+					case <-ctx.Done():
+					}
 				} else {
-					outChan <- entry
+					select {
+					case outChan <- entry:
+					case <-ctx.Done():
+					}
 				}
 			}
+		}
 
-			if inErrorChan == nil && inChan == nil {
-				break
+		if ctx.Err() != nil {
+			select {
+			case outErrorChan <- ctx.Err():
+			default:
 			}
 		}
 	}()
 
 	return outChan, outErrorChan
 }
+
+// ScanPage returns up to limit entries following cursor, and the cursor of
+// the next page which is empty after the last
+func (s *Scanable[E, PE]) ScanPage(cursor string, limit int) ([]PE, string, error) {
+	return s.ScanPageContext(context.Background(), cursor, limit)
+}
+
+func (s *Scanable[E, PE]) ScanPageContext(ctx context.Context, cursor string, limit int) ([]PE, string, error) {
+	if limit <= 0 {
+		return nil, "", PageLimitError
+	}
+
+	entryFieldsList, nextCursor, err := s.backend.ScanPage(ctx, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries, err := s.entryFactory.CreateFromFieldsList(entryFieldsList)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entries, nextCursor, nil
+}
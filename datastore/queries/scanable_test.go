@@ -1,3 +1,222 @@
 package queries_test
 
-// TODO: add tests for this
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/datastore/queries/queriestest"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type MockScanableBackend struct {
+	EntriesRval []mutator.MappedFieldValues
+	ErrorRval   error
+	Stopped     chan struct{}
+
+	FilterArg     *compare.Filter
+	FieldNamesArg []string
+
+	CursorArg      string
+	NextCursorRval string
+}
+
+func (b *MockScanableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	outChan := make(chan mutator.MappedFieldValues, batchSize)
+	errorChan := make(chan error, 1)
+	b.Stopped = make(chan struct{})
+	b.FilterArg = filter
+	b.FieldNamesArg = fieldNames
+
+	go func() {
+		defer close(b.Stopped)
+		defer close(outChan)
+		defer close(errorChan)
+
+		for _, entry := range b.EntriesRval {
+			select {
+			case outChan <- entry:
+			case <-ctx.Done():
+				errorChan <- ctx.Err()
+				return
+			}
+		}
+
+		if b.ErrorRval != nil {
+			errorChan <- b.ErrorRval
+		}
+	}()
+
+	return outChan, errorChan
+}
+
+func (b *MockScanableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	b.CursorArg = cursor
+	if limit < len(b.EntriesRval) {
+		return b.EntriesRval[:limit], b.NextCursorRval, b.ErrorRval
+	}
+
+	return b.EntriesRval, b.NextCursorRval, b.ErrorRval
+}
+
+func generateScanEntries(num int) []mutator.MappedFieldValues {
+	entries := make([]mutator.MappedFieldValues, num)
+	for i := range entries {
+		entries[i] = mutator.MappedFieldValues{
+			queriestest.DataKey: "test" + strconv.Itoa(i),
+		}
+	}
+
+	return entries
+}
+
+func collectScan(dataChan chan *queriestest.MockNonKeyedEntry, errorChan chan error) ([]*queriestest.MockNonKeyedEntry, []error) {
+	entries := []*queriestest.MockNonKeyedEntry{}
+	errs := []error{}
+
+	for dataChan != nil || errorChan != nil {
+		select {
+		case entry, more := <-dataChan:
+			if !more {
+				dataChan = nil
+				continue
+			}
+
+			entries = append(entries, entry)
+		case err, more := <-errorChan:
+			if !more {
+				errorChan = nil
+				continue
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return entries, errs
+}
+
+func TestScan(t *testing.T) {
+	mockErr := errors.New("mock error")
+
+	testutils.Case(t, "scans all entries", func(t *testing.T) {
+		backend := &MockScanableBackend{
+			EntriesRval: generateScanEntries(5),
+		}
+
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(backend)
+
+		entries, errs := collectScan(scanable.Scan(2))
+		testutils.AssertEquals(t, 0, len(errs))
+		testutils.AssertEquals(t, 5, len(entries))
+		for i, entry := range entries {
+			testutils.AssertEquals(t, "test"+strconv.Itoa(i), entry.Data.Data)
+		}
+	})
+
+	testutils.Case(t, "passes filter and field names to backend", func(t *testing.T) {
+		backend := &MockScanableBackend{
+			EntriesRval: generateScanEntries(1),
+		}
+
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(backend)
+
+		filter := compare.Field(queriestest.DataKey, compare.Eq("test0"))
+		entries, errs := collectScan(scanable.ScanWithFilter(2, filter, queriestest.DataKey))
+		testutils.AssertEquals(t, 0, len(errs))
+		testutils.AssertEquals(t, 1, len(entries))
+		testutils.AssertTrue(t, backend.FilterArg == filter)
+		testutils.AssertEquals(t, 1, len(backend.FieldNamesArg))
+		testutils.AssertEquals(t, queriestest.DataKey, backend.FieldNamesArg[0])
+	})
+
+	testutils.Case(t, "returns error from backend", func(t *testing.T) {
+		backend := &MockScanableBackend{
+			EntriesRval: generateScanEntries(2),
+			ErrorRval:   mockErr,
+		}
+
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(backend)
+
+		entries, errs := collectScan(scanable.Scan(2))
+		testutils.AssertEquals(t, 2, len(entries))
+		testutils.AssertEquals(t, 1, len(errs))
+		testutils.AssertErrorEquals(t, mockErr, errs[0])
+	})
+
+	testutils.Case(t, "stops when context is cancelled", func(t *testing.T) {
+		backend := &MockScanableBackend{
+			EntriesRval: generateScanEntries(100),
+		}
+
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(backend)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		dataChan, errorChan := scanable.ScanContext(ctx, 1)
+
+		_, more := <-dataChan
+		testutils.AssertTrue(t, more)
+		cancel()
+
+		entries, errs := collectScan(dataChan, errorChan)
+		testutils.AssertTrue(t, len(entries) < 99)
+		testutils.AssertEquals(t, 1, len(errs))
+		testutils.AssertErrorEquals(t, context.Canceled, errs[0])
+
+		// the backend scan is also stopped
+		<-backend.Stopped
+	})
+
+	testutils.Case(t, "stops when context is already done", func(t *testing.T) {
+		backend := &MockScanableBackend{
+			EntriesRval: generateScanEntries(100),
+		}
+
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(backend)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		entries, errs := collectScan(scanable.ScanContext(ctx, 1))
+		testutils.AssertEquals(t, 0, len(entries))
+		testutils.AssertEquals(t, 1, len(errs))
+		testutils.AssertErrorEquals(t, context.Canceled, errs[0])
+		<-backend.Stopped
+	})
+}
+
+func TestScanPage(t *testing.T) {
+	testutils.Case(t, "returns page and next cursor", func(t *testing.T) {
+		backend := &MockScanableBackend{
+			EntriesRval:    generateScanEntries(5),
+			NextCursorRval: "next",
+		}
+
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(backend)
+
+		entries, nextCursor, err := scanable.ScanPage("start", 2)
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "start", backend.CursorArg)
+		testutils.AssertEquals(t, "next", nextCursor)
+		testutils.AssertEquals(t, 2, len(entries))
+		testutils.AssertEquals(t, "test1", entries[1].Data.Data)
+	})
+
+	testutils.Case(t, "returns error for invalid limit", func(t *testing.T) {
+		scanable := queries.Scanable[queriestest.MockNonKeyedEntry, *queriestest.MockNonKeyedEntry]{}
+		scanable.SetBackend(&MockScanableBackend{})
+
+		_, _, err := scanable.ScanPage("", 0)
+		testutils.AssertErrorEquals(t, queries.PageLimitError, err)
+	})
+}
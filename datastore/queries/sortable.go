@@ -1,6 +1,8 @@
 package queries
 
 import (
+	"context"
+
 	"github.com/sophielizg/go-libs/datastore/compare"
 	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/datastore/mutator"
@@ -8,17 +10,21 @@ import (
 )
 
 type SortableBackend interface {
-	GetWithSortComparator(key mutator.MappedFieldValues, comparator mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
-	UpdateWithSortComparator(entry mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error
-	DeleteWithSortComparator(key mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error
+	GetWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues, options *SortOptions) ([]mutator.MappedFieldValues, error)
+	// GetPageWithSortComparator returns up to limit matching entries after
+	// cursor, and the cursor of the last if there are more
+	GetPageWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues, cursor string, limit int) ([]mutator.MappedFieldValues, string, error)
+	UpdateWithSortComparator(ctx context.Context, entry mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error
+	DeleteWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error
 }
 
 type Sortable[K any, PK mutator.Mutatable[K], E any, PE mutator.Mutatable[E], C any, PC mutator.Mutatable[C]] struct {
-	backend        SortableBackend
-	keyFactory     mutator.MutatableFactory[K, PK]
-	entryFactory   mutator.MutatableFactory[E, PE]
-	KeySettings    *fields.RowSettings
-	SortFieldNames fields.SortFieldNames
+	backend          SortableBackend
+	keyFactory       mutator.MutatableFactory[K, PK]
+	entryFactory     mutator.MutatableFactory[E, PE]
+	KeySettings      *fields.RowSettings
+	SortFieldNames   fields.SortFieldNames
+	VersionFieldName string
 }
 
 func (s *Sortable[K, PK, E, PE, C, PC]) SetBackend(tableBackend SortableBackend) {
@@ -47,14 +53,22 @@ func (s *Sortable[K, PK, E, PE, C, PC]) validateComparator(comparator PC) error
 	return nil
 }
 
-func (s *Sortable[K, PK, E, PE, C, PC]) GetWithSortComparator(key PK, comparator PC) ([]PE, error) {
+// GetWithSortComparator returns the entries with the hash fields of key which
+// match comparator, in ascending order unless options say otherwise
+func (s *Sortable[K, PK, E, PE, C, PC]) GetWithSortComparator(key PK, comparator PC, options ...func(*SortOptions)) ([]PE, error) {
+	return s.GetWithSortComparatorContext(context.Background(), key, comparator, options...)
+}
+
+func (s *Sortable[K, PK, E, PE, C, PC]) GetWithSortComparatorContext(ctx context.Context, key PK, comparator PC, options ...func(*SortOptions)) ([]PE, error) {
 	if err := s.validateComparator(comparator); err != nil {
 		return nil, err
 	}
 
 	entryFieldsList, err := s.backend.GetWithSortComparator(
+		ctx,
 		s.keyFactory.CreateFieldValues(key),
 		comparator.Mutator().GetFields(),
+		NewSortOptions(options...),
 	)
 	if err != nil {
 		return nil, err
@@ -63,23 +77,70 @@ func (s *Sortable[K, PK, E, PE, C, PC]) GetWithSortComparator(key PK, comparator
 	return s.entryFactory.CreateFromFieldsList(entryFieldsList)
 }
 
+// GetPageWithSortComparator works like GetWithSortComparator, but returns a
+// page of up to limit entries following cursor
+func (s *Sortable[K, PK, E, PE, C, PC]) GetPageWithSortComparator(key PK, comparator PC, cursor string, limit int) ([]PE, string, error) {
+	return s.GetPageWithSortComparatorContext(context.Background(), key, comparator, cursor, limit)
+}
+
+func (s *Sortable[K, PK, E, PE, C, PC]) GetPageWithSortComparatorContext(ctx context.Context, key PK, comparator PC, cursor string, limit int) ([]PE, string, error) {
+	if err := s.validateComparator(comparator); err != nil {
+		return nil, "", err
+	} else if limit <= 0 {
+		return nil, "", PageLimitError
+	}
+
+	entryFieldsList, nextCursor, err := s.backend.GetPageWithSortComparator(
+		ctx,
+		s.keyFactory.CreateFieldValues(key),
+		comparator.Mutator().GetFields(),
+		cursor,
+		limit,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries, err := s.entryFactory.CreateFromFieldsList(entryFieldsList)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entries, nextCursor, nil
+}
+
 func (s *Sortable[K, PK, E, PE, C, PC]) UpdateWithSortComparator(entry PE, comparator PC) error {
+	return s.UpdateWithSortComparatorContext(context.Background(), entry, comparator)
+}
+
+func (s *Sortable[K, PK, E, PE, C, PC]) UpdateWithSortComparatorContext(ctx context.Context, entry PE, comparator PC) error {
 	if err := s.validateComparator(comparator); err != nil {
 		return err
 	}
 
-	return s.backend.UpdateWithSortComparator(
+	err := s.backend.UpdateWithSortComparator(
+		ctx,
 		s.entryFactory.CreateFieldValues(entry),
 		comparator.Mutator().GetFields(),
 	)
+	if err != nil {
+		return err
+	}
+
+	return setNextVersion(s.VersionFieldName, []PE{entry})
 }
 
 func (s *Sortable[K, PK, E, PE, C, PC]) DeleteWithSortComparator(key PK, comparator PC) error {
+	return s.DeleteWithSortComparatorContext(context.Background(), key, comparator)
+}
+
+func (s *Sortable[K, PK, E, PE, C, PC]) DeleteWithSortComparatorContext(ctx context.Context, key PK, comparator PC) error {
 	if err := s.validateComparator(comparator); err != nil {
 		return err
 	}
 
 	return s.backend.DeleteWithSortComparator(
+		ctx,
 		s.keyFactory.CreateFieldValues(key),
 		comparator.Mutator().GetFields(),
 	)
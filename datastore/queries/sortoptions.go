@@ -0,0 +1,42 @@
+package queries
+
+type SortOrder = int8
+
+const (
+	ASCENDING SortOrder = iota
+	DESCENDING
+)
+
+// SortOptions control the results of GetWithSortComparator, a Limit of zero
+// returns every entry
+type SortOptions struct {
+	Order SortOrder
+	Limit int
+}
+
+func NewSortOptions(options ...func(*SortOptions)) *SortOptions {
+	sortOptions := &SortOptions{}
+
+	for _, option := range options {
+		option(sortOptions)
+	}
+
+	return sortOptions
+}
+
+func WithOrder(order SortOrder) func(*SortOptions) {
+	return func(options *SortOptions) {
+		options.Order = order
+	}
+}
+
+func WithDescending() func(*SortOptions) {
+	return WithOrder(DESCENDING)
+}
+
+// WithLimit returns at most limit entries, the first limit in the sort order
+func WithLimit(limit int) func(*SortOptions) {
+	return func(options *SortOptions) {
+		options.Limit = limit
+	}
+}
@@ -1,6 +1,10 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type Transferable[E any, PE mutator.Mutatable[E]] struct {
 	Scanable *Scanable[E, PE]
@@ -8,7 +12,16 @@ type Transferable[E any, PE mutator.Mutatable[E]] struct {
 }
 
 func (t *Transferable[E, PE]) TransferTo(newTable *Transferable[E, PE], batchSize int) error {
-	dataChan, errorChan := t.Scanable.Scan(batchSize)
+	return t.TransferToContext(context.Background(), newTable, batchSize)
+}
+
+// TransferToContext stops between batches when ctx is done, so entries added
+// before then stay in newTable
+func (t *Transferable[E, PE]) TransferToContext(ctx context.Context, newTable *Transferable[E, PE], batchSize int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dataChan, errorChan := t.Scanable.ScanContext(ctx, batchSize)
 
 	for {
 		buf := make([]PE, 0, batchSize)
@@ -37,14 +50,14 @@ func (t *Transferable[E, PE]) TransferTo(newTable *Transferable[E, PE], batchSiz
 		}
 
 		if len(buf) > 0 {
-			_, err := newTable.Addable.Add(buf...)
+			_, err := newTable.Addable.AddContext(ctx, buf...)
 			if err != nil {
 				return err
 			}
 		}
 
 		if dataChan == nil && errorChan == nil {
-			return nil
+			return ctx.Err()
 		}
 	}
 }
@@ -1,14 +1,21 @@
 package queries
 
-import "github.com/sophielizg/go-libs/datastore/mutator"
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
 
 type UpdateableBackend interface {
-	Update(keys []mutator.MappedFieldValues) error
+	Update(ctx context.Context, keys []mutator.MappedFieldValues) error
+	UpdateIf(ctx context.Context, entries []mutator.MappedFieldValues, predicate func(existing mutator.MappedFieldValues) (bool, error)) error
 }
 
 type Updateable[E any, PE mutator.Mutatable[E]] struct {
-	backend      UpdateableBackend
-	entryFactory mutator.MutatableFactory[E, PE]
+	backend          UpdateableBackend
+	entryFactory     mutator.MutatableFactory[E, PE]
+	VersionFieldName string
 }
 
 func (a *Updateable[E, PE]) SetBackend(tableBackend UpdateableBackend) {
@@ -16,7 +23,64 @@ func (a *Updateable[E, PE]) SetBackend(tableBackend UpdateableBackend) {
 }
 
 func (a *Updateable[E, PE]) Update(entries ...PE) error {
-	return a.backend.Update(
+	return a.UpdateContext(context.Background(), entries...)
+}
+
+func (a *Updateable[E, PE]) UpdateContext(ctx context.Context, entries ...PE) error {
+	err := a.backend.Update(
+		ctx,
 		a.entryFactory.CreateFieldValuesList(entries),
 	)
+	if err != nil {
+		return err
+	}
+
+	return setNextVersion(a.VersionFieldName, entries)
+}
+
+// UpdateIf updates the entries only if predicate returns true for each stored
+// entry, or returns ConditionFailedError. predicate must not query the table.
+func (a *Updateable[E, PE]) UpdateIf(predicate func(existing PE) bool, entries ...PE) error {
+	return a.UpdateIfContext(context.Background(), predicate, entries...)
+}
+
+func (a *Updateable[E, PE]) UpdateIfContext(ctx context.Context, predicate func(existing PE) bool, entries ...PE) error {
+	err := a.backend.UpdateIf(
+		ctx,
+		a.entryFactory.CreateFieldValuesList(entries),
+		func(existing mutator.MappedFieldValues) (bool, error) {
+			existingEntry, err := a.entryFactory.CreateFromFields(existing)
+			if err != nil {
+				return false, err
+			}
+
+			return predicate(existingEntry), nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return setNextVersion(a.VersionFieldName, entries)
+}
+
+// setNextVersion increments the version field of each updated entry to match
+// the version stored by the backend, so they can be updated again
+func setNextVersion[E any, PE mutator.Mutatable[E]](versionFieldName string, entries []PE) error {
+	if versionFieldName == "" {
+		return nil
+	}
+
+	for _, entry := range entries {
+		version, err := fields.NextVersion(entry.Mutator().GetField(versionFieldName))
+		if err != nil {
+			return err
+		}
+
+		if err := entry.Mutator().SetField(versionFieldName, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+type UpsertableBackend interface {
+	Upsert(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
+	AddIfAbsent(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error)
+}
+
+type Upsertable[E any, PE mutator.Mutatable[E]] struct {
+	Generators   *Generators
+	backend      UpsertableBackend
+	entryFactory mutator.MutatableFactory[E, PE]
+}
+
+func (u *Upsertable[E, PE]) SetBackend(tableBackend UpsertableBackend) {
+	u.backend = tableBackend
+}
+
+// Upsert adds each entry, or replaces the stored entry if its key already
+// exists, and returns the entries as they were stored
+func (u *Upsertable[E, PE]) Upsert(entries ...PE) ([]PE, error) {
+	return u.UpsertContext(context.Background(), entries...)
+}
+
+func (u *Upsertable[E, PE]) UpsertContext(ctx context.Context, entries ...PE) ([]PE, error) {
+	entryFieldsList := u.entryFactory.CreateFieldValuesList(entries)
+	if err := u.Generators.generate(entryFieldsList); err != nil {
+		return nil, err
+	}
+
+	entryFieldsList, err := u.backend.Upsert(ctx, entryFieldsList)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.entryFactory.CreateFromFieldsList(entryFieldsList)
+}
+
+// AddIfAbsent adds the entries whose keys do not already exist, leaving the
+// others unchanged, and returns the entries which were added
+func (u *Upsertable[E, PE]) AddIfAbsent(entries ...PE) ([]PE, error) {
+	return u.AddIfAbsentContext(context.Background(), entries...)
+}
+
+func (u *Upsertable[E, PE]) AddIfAbsentContext(ctx context.Context, entries ...PE) ([]PE, error) {
+	entryFieldsList := u.entryFactory.CreateFieldValuesList(entries)
+	if err := u.Generators.generate(entryFieldsList); err != nil {
+		return nil, err
+	}
+
+	entryFieldsList, err := u.backend.AddIfAbsent(ctx, entryFieldsList)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.entryFactory.CreateFromFieldsList(entryFieldsList)
+}
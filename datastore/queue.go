@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"context"
+
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
 )
@@ -30,29 +32,46 @@ func (q *Queue[M, PM]) SetBackend(backend QueueBackendQueries) {
 }
 
 func (q *Queue[M, PM]) SendMessage(messages ...PM) error {
-	return q.backend.SendMessage(q.MessageFactory.CreateFieldValuesList(messages))
+	return q.SendMessageContext(context.Background(), messages...)
+}
+
+func (q *Queue[M, PM]) SendMessageContext(ctx context.Context, messages ...PM) error {
+	return q.backend.SendMessage(ctx, q.MessageFactory.CreateFieldValuesList(messages))
 }
 
 func (q *Queue[M, PM]) TransferTo(newQueue *Queue[M, PM], batchSize int) error {
+	return q.TransferToContext(context.Background(), newQueue, batchSize)
+}
+
+// TransferToContext stops when ctx is done, acking recieved messages
+// without it
+func (q *Queue[M, PM]) TransferToContext(ctx context.Context, newQueue *Queue[M, PM], batchSize int) error {
 	bufMessages := make([]PM, 0, batchSize)
 	bufIds := make([]string, 0, batchSize)
 	for {
-		size, err := q.Count()
+		if err := ctx.Err(); err != nil {
+			q.AckFailure(bufIds...)
+			return err
+		}
+
+		size, err := q.CountContext(ctx)
 		if err != nil {
+			q.AckFailure(bufIds...)
 			return err
 		} else if size == 0 {
 			break
 		}
 
-		id, message, err := q.RecieveMessage()
+		id, message, err := q.RecieveMessageContext(ctx)
 		if err != nil {
+			q.AckFailure(bufIds...)
 			return err
 		}
 
 		bufMessages = append(bufMessages, message)
 		bufIds = append(bufIds, id)
 		if len(bufMessages) == batchSize {
-			if err = newQueue.SendMessage(bufMessages...); err != nil {
+			if err = newQueue.SendMessageContext(ctx, bufMessages...); err != nil {
 				q.AckFailure(bufIds...)
 				return err
 			}
@@ -63,7 +82,7 @@ func (q *Queue[M, PM]) TransferTo(newQueue *Queue[M, PM], batchSize int) error {
 		}
 	}
 
-	if err := newQueue.SendMessage(bufMessages...); err != nil {
+	if err := newQueue.SendMessageContext(ctx, bufMessages...); err != nil {
 		q.AckFailure(bufIds...)
 		return err
 	}
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
 )
@@ -8,19 +9,37 @@ import (
 type SortTable[K any, PK mutator.Mutatable[K], E any, PE mutator.Mutatable[E], C any, PC mutator.Mutatable[C]] struct {
 	Settings *TableSettings
 	*queries.Scanable[E, PE]
+	*queries.Aggregatable[E, PE]
 	*queries.Countable
 	*queries.CRUDable[K, PK, E, PE]
+	*queries.Indexable[E, PE]
 	*queries.Sortable[K, PK, E, PE, C, PC]
 	*queries.Transferable[E, PE]
 }
 
 func (t *SortTable[K, PK, E, PE, C, PC]) Init() {
 	t.Settings.ApplyOption(WithEntry[E, PE]())
+	t.Settings.ApplyOption(withKey[K, PK]())
 	t.Scanable = &queries.Scanable[E, PE]{}
+	t.Aggregatable = &queries.Aggregatable[E, PE]{}
 	t.Countable = &queries.Countable{}
-	t.CRUDable = &queries.CRUDable[K, PK, E, PE]{}
+	generators := t.Settings.Generators()
+	t.CRUDable = &queries.CRUDable[K, PK, E, PE]{
+		Addable: queries.Addable[E, PE]{
+			Generators: generators,
+		},
+		Updateable: queries.Updateable[E, PE]{
+			VersionFieldName: fields.VersionFieldName(t.Settings.DataSettings),
+		},
+		Upsertable: queries.Upsertable[E, PE]{
+			Generators: generators,
+		},
+	}
+	t.Indexable = &queries.Indexable[E, PE]{}
 	t.Sortable = &queries.Sortable[K, PK, E, PE, C, PC]{
-		SortFieldNames: t.Settings.SortFieldNames,
+		KeySettings:      t.Settings.KeySettings,
+		SortFieldNames:   t.Settings.SortFieldNames,
+		VersionFieldName: fields.VersionFieldName(t.Settings.DataSettings),
 	}
 	t.Transferable = &queries.Transferable[E, PE]{
 		Scanable: t.Scanable,
@@ -34,7 +53,9 @@ func (t *SortTable[K, PK, E, PE, C, PC]) GetSettings() *TableSettings {
 
 func (t *SortTable[K, PK, E, PE, C, PC]) SetBackend(tableBackend SortTableBackendQueries) {
 	t.Scanable.SetBackend(tableBackend)
+	t.Aggregatable.SetBackend(tableBackend)
 	t.Countable.SetBackend(tableBackend)
 	t.CRUDable.SetBackend(tableBackend)
+	t.Indexable.SetBackend(tableBackend)
 	t.Sortable.SetBackend(tableBackend)
 }
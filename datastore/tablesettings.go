@@ -1,8 +1,14 @@
 package datastore
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
 	"github.com/sophielizg/go-libs/datastore/fields"
 	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+	"github.com/sophielizg/go-libs/utils"
 )
 
 type TableSettings struct {
@@ -11,12 +17,217 @@ type TableSettings struct {
 	DataSettings   *fields.RowSettings
 	KeySettings    *fields.RowSettings
 	SortFieldNames fields.SortFieldNames
+	// VisibilityTimeout is how long a recieved message stays in flight
+	// before it is redelivered, or forever if it is zero
+	VisibilityTimeout time.Duration
+	// DeadLetterQueueName names the Queue which messages are moved to after
+	// MaxDeliveryAttempts, if it is not zero
+	DeadLetterQueueName string
+	MaxDeliveryAttempts int
+	Indexes             []*Index
+	// keyEmptyValues are the fields of the key of a keyed table's entry
+	keyEmptyValues mutator.MappedFieldValues
+}
+
+// Index lets entries be looked up by the values of FieldNames with
+// GetByIndex
+type Index struct {
+	Name       string
+	FieldNames fields.OrderedFieldKeys
+	Unique     bool
 }
 
 func (s *TableSettings) ApplyOption(option func(*TableSettings)) {
 	option(s)
 }
 
+// Index returns the index with name, or nil if there is none
+func (s *TableSettings) Index(name string) *Index {
+	for _, index := range s.Indexes {
+		if index.Name == name {
+			return index
+		}
+	}
+
+	return nil
+}
+
+// Generators returns the generators of auto generated fields other than
+// AutoIncrement fields, or nil if there are none
+func (s *TableSettings) Generators() *queries.Generators {
+	fieldGenerators := map[string]fields.Generator{}
+	for _, rowSettings := range []*fields.RowSettings{s.KeySettings, s.DataSettings} {
+		if rowSettings == nil {
+			continue
+		}
+
+		for fieldName, setting := range rowSettings.FieldSettings {
+			generator := setting.GeneratorFor(s.EmptyValues[fieldName])
+			if generator != nil && !setting.IsAutoIncrement(s.EmptyValues[fieldName]) {
+				fieldGenerators[fieldName] = generator
+			}
+		}
+	}
+
+	if len(fieldGenerators) == 0 {
+		return nil
+	}
+
+	return &queries.Generators{
+		FieldGenerators: fieldGenerators,
+		EmptyValues:     s.EmptyValues,
+	}
+}
+
+// ValidateIndexes checks every index has a unique name and is made of fields
+// which are in the key or data settings
+func (s *TableSettings) ValidateIndexes() error {
+	names := map[string]bool{}
+	for _, index := range s.Indexes {
+		if names[index.Name] || len(index.FieldNames) == 0 {
+			return InvalidIndexError
+		}
+		names[index.Name] = true
+
+		for _, fieldName := range index.FieldNames {
+			if !s.HasField(fieldName) {
+				return InvalidIndexError
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the key and data settings against the fields of the entry
+// and key, returning a *SettingsError listing every problem
+func (s *TableSettings) Validate() error {
+	problems := []*SettingsProblem{}
+	addProblem := func(fieldName string, format string, args ...any) {
+		problems = append(problems, &SettingsProblem{
+			FieldName: fieldName,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	rows := []struct {
+		name     string
+		settings *fields.RowSettings
+	}{
+		{"key", s.KeySettings},
+		{"data", s.DataSettings},
+	}
+
+	inFieldOrder := map[string]bool{}
+	versionFieldName := ""
+	for _, row := range rows {
+		if row.settings == nil {
+			continue
+		}
+
+		for _, fieldName := range row.settings.FieldOrder {
+			if inFieldOrder[fieldName] {
+				addProblem(fieldName, "is in the field orders more than once")
+				continue
+			}
+			inFieldOrder[fieldName] = true
+
+			if _, ok := s.EmptyValues[fieldName]; !ok {
+				addProblem(fieldName, "is in the %s field order but is not a field of the entry", row.name)
+			}
+
+			if setting := row.settings.FieldSettings[fieldName]; setting != nil && setting.Version {
+				if versionFieldName != "" {
+					addProblem(fieldName, "is a version field but %s is already the version field", versionFieldName)
+				} else {
+					versionFieldName = fieldName
+				}
+			}
+		}
+
+		for _, fieldName := range utils.SortedKeys(row.settings.FieldSettings) {
+			if !utils.SliceContains(row.settings.FieldOrder, fieldName) {
+				addProblem(fieldName, "has %s field settings but is not in the %s field order", row.name, row.name)
+			}
+		}
+	}
+
+	for _, fieldName := range utils.SortedKeys(s.EmptyValues) {
+		if !inFieldOrder[fieldName] {
+			addProblem(fieldName, "is a field of the entry but is not in the key or data field order")
+		}
+	}
+
+	if s.keyEmptyValues != nil {
+		for _, fieldName := range utils.SortedKeys(s.keyEmptyValues) {
+			if s.KeySettings == nil || !utils.SliceContains(s.KeySettings.FieldOrder, fieldName) {
+				addProblem(fieldName, "is a field of the key but is not in the key field order")
+			}
+		}
+
+		if s.KeySettings != nil {
+			for _, fieldName := range s.KeySettings.FieldOrder {
+				_, inKey := s.keyEmptyValues[fieldName]
+				_, inEntry := s.EmptyValues[fieldName]
+				if !inKey && inEntry {
+					addProblem(fieldName, "is in the key field order but is not a field of the key")
+				}
+			}
+		}
+	}
+
+	for _, fieldName := range s.SortFieldNames {
+		if s.KeySettings == nil || !utils.SliceContains(s.KeySettings.FieldOrder, fieldName) {
+			addProblem(fieldName, "is a sort field but is not in the key field order")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &SettingsError{TableName: s.Name, Problems: problems}
+	}
+
+	return nil
+}
+
+// HasField reports whether fieldName is in the key or data settings
+func (s *TableSettings) HasField(fieldName string) bool {
+	inKey := s.KeySettings != nil && utils.SliceContains(s.KeySettings.FieldOrder, fieldName)
+	inData := s.DataSettings != nil && utils.SliceContains(s.DataSettings.FieldOrder, fieldName)
+	return inKey || inData
+}
+
+// ValidateScan checks every field referenced by filter, and every projected
+// field in fieldNames, is in the key or data settings
+func (s *TableSettings) ValidateScan(filter *compare.Filter, fieldNames []string) error {
+	for _, fieldName := range append(filter.FieldNames(), fieldNames...) {
+		if !s.HasField(fieldName) {
+			return FieldDoesNotExistError
+		}
+	}
+
+	return nil
+}
+
+// ValidateAggregates checks every field used by the aggregates or options is
+// in the key or data settings, and has a type the aggregate supports
+func (s *TableSettings) ValidateAggregates(aggregates []*queries.Aggregate, options *queries.AggregateOptions) error {
+	if err := s.ValidateScan(options.Filter, options.GroupBy); err != nil {
+		return err
+	}
+
+	for _, aggregate := range aggregates {
+		if aggregate.Func == queries.COUNT {
+			continue
+		} else if !s.HasField(aggregate.FieldName) {
+			return FieldDoesNotExistError
+		} else if _, ok := aggregate.EmptyResult(s.EmptyValues[aggregate.FieldName]); !ok {
+			return AggregateTypeError
+		}
+	}
+
+	return nil
+}
+
 func NewTableSettings(options ...func(*TableSettings)) *TableSettings {
 	settings := &TableSettings{}
 
@@ -51,9 +262,52 @@ func WithSortFieldNames(SortFieldNames fields.SortFieldNames) func(*TableSetting
 	}
 }
 
+func WithVisibilityTimeout(visibilityTimeout time.Duration) func(*TableSettings) {
+	return func(settings *TableSettings) {
+		settings.VisibilityTimeout = visibilityTimeout
+	}
+}
+
+// WithDeadLetterQueue moves messages which have been recieved maxAttempts
+// times to the queue named name
+func WithDeadLetterQueue(name string, maxAttempts int) func(*TableSettings) {
+	return func(settings *TableSettings) {
+		settings.DeadLetterQueueName = name
+		settings.MaxDeliveryAttempts = maxAttempts
+	}
+}
+
+func WithIndex(name string, fieldNames ...string) func(*TableSettings) {
+	return func(settings *TableSettings) {
+		settings.Indexes = append(settings.Indexes, &Index{
+			Name:       name,
+			FieldNames: fieldNames,
+		})
+	}
+}
+
+func WithUniqueIndex(name string, fieldNames ...string) func(*TableSettings) {
+	return func(settings *TableSettings) {
+		settings.Indexes = append(settings.Indexes, &Index{
+			Name:       name,
+			FieldNames: fieldNames,
+			Unique:     true,
+		})
+	}
+}
+
 func WithEntry[E any, PE mutator.Mutatable[E]]() func(*TableSettings) {
 	return func(settings *TableSettings) {
 		empty := mutator.MutatableFactory[E, PE]{}.Create()
 		settings.EmptyValues = empty.Mutator().GetFields()
 	}
 }
+
+// withKey lets Validate check the key settings of a keyed table against the
+// fields of its key
+func withKey[K any, PK mutator.Mutatable[K]]() func(*TableSettings) {
+	return func(settings *TableSettings) {
+		empty := mutator.MutatableFactory[K, PK]{}.Create()
+		settings.keyEmptyValues = empty.Mutator().GetFields()
+	}
+}
@@ -0,0 +1,137 @@
+package datastore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestRegisterValidatesSettings(t *testing.T) {
+	type validateExpectedVal struct {
+		fieldNames []string
+	}
+
+	dataSettings := func(fieldNames ...string) func(*datastore.TableSettings) {
+		return datastore.WithDataSettings(&fields.RowSettings{
+			FieldSettings: fields.NewFieldSettings(),
+			FieldOrder:    fieldNames,
+		})
+	}
+
+	tests := &testutils.Tests[[]func(*datastore.TableSettings), *validateExpectedVal]{
+		Cases: []testutils.TestCase[[]func(*datastore.TableSettings), *validateExpectedVal]{
+			{
+				Name: "accepts matching settings",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(datastoretest.MockSortKeySettings),
+					datastore.WithDataSettings(datastoretest.MockDataSettings),
+					datastore.WithSortFieldNames(datastoretest.MockSortFieldNames),
+				},
+				Expected: &validateExpectedVal{},
+			},
+			{
+				Name: "lists a misspelled field and the field it replaced",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(datastoretest.MockSortKeySettings),
+					dataSettings("Dta"),
+				},
+				Expected: &validateExpectedVal{fieldNames: []string{"Dta", datastoretest.DataKey}},
+			},
+			{
+				Name: "lists key fields in the data settings",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(&fields.RowSettings{
+						FieldOrder: fields.OrderedFieldKeys{datastoretest.IdKey},
+					}),
+					dataSettings(datastoretest.SortKey, datastoretest.DataKey),
+				},
+				Expected: &validateExpectedVal{fieldNames: []string{datastoretest.SortKey}},
+			},
+			{
+				Name: "lists fields in the field orders more than once",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(datastoretest.MockSortKeySettings),
+					dataSettings(datastoretest.DataKey, datastoretest.IdKey),
+				},
+				Expected: &validateExpectedVal{fieldNames: []string{datastoretest.IdKey}},
+			},
+			{
+				Name: "lists field settings for missing fields",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(datastoretest.MockSortKeySettings),
+					datastore.WithDataSettings(&fields.RowSettings{
+						FieldSettings: fields.NewFieldSettings(
+							fields.WithNumBytes("Missing", 63),
+						),
+						FieldOrder: fields.OrderedFieldKeys{datastoretest.DataKey},
+					}),
+				},
+				Expected: &validateExpectedVal{fieldNames: []string{"Missing"}},
+			},
+			{
+				Name: "lists version fields after the first",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(&fields.RowSettings{
+						FieldSettings: fields.NewFieldSettings(
+							fields.WithVersion(datastoretest.SortKey),
+						),
+						FieldOrder: fields.OrderedFieldKeys{datastoretest.IdKey, datastoretest.SortKey},
+					}),
+					datastore.WithDataSettings(&fields.RowSettings{
+						FieldSettings: fields.NewFieldSettings(
+							fields.WithVersion(datastoretest.DataKey),
+						),
+						FieldOrder: fields.OrderedFieldKeys{datastoretest.DataKey},
+					}),
+				},
+				Expected: &validateExpectedVal{fieldNames: []string{datastoretest.DataKey}},
+			},
+			{
+				Name: "lists sort fields which are not key fields",
+				Input: []func(*datastore.TableSettings){
+					datastore.WithKeySettings(datastoretest.MockSortKeySettings),
+					datastore.WithDataSettings(datastoretest.MockDataSettings),
+					datastore.WithSortFieldNames(fields.SortFieldNames{datastoretest.DataKey}),
+				},
+				Expected: &validateExpectedVal{fieldNames: []string{datastoretest.DataKey}},
+			},
+		},
+		Func: func(t *testing.T, input []func(*datastore.TableSettings), expected *validateExpectedVal) {
+			mockTable := &datastoretest.MockSortTable{
+				Settings: datastore.NewTableSettings(
+					append(input, datastore.WithTableName("TestValidate"))...,
+				),
+			}
+			group := datastore.NewConnectionGroup(
+				datastore.WithConnection(inmemory.NewConnection()),
+			)
+			err := group.RegisterTables(datastore.RegisterSortTable[*inmemory.Connection](mockTable, &inmemory.SortTableBackend{}))
+
+			if len(expected.fieldNames) == 0 {
+				testutils.AssertOk(t, err)
+				return
+			}
+
+			testutils.AssertErrorEquals(t, datastore.InvalidSettingsError, err)
+
+			var settingsErr *datastore.SettingsError
+			testutils.AssertTrue(t, errors.As(err, &settingsErr))
+			if settingsErr == nil {
+				return
+			}
+
+			testutils.AssertEquals(t, "TestValidate", settingsErr.TableName)
+			testutils.AssertEquals(t, len(expected.fieldNames), len(settingsErr.Problems))
+			for i := range settingsErr.Problems {
+				testutils.AssertEquals(t, expected.fieldNames[i], settingsErr.Problems[i].FieldName)
+			}
+		},
+	}
+
+	tests.Run(t)
+}
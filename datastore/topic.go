@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"context"
+
 	"github.com/sophielizg/go-libs/datastore/mutator"
 	"github.com/sophielizg/go-libs/datastore/queries"
 )
@@ -24,11 +26,19 @@ func (t *Topic[M, PM]) SetBackend(backend TopicBackendQueries) {
 }
 
 func (t *Topic[M, PM]) Publish(messages ...PM) error {
-	return t.backend.Publish(t.MessageFactory.CreateFieldValuesList(messages))
+	return t.PublishContext(context.Background(), messages...)
+}
+
+func (t *Topic[M, PM]) PublishContext(ctx context.Context, messages ...PM) error {
+	return t.backend.Publish(ctx, t.MessageFactory.CreateFieldValuesList(messages))
 }
 
 func (t *Topic[M, PM]) Subscribe(subscriptionId string) (*Subscription[M, PM], error) {
-	subscriptionBackend, err := t.backend.Subscribe(subscriptionId)
+	return t.SubscribeContext(context.Background(), subscriptionId)
+}
+
+func (t *Topic[M, PM]) SubscribeContext(ctx context.Context, subscriptionId string) (*Subscription[M, PM], error) {
+	subscriptionBackend, err := t.backend.Subscribe(ctx, subscriptionId)
 	if err != nil {
 		return nil, err
 	}
@@ -59,5 +69,9 @@ func (s *Subscription[M, PM]) SetBackend(backend SubscriptionBackendQueries) {
 }
 
 func (s *Subscription[M, PM]) Unsubscribe() error {
-	return s.backend.Unsubscribe()
+	return s.UnsubscribeContext(context.Background())
+}
+
+func (s *Subscription[M, PM]) UnsubscribeContext(ctx context.Context) error {
+	return s.backend.Unsubscribe(ctx)
 }
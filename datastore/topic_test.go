@@ -1,3 +1,144 @@
 package datastore_test
 
-// TODO add test for subscribe
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/backends/inmemory"
+	"github.com/sophielizg/go-libs/datastore/examples/shipping"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func newShippedTopic(t *testing.T) *shipping.Topic {
+	t.Helper()
+
+	topic := shipping.NewShippedTopic()
+	group := datastore.NewConnectionGroup(
+		datastore.WithConnection(inmemory.NewConnection()),
+	)
+	err := group.RegisterTables(
+		datastore.RegisterTopic[*inmemory.Connection](topic, &inmemory.TopicBackend{}),
+	)
+	testutils.AssertOk(t, err)
+
+	return topic
+}
+
+func newShippingMessage(name string) *shipping.Message {
+	return &shipping.Message{
+		Data: &shipping.Data{
+			Name: name,
+		},
+	}
+}
+
+func assertRecievesMessage(t *testing.T, subscription *shipping.Subscription, expectedName string) string {
+	t.Helper()
+
+	hasMessage, err := subscription.HasMessage()
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, hasMessage)
+
+	id, message, err := subscription.RecieveMessage()
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, expectedName, message.Data.Name)
+
+	return id
+}
+
+func TestSubscribe(t *testing.T) {
+	topic := newShippedTopic(t)
+
+	subscription, err := topic.Subscribe("test")
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, "test", subscription.Id)
+	testutils.AssertTrue(t, subscription.ParentTopic == topic)
+
+	hasMessage, err := subscription.HasMessage()
+	testutils.AssertOk(t, err)
+	testutils.AssertTrue(t, !hasMessage)
+}
+
+func TestPublish(t *testing.T) {
+	testutils.Case(t, "fans out to every subscription", func(t *testing.T) {
+		topic := newShippedTopic(t)
+
+		subscription1, err := topic.Subscribe("test1")
+		testutils.AssertOk(t, err)
+		subscription2, err := topic.Subscribe("test2")
+		testutils.AssertOk(t, err)
+
+		err = topic.Publish(newShippingMessage("message1"), newShippingMessage("message2"))
+		testutils.AssertOk(t, err)
+
+		for _, subscription := range []*shipping.Subscription{subscription1, subscription2} {
+			id1 := assertRecievesMessage(t, subscription, "message1")
+			id2 := assertRecievesMessage(t, subscription, "message2")
+			testutils.AssertOk(t, subscription.AckSuccess(id1, id2))
+
+			hasMessage, err := subscription.HasMessage()
+			testutils.AssertOk(t, err)
+			testutils.AssertTrue(t, !hasMessage)
+		}
+	})
+
+	testutils.Case(t, "does not deliver messages published before subscribing", func(t *testing.T) {
+		topic := newShippedTopic(t)
+
+		err := topic.Publish(newShippingMessage("message1"))
+		testutils.AssertOk(t, err)
+
+		subscription, err := topic.Subscribe("test")
+		testutils.AssertOk(t, err)
+
+		hasMessage, err := subscription.HasMessage()
+		testutils.AssertOk(t, err)
+		testutils.AssertTrue(t, !hasMessage)
+	})
+}
+
+func TestSubscriptionAck(t *testing.T) {
+	topic := newShippedTopic(t)
+
+	subscription, err := topic.Subscribe("test")
+	testutils.AssertOk(t, err)
+
+	err = topic.Publish(newShippingMessage("message1"), newShippingMessage("message2"))
+	testutils.AssertOk(t, err)
+
+	testutils.Case(t, "redelivers message after ack failure", func(t *testing.T) {
+		id := assertRecievesMessage(t, subscription, "message1")
+		testutils.AssertOk(t, subscription.AckFailure(id))
+
+		redeliveredId := assertRecievesMessage(t, subscription, "message1")
+		testutils.AssertEquals(t, id, redeliveredId)
+		testutils.AssertOk(t, subscription.AckSuccess(redeliveredId))
+	})
+
+	testutils.Case(t, "returns error acking message that is not in flight", func(t *testing.T) {
+		id := assertRecievesMessage(t, subscription, "message2")
+		testutils.AssertOk(t, subscription.AckSuccess(id))
+		testutils.AssertErrorEquals(t, inmemory.KeyDoesNotExistError, subscription.AckSuccess(id))
+	})
+}
+
+func TestUnsubscribe(t *testing.T) {
+	topic := newShippedTopic(t)
+
+	subscription1, err := topic.Subscribe("test1")
+	testutils.AssertOk(t, err)
+	subscription2, err := topic.Subscribe("test2")
+	testutils.AssertOk(t, err)
+
+	testutils.AssertOk(t, subscription1.Unsubscribe())
+
+	err = topic.Publish(newShippingMessage("message1"))
+	testutils.AssertOk(t, err)
+
+	_, err = subscription1.HasMessage()
+	testutils.AssertErrorEquals(t, inmemory.SubscriptionDoesNotExistError, err)
+	testutils.AssertErrorEquals(t, inmemory.SubscriptionDoesNotExistError, subscription1.Unsubscribe())
+
+	id := assertRecievesMessage(t, subscription2, "message1")
+	testutils.AssertOk(t, subscription2.AckSuccess(id))
+}
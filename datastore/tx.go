@@ -0,0 +1,44 @@
+package datastore
+
+import "context"
+
+// Tx is a transaction started by a TxConnection
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// TxConnection is a Connection which supports transactions. BeginTx returns
+// a context carrying the transaction, or joins the one ctx already carries.
+type TxConnection interface {
+	Connection
+	BeginTx(ctx context.Context) (context.Context, Tx, error)
+}
+
+// WithTx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back otherwise
+func (g *ConnectionGroup[C]) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	conn, ok := any(g.Conn).(TxConnection)
+	if !ok {
+		return TxNotSupportedError
+	}
+
+	txCtx, tx, err := conn.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(txCtx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,95 @@
+package datastore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+type mockTxKey struct{}
+
+type MockTx struct {
+	Committed  bool
+	RolledBack bool
+}
+
+func (tx *MockTx) Commit() error {
+	tx.Committed = true
+	return nil
+}
+
+func (tx *MockTx) Rollback() error {
+	tx.RolledBack = true
+	return nil
+}
+
+type MockTxConnection struct {
+	Tx *MockTx
+}
+
+func (c *MockTxConnection) Close() {}
+
+func (c *MockTxConnection) BeginTx(ctx context.Context) (context.Context, datastore.Tx, error) {
+	c.Tx = &MockTx{}
+	return context.WithValue(ctx, mockTxKey{}, c.Tx), c.Tx, nil
+}
+
+type MockConnection struct{}
+
+func (c *MockConnection) Close() {}
+
+func TestWithTx(t *testing.T) {
+	mockErr := errors.New("mock error")
+
+	newGroup := func() (*datastore.ConnectionGroup[*MockTxConnection], *MockTxConnection) {
+		conn := &MockTxConnection{}
+		return datastore.NewConnectionGroup(datastore.WithConnection(conn)), conn
+	}
+
+	testutils.Case(t, "commits when fn succeeds", func(t *testing.T) {
+		group, conn := newGroup()
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			testutils.AssertTrue(t, ctx.Value(mockTxKey{}) == conn.Tx)
+			return nil
+		})
+		testutils.AssertOk(t, err)
+		testutils.AssertTrue(t, conn.Tx.Committed)
+		testutils.AssertTrue(t, !conn.Tx.RolledBack)
+	})
+
+	testutils.Case(t, "rolls back when fn fails", func(t *testing.T) {
+		group, conn := newGroup()
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			return mockErr
+		})
+		testutils.AssertErrorEquals(t, mockErr, err)
+		testutils.AssertTrue(t, !conn.Tx.Committed)
+		testutils.AssertTrue(t, conn.Tx.RolledBack)
+	})
+
+	testutils.Case(t, "rolls back when fn panics", func(t *testing.T) {
+		group, conn := newGroup()
+		func() {
+			defer func() {
+				testutils.AssertTrue(t, recover() == mockErr)
+			}()
+
+			group.WithTx(context.Background(), func(ctx context.Context) error {
+				panic(mockErr)
+			})
+		}()
+		testutils.AssertTrue(t, !conn.Tx.Committed)
+		testutils.AssertTrue(t, conn.Tx.RolledBack)
+	})
+
+	testutils.Case(t, "returns error for connections without transactions", func(t *testing.T) {
+		group := datastore.NewConnectionGroup(datastore.WithConnection(&MockConnection{}))
+		err := group.WithTx(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+		testutils.AssertErrorEquals(t, datastore.TxNotSupportedError, err)
+	})
+}
@@ -0,0 +1,13 @@
+package datastoremysql
+
+import "github.com/sophielizg/go-libs/datastoresql"
+
+type AppendTableBackend struct {
+	conn *Connection
+	datastoresql.AppendTableBackend
+}
+
+func (b *AppendTableBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+	b.SetDB(conn.db, Dialect{})
+}
@@ -1,10 +1,13 @@
 package datastoremysql
 
 import (
+	"context"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastoresql"
 )
 
 type Connection struct {
@@ -13,7 +16,17 @@ type Connection struct {
 }
 
 func (c *Connection) Open() error {
-	db, err := sqlx.Connect("mysql", c.Config.DSNString)
+	dsn, err := mysql.ParseDSN(c.Config.DSNString)
+	if err != nil {
+		return err
+	}
+
+	// times are scanned directly into fields.Time, and updates report matched
+	// rather than changed rows so missing keys can be detected
+	dsn.ParseTime = true
+	dsn.ClientFoundRows = true
+
+	db, err := sqlx.Connect("mysql", dsn.FormatDSN())
 	if err != nil {
 		return err
 	}
@@ -22,6 +35,16 @@ func (c *Connection) Open() error {
 	db.SetMaxOpenConns(10)
 	db.SetMaxIdleConns(10)
 
+	if c.Config.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(c.Config.ConnMaxLifetime)
+	}
+	if c.Config.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(c.Config.MaxOpenConns)
+	}
+	if c.Config.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(c.Config.MaxIdleConns)
+	}
+
 	c.db = db
 	return nil
 }
@@ -30,6 +53,10 @@ func (c *Connection) Close() {
 	c.db.Close()
 }
 
+func (c *Connection) BeginTx(ctx context.Context) (context.Context, datastore.Tx, error) {
+	return datastoresql.BeginTx(ctx, c.db)
+}
+
 // Expose underlying db to query directly
 func (c *Connection) Db() *sqlx.DB {
 	return c.db
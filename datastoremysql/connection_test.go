@@ -0,0 +1,31 @@
+package datastoremysql_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastoremysql"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+// tests run against the database in DATASTOREMYSQL_TEST_DSN, which can be
+// any local mysql compatible server, and are skipped when it is not set
+const testDSNEnv = "DATASTOREMYSQL_TEST_DSN"
+
+func newTestConnection(t *testing.T) *datastoremysql.Connection {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	conn := &datastoremysql.Connection{
+		Config: datastoremysql.Config{
+			DSNString: dsn,
+		},
+	}
+	testutils.AssertOk(t, conn.Open())
+
+	return conn
+}
@@ -0,0 +1,206 @@
+package datastoremysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastoresql"
+)
+
+const (
+	defaultKeyNumBytes        = 255
+	duplicateEntryErrorNumber = 1062
+	maxPlaceholders           = 65535
+	// in utf8mb4 characters
+	maxVarcharLength    = 16383
+	maxKeyVarcharLength = 768
+)
+
+type Dialect struct{}
+
+func (d Dialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (d Dialect) IsDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == duplicateEntryErrorNumber
+}
+
+func (d Dialect) MaxPlaceholders() int {
+	return maxPlaceholders
+}
+
+// InsertIfAbsentQuery only skips the insert when the primary key exists, so
+// a row which violates a unique index still fails. INSERT IGNORE and ON
+// DUPLICATE KEY UPDATE would skip it too
+func (d Dialect) InsertIfAbsentQuery(tableName string, columnNames []string, primaryKey []string) string {
+	selected := make([]string, len(columnNames))
+	for i, columnName := range columnNames {
+		selected[i] = "? AS " + d.QuoteIdentifier(columnName)
+	}
+
+	matches := make([]string, len(primaryKey))
+	for i, keyName := range primaryKey {
+		matches[i] = fmt.Sprintf("existing.%s = inserted.%s", d.QuoteIdentifier(keyName), d.QuoteIdentifier(keyName))
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT * FROM (SELECT %s) AS inserted WHERE NOT EXISTS (SELECT 1 FROM %s AS existing WHERE %s)",
+		d.QuoteIdentifier(tableName),
+		d.quoteAll(columnNames),
+		strings.Join(selected, ", "),
+		d.QuoteIdentifier(tableName),
+		strings.Join(matches, " AND "),
+	)
+}
+
+func intColumnType(numBytes int, unsigned bool) string {
+	var columnType string
+	switch {
+	case numBytes == 1:
+		columnType = "TINYINT"
+	case numBytes == 2:
+		columnType = "SMALLINT"
+	case numBytes == 3:
+		columnType = "MEDIUMINT"
+	case numBytes == 4:
+		columnType = "INT"
+	default:
+		columnType = "BIGINT"
+	}
+
+	if unsigned {
+		columnType += " UNSIGNED"
+	}
+
+	return columnType
+}
+
+// stringColumnType treats numBytes as a number of characters, since that is
+// how mysql sizes a VARCHAR
+func stringColumnType(numBytes int, isKey bool) string {
+	// keys and indexed columns cannot be TEXT, so they need a length
+	if isKey {
+		if numBytes == 0 {
+			numBytes = defaultKeyNumBytes
+		} else if numBytes > maxKeyVarcharLength {
+			numBytes = maxKeyVarcharLength
+		}
+	}
+
+	if numBytes == 0 {
+		return "TEXT"
+	} else if numBytes > maxVarcharLength {
+		return "LONGTEXT"
+	}
+
+	return fmt.Sprintf("VARCHAR(%d)", numBytes)
+}
+
+// baseColumnType derives the mysql type for a field from the type of its
+// empty value, ie. the type declared on the entry struct
+func baseColumnType(column datastoresql.Column) (string, bool, error) {
+	switch column.EmptyValue.(type) {
+	case fields.Int, fields.BigInt:
+		return intColumnType(column.Setting.NumBytes, false), false, nil
+	case fields.NullInt, fields.NullBigInt:
+		return intColumnType(column.Setting.NumBytes, false), true, nil
+	case fields.UInt, fields.BigUInt:
+		return intColumnType(column.Setting.NumBytes, true), false, nil
+	case fields.NullUInt, fields.NullBigUInt:
+		return intColumnType(column.Setting.NumBytes, true), true, nil
+	case fields.SmallFloat:
+		return "FLOAT", false, nil
+	case fields.NullSmallFloat:
+		return "FLOAT", true, nil
+	case fields.Float:
+		return "DOUBLE", false, nil
+	case fields.NullFloat:
+		return "DOUBLE", true, nil
+	case fields.String:
+		return stringColumnType(column.Setting.NumBytes, column.IsKey || column.IsIndexed), false, nil
+	case fields.NullString:
+		return stringColumnType(column.Setting.NumBytes, column.IsKey || column.IsIndexed), true, nil
+	case fields.Bool:
+		return "BOOLEAN", false, nil
+	case fields.NullBool:
+		return "BOOLEAN", true, nil
+	case fields.Time:
+		return "DATETIME(6)", false, nil
+	case fields.NullTime:
+		return "DATETIME(6)", true, nil
+	case fields.JsonMap, fields.JsonList:
+		return "JSON", true, nil
+	default:
+		return "", false, datastoresql.UnsupportedFieldTypeError
+	}
+}
+
+// columnType is the full mysql column definition for a field
+func columnType(column datastoresql.Column) (string, error) {
+	columnType, nullable, err := baseColumnType(column)
+	if err != nil {
+		return "", err
+	}
+
+	if column.IsAutoIncrement() {
+		if !fields.IsIntField(column.EmptyValue) {
+			return "", datastoresql.AutoGenerateNotSupportedError
+		}
+
+		columnType += " AUTO_INCREMENT"
+	}
+
+	if !nullable {
+		columnType += " NOT NULL"
+	}
+
+	return columnType, nil
+}
+
+func (d Dialect) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// CreateTableQueries defines indexes in the CREATE TABLE statement, since
+// mysql has no CREATE INDEX IF NOT EXISTS
+func (d Dialect) CreateTableQueries(tableName string, columns []datastoresql.Column, primaryKey []string, indexes []*datastore.Index) ([]string, error) {
+	definitions := make([]string, 0, len(columns)+len(indexes)+1)
+	for _, column := range columns {
+		columnType, err := columnType(column)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column.Name, err)
+		}
+
+		definitions = append(definitions, d.QuoteIdentifier(column.Name)+" "+columnType)
+	}
+
+	if len(primaryKey) > 0 {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", d.quoteAll(primaryKey)))
+	}
+
+	for _, index := range indexes {
+		keyType := "KEY"
+		if index.Unique {
+			keyType = "UNIQUE KEY"
+		}
+
+		definitions = append(definitions, fmt.Sprintf("%s %s (%s)", keyType, d.QuoteIdentifier(index.Name), d.quoteAll(index.FieldNames)))
+	}
+
+	return []string{fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s)",
+		d.QuoteIdentifier(tableName),
+		strings.Join(definitions, ", "),
+	)}, nil
+}
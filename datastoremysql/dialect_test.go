@@ -0,0 +1,49 @@
+package datastoremysql
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestStringColumnType(t *testing.T) {
+	type stringColumnTypeInputVal struct {
+		numBytes int
+		isKey    bool
+	}
+
+	tests := &testutils.Tests[*stringColumnTypeInputVal, string]{
+		Cases: []testutils.TestCase[*stringColumnTypeInputVal, string]{
+			{
+				Name:     "uses text without a size",
+				Input:    &stringColumnTypeInputVal{},
+				Expected: "TEXT",
+			},
+			{
+				Name:     "uses a varchar of the size",
+				Input:    &stringColumnTypeInputVal{numBytes: 63},
+				Expected: "VARCHAR(63)",
+			},
+			{
+				Name:     "uses longtext over the longest varchar",
+				Input:    &stringColumnTypeInputVal{numBytes: maxVarcharLength + 1},
+				Expected: "LONGTEXT",
+			},
+			{
+				Name:     "gives keys without a size the default",
+				Input:    &stringColumnTypeInputVal{isKey: true},
+				Expected: "VARCHAR(255)",
+			},
+			{
+				Name:     "caps keys at the longest indexable varchar",
+				Input:    &stringColumnTypeInputVal{numBytes: 1024, isKey: true},
+				Expected: "VARCHAR(768)",
+			},
+		},
+		Func: func(t *testing.T, input *stringColumnTypeInputVal, expected string) {
+			testutils.AssertEquals(t, expected, stringColumnType(input.numBytes, input.isKey))
+		},
+	}
+
+	tests.Run(t)
+}
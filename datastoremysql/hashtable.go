@@ -0,0 +1,13 @@
+package datastoremysql
+
+import "github.com/sophielizg/go-libs/datastoresql"
+
+type HashTableBackend struct {
+	conn *Connection
+	datastoresql.HashTableBackend
+}
+
+func (b *HashTableBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+	b.SetDB(conn.db, Dialect{})
+}
@@ -0,0 +1,16 @@
+package datastoremysql_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastoremysql"
+)
+
+func TestHashTableBackend(t *testing.T) {
+	datastoretest.RunHashTableSuite(t, func(t *testing.T) (*datastoremysql.Connection, *datastoremysql.HashTableBackend) {
+		conn := newTestConnection(t)
+		t.Cleanup(func() { conn.Close() })
+		return conn, &datastoremysql.HashTableBackend{}
+	})
+}
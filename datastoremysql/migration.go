@@ -0,0 +1,161 @@
+package datastoremysql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastoresql"
+)
+
+// intDisplayWidth matches the display widths older versions of mysql report
+// for int types, which do not change what the column stores
+var intDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+
+// normalizeColumnType lets a type reported by the database be compared to
+// the type it was created with
+func normalizeColumnType(columnType string) string {
+	columnType = strings.ToLower(columnType)
+	if columnType == "boolean" || columnType == "bool" {
+		return "tinyint(1)"
+	} else if columnType == "tinyint(1)" {
+		return columnType
+	}
+
+	return intDisplayWidth.ReplaceAllString(columnType, "$1")
+}
+
+type storedColumnRow struct {
+	Name       string `db:"name"`
+	Type       string `db:"type"`
+	IsNullable string `db:"is_nullable"`
+	Extra      string `db:"extra"`
+}
+
+func (d Dialect) StoredTable(ctx context.Context, db sqlx.QueryerContext, tableName string) (*datastoresql.StoredTable, error) {
+	rows := []*storedColumnRow{}
+	err := sqlx.SelectContext(ctx, db, &rows, `
+		SELECT COLUMN_NAME AS name, COLUMN_TYPE AS type, IS_NULLABLE AS is_nullable, EXTRA AS extra
+		FROM information_schema.columns
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`,
+		tableName,
+	)
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return nil, nil
+	}
+
+	stored := &datastoresql.StoredTable{
+		Columns: make([]*datastoresql.StoredColumn, len(rows)),
+	}
+	for i, row := range rows {
+		stored.Columns[i] = &datastoresql.StoredColumn{
+			Name:          row.Name,
+			Type:          row.Type,
+			Nullable:      row.IsNullable == "YES",
+			AutoIncrement: strings.Contains(strings.ToLower(row.Extra), "auto_increment"),
+		}
+	}
+
+	err = sqlx.SelectContext(ctx, db, &stored.PrimaryKey, `
+		SELECT COLUMN_NAME
+		FROM information_schema.statistics
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY'
+		ORDER BY SEQ_IN_INDEX`,
+		tableName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func (d Dialect) ColumnMatches(stored *datastoresql.StoredColumn, column datastoresql.Column) (bool, error) {
+	columnType, nullable, err := baseColumnType(column)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", column.Name, err)
+	}
+
+	return normalizeColumnType(stored.Type) == normalizeColumnType(columnType) &&
+		stored.Nullable == nullable &&
+		stored.AutoIncrement == column.IsAutoIncrement(), nil
+}
+
+func (d Dialect) AddColumnQuery(tableName string, column datastoresql.Column, after string) (string, error) {
+	columnType, err := columnType(column)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", column.Name, err)
+	}
+
+	position := "FIRST"
+	if after != "" {
+		position = "AFTER " + d.QuoteIdentifier(after)
+	}
+
+	return fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN %s %s %s",
+		d.QuoteIdentifier(tableName),
+		d.QuoteIdentifier(column.Name),
+		columnType,
+		position,
+	), nil
+}
+
+func (d Dialect) AlterColumnQuery(tableName string, column datastoresql.Column) (string, error) {
+	columnType, err := columnType(column)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", column.Name, err)
+	}
+
+	return fmt.Sprintf(
+		"ALTER TABLE %s MODIFY COLUMN %s %s",
+		d.QuoteIdentifier(tableName),
+		d.QuoteIdentifier(column.Name),
+		columnType,
+	), nil
+}
+
+func (d Dialect) MoveColumnQuery(tableName string, column datastoresql.Column, after string) (string, error) {
+	query, err := d.AlterColumnQuery(tableName, column)
+	if err != nil {
+		return "", err
+	}
+
+	if after == "" {
+		return query + " FIRST", nil
+	}
+
+	return query + " AFTER " + d.QuoteIdentifier(after), nil
+}
+
+func (d Dialect) DropColumnQuery(tableName string, columnName string) string {
+	return fmt.Sprintf(
+		"ALTER TABLE %s DROP COLUMN %s",
+		d.QuoteIdentifier(tableName),
+		d.QuoteIdentifier(columnName),
+	)
+}
+
+func (d Dialect) AlterPrimaryKeyQuery(tableName string, storedKey []string, primaryKey []string) (string, error) {
+	changes := []string{}
+	if len(storedKey) > 0 {
+		changes = append(changes, "DROP PRIMARY KEY")
+	}
+
+	if len(primaryKey) > 0 {
+		changes = append(changes, fmt.Sprintf("ADD PRIMARY KEY (%s)", d.quoteAll(primaryKey)))
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s %s", d.QuoteIdentifier(tableName), strings.Join(changes, ", ")), nil
+}
+
+// TransactionalDDL is false, since mysql commits the open transaction before
+// changing a schema
+func (d Dialect) TransactionalDDL() bool {
+	return false
+}
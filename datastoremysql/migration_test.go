@@ -0,0 +1,129 @@
+package datastoremysql
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastoresql"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestNormalizeColumnType(t *testing.T) {
+	tests := &testutils.Tests[string, string]{
+		Cases: []testutils.TestCase[string, string]{
+			{
+				Name:     "lowercases types",
+				Input:    "VARCHAR(255)",
+				Expected: "varchar(255)",
+			},
+			{
+				Name:     "treats boolean as tinyint(1)",
+				Input:    "BOOLEAN",
+				Expected: "tinyint(1)",
+			},
+			{
+				Name:     "keeps the width of tinyint(1)",
+				Input:    "tinyint(1)",
+				Expected: "tinyint(1)",
+			},
+			{
+				Name:     "drops int display widths",
+				Input:    "int(11)",
+				Expected: "int",
+			},
+			{
+				Name:     "drops display widths of unsigned ints",
+				Input:    "bigint(20) unsigned",
+				Expected: "bigint unsigned",
+			},
+		},
+		Func: func(t *testing.T, input string, expected string) {
+			testutils.AssertEquals(t, expected, normalizeColumnType(input))
+		},
+	}
+
+	tests.Run(t)
+}
+
+func TestMigrationQueries(t *testing.T) {
+	d := Dialect{}
+	countColumn := datastoresql.Column{
+		Name:       "Count",
+		EmptyValue: fields.Int(0),
+		Setting:    &fields.FieldSetting{NumBytes: 4},
+	}
+	dataColumn := datastoresql.Column{
+		Name:       "Data",
+		EmptyValue: fields.String(""),
+		Setting:    &fields.FieldSetting{NumBytes: 63},
+	}
+	unsupportedColumn := datastoresql.Column{
+		Name:       "Unsupported",
+		EmptyValue: struct{}{},
+		Setting:    &fields.FieldSetting{},
+	}
+
+	testutils.Case(t, "adds a column after another", func(t *testing.T) {
+		query, err := d.AddColumnQuery("Test", countColumn, "Data")
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` ADD COLUMN `Count` INT NOT NULL AFTER `Data`", query)
+	})
+
+	testutils.Case(t, "adds a column first", func(t *testing.T) {
+		query, err := d.AddColumnQuery("Test", countColumn, "")
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` ADD COLUMN `Count` INT NOT NULL FIRST", query)
+	})
+
+	testutils.Case(t, "modifies a column", func(t *testing.T) {
+		query, err := d.AlterColumnQuery("Test", dataColumn)
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` MODIFY COLUMN `Data` VARCHAR(63) NOT NULL", query)
+	})
+
+	testutils.Case(t, "moves a column after another", func(t *testing.T) {
+		query, err := d.MoveColumnQuery("Test", dataColumn, "Count")
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` MODIFY COLUMN `Data` VARCHAR(63) NOT NULL AFTER `Count`", query)
+	})
+
+	testutils.Case(t, "moves a column first", func(t *testing.T) {
+		query, err := d.MoveColumnQuery("Test", dataColumn, "")
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` MODIFY COLUMN `Data` VARCHAR(63) NOT NULL FIRST", query)
+	})
+
+	testutils.Case(t, "returns unsupported field types", func(t *testing.T) {
+		_, err := d.AddColumnQuery("Test", unsupportedColumn, "")
+		testutils.AssertErrorEquals(t, datastoresql.UnsupportedFieldTypeError, err)
+
+		_, err = d.AlterColumnQuery("Test", unsupportedColumn)
+		testutils.AssertErrorEquals(t, datastoresql.UnsupportedFieldTypeError, err)
+	})
+
+	testutils.Case(t, "drops a column", func(t *testing.T) {
+		testutils.AssertEquals(t, "ALTER TABLE `Test` DROP COLUMN `Legacy`", d.DropColumnQuery("Test", "Legacy"))
+	})
+
+	testutils.Case(t, "replaces the primary key", func(t *testing.T) {
+		query, err := d.AlterPrimaryKeyQuery("Test", []string{"Id"}, []string{"Id", "Sort"})
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` DROP PRIMARY KEY, ADD PRIMARY KEY (`Id`, `Sort`)", query)
+	})
+
+	testutils.Case(t, "adds a primary key to a table without one", func(t *testing.T) {
+		query, err := d.AlterPrimaryKeyQuery("Test", nil, []string{"Id"})
+		testutils.AssertOk(t, err)
+		testutils.AssertEquals(t, "ALTER TABLE `Test` ADD PRIMARY KEY (`Id`)", query)
+	})
+
+	testutils.Case(t, "matches stored columns with display widths", func(t *testing.T) {
+		matches, err := d.ColumnMatches(&datastoresql.StoredColumn{Name: "Count", Type: "int(11)"}, countColumn)
+		testutils.AssertOk(t, err)
+		testutils.AssertTrue(t, matches)
+
+		matches, err = d.ColumnMatches(&datastoresql.StoredColumn{Name: "Data", Type: "varchar(31)"}, dataColumn)
+		testutils.AssertOk(t, err)
+		testutils.AssertTrue(t, !matches)
+	})
+}
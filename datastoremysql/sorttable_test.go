@@ -0,0 +1,16 @@
+package datastoremysql_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastoremysql"
+)
+
+func TestSortTableBackend(t *testing.T) {
+	datastoretest.RunSortTableSuite(t, func(t *testing.T) (*datastoremysql.Connection, *datastoremysql.SortTableBackend) {
+		conn := newTestConnection(t)
+		t.Cleanup(func() { conn.Close() })
+		return conn, &datastoremysql.SortTableBackend{}
+	})
+}
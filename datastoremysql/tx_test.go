@@ -0,0 +1,25 @@
+package datastoremysql_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastoremysql"
+)
+
+func TestTx(t *testing.T) {
+	datastoretest.RunTxSuite(
+		t,
+		func(t *testing.T) *datastoremysql.Connection {
+			conn := newTestConnection(t)
+			t.Cleanup(func() { conn.Close() })
+			return conn
+		},
+		func() *datastoremysql.HashTableBackend {
+			return &datastoremysql.HashTableBackend{}
+		},
+		func() *datastoremysql.SortTableBackend {
+			return &datastoremysql.SortTableBackend{}
+		},
+	)
+}
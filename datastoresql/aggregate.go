@@ -0,0 +1,160 @@
+package datastoresql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+)
+
+// timeLayouts are the formats times may be returned in by aggregate functions,
+// which lose the column type some drivers use to parse them
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+func parseTime(value string) (fields.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+
+	return fields.Time{}, fmt.Errorf("unable to parse aggregate time %q", value)
+}
+
+// aggregateValue converts the value of an aggregate column to the type of
+// emptyResult, which is returned when the value is null
+func aggregateValue(emptyResult any, value any) (any, error) {
+	if value == nil {
+		return emptyResult, nil
+	}
+
+	str := fmt.Sprint(value)
+	if bytes, ok := value.([]byte); ok {
+		str = string(bytes)
+	}
+
+	if _, ok := emptyResult.(fields.Time); ok {
+		if timeValue, ok := value.(time.Time); ok {
+			return timeValue.UTC(), nil
+		}
+
+		return parseTime(str)
+	}
+
+	result := reflect.New(reflect.TypeOf(emptyResult)).Elem()
+	if result.CanInt() {
+		parsed, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result.SetInt(parsed)
+	} else if result.CanUint() {
+		parsed, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		result.SetUint(parsed)
+	} else {
+		parsed, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, err
+		}
+		result.SetFloat(parsed)
+	}
+
+	return result.Interface(), nil
+}
+
+func (b *Backend) aggregateColumn(aggregate *queries.Aggregate) (string, error) {
+	switch aggregate.Func {
+	case queries.COUNT:
+		return "COUNT(*)", nil
+	case queries.SUM:
+		return fmt.Sprintf("SUM(%s)", b.quote(aggregate.FieldName)), nil
+	case queries.MIN:
+		return fmt.Sprintf("MIN(%s)", b.quote(aggregate.FieldName)), nil
+	case queries.MAX:
+		return fmt.Sprintf("MAX(%s)", b.quote(aggregate.FieldName)), nil
+	case queries.AVG:
+		return fmt.Sprintf("AVG(%s)", b.quote(aggregate.FieldName)), nil
+	default:
+		return "", fmt.Errorf("unknown aggregate function %d: %w", aggregate.Func, AggregateTypeError)
+	}
+}
+
+func (b *Backend) Aggregate(ctx context.Context, aggregates []*queries.Aggregate, options *queries.AggregateOptions) ([]*queries.AggregateFields, error) {
+	if err := b.settings.ValidateAggregates(aggregates, options); err != nil {
+		return nil, err
+	}
+
+	where, values, err := b.filterClause(options.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groupBy := strings.Join(b.quoteAll(options.GroupBy), ", ")
+	columns := b.quoteAll(options.GroupBy)
+	for _, aggregate := range aggregates {
+		column, err := b.aggregateColumn(aggregate)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), b.tableName())
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	if groupBy != "" {
+		query += fmt.Sprintf(" GROUP BY %s ORDER BY %s", groupBy, groupBy)
+	}
+
+	rows, err := b.querier(ctx).QueryxContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*queries.AggregateFields{}
+	for rows.Next() {
+		aggregateDests := make([]any, len(aggregates))
+		extraDests := make([]any, len(aggregates))
+		for i := range aggregateDests {
+			extraDests[i] = &aggregateDests[i]
+		}
+
+		group, err := scanRow(rows, b.settings, options.GroupBy, extraDests...)
+		if err != nil {
+			return nil, err
+		}
+
+		aggregateValues := mutator.MappedFieldValues{}
+		for i, aggregate := range aggregates {
+			emptyResult, _ := aggregate.EmptyResult(b.settings.EmptyValues[aggregate.FieldName])
+			if aggregateValues[aggregate.Name], err = aggregateValue(emptyResult, aggregateDests[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, &queries.AggregateFields{
+			Group:  group,
+			Values: aggregateValues,
+		})
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,27 @@
+package datastoresql
+
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+type AppendTableBackend struct {
+	Backend
+}
+
+func (b *AppendTableBackend) Register() error {
+	// auto increment columns must be part of a key in most databases
+	return b.createTable(autoGenerateFieldNames(b.settings, b.settings.DataSettings))
+}
+
+func (b *AppendTableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	return b.scan(ctx, batchSize, nil, filter, fieldNames)
+}
+
+// ScanPage orders entries by their auto generated fields, so it returns
+// PageNotSupportedError for tables without any
+func (b *AppendTableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	return b.page(ctx, "", nil, autoGenerateFieldNames(b.settings, b.settings.DataSettings), cursor, limit)
+}
@@ -0,0 +1,907 @@
+package datastoresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/utils"
+)
+
+// Backend holds the queries shared by every sql table kind
+type Backend struct {
+	db       *sqlx.DB
+	dialect  Dialect
+	settings *datastore.TableSettings
+	// primaryKeyNames and extraColumns are kept from createTable to plan
+	// migrations with
+	primaryKeyNames []string
+	extraColumns    []Column
+}
+
+func (b *Backend) SetSettings(settings *datastore.TableSettings) {
+	b.settings = settings
+}
+
+func (b *Backend) SetDB(db *sqlx.DB, dialect Dialect) {
+	b.db = db
+	b.dialect = dialect
+}
+
+// maxBatchRows caps the rows inserted by one statement, which keeps large
+// entries within the packet sizes databases accept by default
+const maxBatchRows = 1000
+
+// MaxBatchSize limits the entries added in one call so an insert of all of
+// them stays within the placeholders the dialect allows
+func (b *Backend) MaxBatchSize() int {
+	numFields := len(allFieldNames(b.settings))
+	if b.dialect == nil || numFields == 0 {
+		return maxBatchRows
+	}
+
+	if maxRows := b.dialect.MaxPlaceholders() / numFields; maxRows < maxBatchRows {
+		return maxRows
+	}
+
+	return maxBatchRows
+}
+
+func (b *Backend) quote(name string) string {
+	return b.dialect.QuoteIdentifier(name)
+}
+
+func (b *Backend) quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = b.quote(name)
+	}
+
+	return quoted
+}
+
+func (b *Backend) tableName() string {
+	return b.quote(b.settings.Name)
+}
+
+func (b *Backend) columns(rowSettings *fields.RowSettings, isKey bool) []Column {
+	if rowSettings == nil {
+		return nil
+	}
+
+	columns := make([]Column, len(rowSettings.FieldOrder))
+	for i, fieldName := range rowSettings.FieldOrder {
+		setting := rowSettings.FieldSettings[fieldName]
+		if setting == nil {
+			setting = &fields.FieldSetting{}
+		}
+
+		columns[i] = Column{
+			Name:       fieldName,
+			EmptyValue: b.settings.EmptyValues[fieldName],
+			Setting:    setting,
+			IsKey:      isKey,
+			IsIndexed:  b.isIndexed(fieldName),
+		}
+	}
+
+	return columns
+}
+
+// tableColumns are the extra columns followed by the key and data fields
+func (b *Backend) tableColumns() []Column {
+	columns := append([]Column{}, b.extraColumns...)
+	columns = append(columns, b.columns(b.settings.KeySettings, true)...)
+	return append(columns, b.columns(b.settings.DataSettings, false)...)
+}
+
+// createTable creates the table for the settings if it does not exist, with
+// any extraColumns used internally by the backend before the entry fields
+func (b *Backend) createTable(primaryKey []string, extraColumns ...Column) error {
+	if err := validateAutoGenerateSettings(b.settings); err != nil {
+		return err
+	} else if err := validateVersionSettings(b.settings); err != nil {
+		return err
+	} else if err := b.settings.ValidateIndexes(); err != nil {
+		return err
+	}
+
+	b.primaryKeyNames, b.extraColumns = primaryKey, extraColumns
+
+	queries, err := b.dialect.CreateTableQueries(b.settings.Name, b.tableColumns(), primaryKey, b.settings.Indexes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", b.settings.Name, err)
+	}
+
+	for _, query := range queries {
+		if _, err := b.db.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Backend) isIndexed(fieldName string) bool {
+	for _, index := range b.settings.Indexes {
+		if utils.SliceContains(index.FieldNames, fieldName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Backend) Drop() error {
+	_, err := b.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", b.tableName()))
+	return err
+}
+
+func (b *Backend) Count(ctx context.Context) (int, error) {
+	var count int
+	err := b.querier(ctx).GetContext(ctx, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", b.tableName()))
+	return count, err
+}
+
+func (b *Backend) selectQuery(fieldNames []string, where string, orderBy []string) string {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s",
+		strings.Join(b.quoteAll(fieldNames), ", "),
+		b.tableName(),
+	)
+
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	if len(orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(b.quoteAll(orderBy), ", ")
+	}
+
+	return query
+}
+
+// scan sends the rows matching filter ordered by orderBy, with only the
+// columns in fieldNames or every column if there are none
+func (b *Backend) scan(ctx context.Context, batchSize int, orderBy []string, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	outChan := make(chan mutator.MappedFieldValues, batchSize)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer close(outChan)
+		defer close(errorChan)
+
+		if err := b.settings.ValidateScan(filter, fieldNames); err != nil {
+			errorChan <- err
+			return
+		}
+
+		where, values, err := b.filterClause(filter)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+
+		if len(fieldNames) == 0 {
+			fieldNames = allFieldNames(b.settings)
+		}
+
+		rows, err := b.querier(ctx).QueryxContext(ctx, b.selectQuery(fieldNames, where, orderBy), values...)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			entry, err := scanRow(rows, b.settings, fieldNames)
+			if err != nil {
+				errorChan <- err
+				return
+			}
+
+			select {
+			case outChan <- entry:
+			case <-ctx.Done():
+				errorChan <- ctx.Err()
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			errorChan <- err
+		}
+	}()
+
+	return outChan, errorChan
+}
+
+// afterClause matches the rows ordered after values by the orderBy fields
+func (b *Backend) afterClause(orderBy []string, values mutator.MappedFieldValues) (string, []any, error) {
+	conditions := make([]string, len(orderBy))
+	conditionValues := []any{}
+	for i, fieldName := range orderBy {
+		parts := []string{}
+		for _, equalFieldName := range orderBy[:i] {
+			parts = append(parts, b.quote(equalFieldName)+" = ?")
+		}
+		parts = append(parts, b.quote(fieldName)+" > ?")
+		conditions[i] = "(" + strings.Join(parts, " AND ") + ")"
+
+		partValues, err := toColumnValues(orderBy[:i+1], values)
+		if err != nil {
+			return "", nil, err
+		}
+		conditionValues = append(conditionValues, partValues...)
+	}
+
+	return strings.Join(conditions, " OR "), conditionValues, nil
+}
+
+// page returns up to limit rows matching where which are ordered after the
+// cursor by orderBy, and the cursor of the last row if there are more
+func (b *Backend) page(ctx context.Context, where string, values []any, orderBy []string, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	if len(orderBy) == 0 {
+		return nil, "", PageNotSupportedError
+	}
+
+	if cursor != "" {
+		cursorValues, err := datastore.DecodeCursor(cursor, b.settings.EmptyValues, orderBy)
+		if err != nil {
+			return nil, "", err
+		}
+
+		afterWhere, afterValues, err := b.afterClause(orderBy, cursorValues)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if where == "" {
+			where = afterWhere
+		} else {
+			where = "(" + where + ") AND (" + afterWhere + ")"
+		}
+		values = append(values, afterValues...)
+	}
+
+	// one extra row is read to know whether there is another page
+	fieldNames := allFieldNames(b.settings)
+	query := fmt.Sprintf("%s LIMIT %d", b.selectQuery(fieldNames, where, orderBy), limit+1)
+	rows, err := b.querier(ctx).QueryxContext(ctx, query, values...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entries, err := scanRows(rows, b.settings, fieldNames)
+	if err != nil || len(entries) <= limit {
+		return entries, "", err
+	}
+
+	entries = entries[:limit]
+	cursorValues := mutator.MappedFieldValues{}
+	for _, fieldName := range orderBy {
+		cursorValues[fieldName] = entries[limit-1][fieldName]
+	}
+
+	nextCursor, err := datastore.EncodeCursor(cursorValues)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entries, nextCursor, nil
+}
+
+func (b *Backend) insertQuery(fieldNames []string, numRows int) string {
+	rowPlaceholders := "(" + placeholders(len(fieldNames)) + ")"
+	allPlaceholders := strings.TrimSuffix(strings.Repeat(rowPlaceholders+", ", numRows), ", ")
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		b.tableName(),
+		strings.Join(b.quoteAll(fieldNames), ", "),
+		allPlaceholders,
+	)
+}
+
+// insertFieldNames returns the fields given values when a row is inserted,
+// and the fields the database generates values for
+func (b *Backend) insertFieldNames() ([]string, []string) {
+	autoGenerateFieldNames := append(
+		autoGenerateFieldNames(b.settings, b.settings.KeySettings),
+		autoGenerateFieldNames(b.settings, b.settings.DataSettings)...,
+	)
+
+	insertFieldNames := []string{}
+	for _, fieldName := range allFieldNames(b.settings) {
+		if !utils.SliceContains(autoGenerateFieldNames, fieldName) {
+			insertFieldNames = append(insertFieldNames, fieldName)
+		}
+	}
+
+	return insertFieldNames, autoGenerateFieldNames
+}
+
+// withGeneratedValues copies entry with the values generated when it was
+// inserted
+func (b *Backend) withGeneratedValues(entry mutator.MappedFieldValues, autoGenerateFieldNames []string, result sql.Result) (mutator.MappedFieldValues, error) {
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	generated := utils.MergeMaps(entry)
+	for _, fieldName := range autoGenerateFieldNames {
+		generated[fieldName], err = fields.IntValue(b.settings.EmptyValues[fieldName], id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return generated, nil
+}
+
+func (b *Backend) Add(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	insertFieldNames, autoGenerateFieldNames := b.insertFieldNames()
+
+	if len(autoGenerateFieldNames) == 0 {
+		values := []any{}
+		for _, entry := range entries {
+			entryValues, err := toColumnValues(insertFieldNames, entry)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, entryValues...)
+		}
+
+		_, err := b.querier(ctx).ExecContext(ctx, b.insertQuery(insertFieldNames, len(entries)), values...)
+		if err != nil && b.dialect.IsDuplicateKeyError(err) {
+			return nil, KeyExistsError
+		} else if err != nil {
+			return nil, err
+		}
+
+		return entries, nil
+	}
+
+	// rows with generated values are inserted one at a time so each generated
+	// id can be read back and returned with its entry
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := b.insertQuery(insertFieldNames, 1)
+	added := make([]mutator.MappedFieldValues, len(entries))
+	for i, entry := range entries {
+		values, err := toColumnValues(insertFieldNames, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := b.querier(ctx).ExecContext(ctx, query, values...)
+		if err != nil && b.dialect.IsDuplicateKeyError(err) {
+			return nil, KeyExistsError
+		} else if err != nil {
+			return nil, err
+		}
+
+		if added[i], err = b.withGeneratedValues(entry, autoGenerateFieldNames, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return added, tx.Commit()
+}
+
+// equalsClause creates a condition matching every field in fieldNames to its
+// value in entry
+func (b *Backend) equalsClause(fieldNames []string, entry mutator.MappedFieldValues) (string, []any, error) {
+	if len(fieldNames) == 0 {
+		return "1 = 1", []any{}, nil
+	}
+
+	conditions := make([]string, len(fieldNames))
+	for i, fieldName := range fieldNames {
+		conditions[i] = b.quote(fieldName) + " = ?"
+	}
+
+	values, err := toColumnValues(fieldNames, entry)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", values, nil
+}
+
+// filterClause translates filter into a where clause and its values, which
+// is empty when the filter is nil
+func (b *Backend) filterClause(filter *compare.Filter) (string, []any, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+
+	switch filter.Op {
+	case compare.FIELD:
+		return b.comparatorClause(filter.FieldName, filter.Comparator)
+	case compare.AND, compare.OR:
+		if len(filter.Filters) == 0 {
+			// match the in memory results for empty filters
+			if filter.Op == compare.AND {
+				return "1 = 1", nil, nil
+			}
+			return "1 = 0", nil, nil
+		}
+
+		separator := " AND "
+		if filter.Op == compare.OR {
+			separator = " OR "
+		}
+
+		conditions := make([]string, len(filter.Filters))
+		values := []any{}
+		for i, subFilter := range filter.Filters {
+			condition, subValues, err := b.filterClause(subFilter)
+			if err != nil {
+				return "", nil, err
+			} else if condition == "" {
+				condition = "1 = 1"
+			}
+
+			conditions[i] = "(" + condition + ")"
+			values = append(values, subValues...)
+		}
+
+		return strings.Join(conditions, separator), values, nil
+	case compare.NOT:
+		if len(filter.Filters) != 1 {
+			return "", nil, compare.FilterValuesError
+		}
+
+		condition, values, err := b.filterClause(filter.Filters[0])
+		if err != nil {
+			return "", nil, err
+		} else if condition == "" {
+			condition = "1 = 1"
+		}
+
+		return "NOT (" + condition + ")", values, nil
+	default:
+		return "", nil, compare.FilterValuesError
+	}
+}
+
+func (b *Backend) comparatorClause(fieldName string, comparator any) (string, []any, error) {
+	expression, ok := comparator.(compare.Expression)
+	if !ok {
+		return "", nil, compare.ComparisonTypeError
+	}
+
+	operands := expression.Operands()
+	numOperands := 1
+	if expression.Operator() == compare.BTW {
+		numOperands = 2
+	}
+
+	if len(operands) != numOperands {
+		return "", nil, compare.ComparatorValuesError
+	}
+
+	for i := range operands {
+		var err error
+		operands[i], err = toColumnValue(operands[i])
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	column := b.quote(fieldName)
+	switch expression.Operator() {
+	case compare.EQ:
+		return column + " = ?", operands, nil
+	case compare.LT:
+		return column + " < ?", operands, nil
+	case compare.LTE:
+		return column + " <= ?", operands, nil
+	case compare.GT:
+		return column + " > ?", operands, nil
+	case compare.GTE:
+		return column + " >= ?", operands, nil
+	case compare.BTW:
+		return column + " BETWEEN ? AND ?", operands, nil
+	default:
+		return "", nil, fmt.Errorf("unknown comparator operator %d: %w", expression.Operator(), compare.ComparatorValuesError)
+	}
+}
+
+func (b *Backend) assignments(fieldNames []string) string {
+	assignments := make([]string, len(fieldNames))
+	for i, fieldName := range fieldNames {
+		assignments[i] = b.quote(fieldName) + " = ?"
+	}
+
+	return strings.Join(assignments, ", ")
+}
+
+// updateAssignments sets the data fields and increments the version field,
+// returning the fields to give values for
+func (b *Backend) updateAssignments() (string, []string) {
+	versionFieldName := fields.VersionFieldName(b.settings.DataSettings)
+
+	fieldNames := []string{}
+	for _, fieldName := range dataFieldNames(b.settings) {
+		if fieldName != versionFieldName {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+
+	assignments := b.assignments(fieldNames)
+	if versionFieldName != "" {
+		version := b.quote(versionFieldName)
+		increment := fmt.Sprintf("%s = %s + 1", version, version)
+		if assignments == "" {
+			assignments = increment
+		} else {
+			assignments += ", " + increment
+		}
+	}
+
+	return assignments, fieldNames
+}
+
+// versionClause adds a condition on the version in entry to where, if the
+// table has a version field
+func (b *Backend) versionClause(where string, whereValues []any, entry mutator.MappedFieldValues) (string, []any, error) {
+	versionFieldName := fields.VersionFieldName(b.settings.DataSettings)
+	if versionFieldName == "" {
+		return where, whereValues, nil
+	}
+
+	version, err := toColumnValue(entry[versionFieldName])
+	if err != nil {
+		return "", nil, err
+	}
+
+	return where + " AND " + b.quote(versionFieldName) + " = ?", append(whereValues, version), nil
+}
+
+// KeyedBackend holds the queries shared by sql table kinds with a key
+type KeyedBackend struct {
+	Backend
+}
+
+func (b *KeyedBackend) Get(ctx context.Context, keys []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	keyFieldNames := keyFieldNames(b.settings)
+	fieldNames := allFieldNames(b.settings)
+
+	// each key is queried separately so entries are returned in the same
+	// order as the keys they were requested with
+	data := make([]mutator.MappedFieldValues, 0, len(keys))
+	for _, key := range keys {
+		where, values, err := b.equalsClause(keyFieldNames, key)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := b.querier(ctx).QueryxContext(ctx, b.selectQuery(fieldNames, where, nil), values...)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := scanRows(rows, b.settings, fieldNames)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, found...)
+	}
+
+	return data, nil
+}
+
+func (b *KeyedBackend) Update(ctx context.Context, entries []mutator.MappedFieldValues) error {
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// with nothing to set, the keys only need to exist
+	if len(dataFieldNames(b.settings)) == 0 {
+		for _, entry := range entries {
+			if found, err := b.Get(ctx, []mutator.MappedFieldValues{entry}); err != nil {
+				return err
+			} else if len(found) == 0 {
+				return KeyDoesNotExistError
+			}
+		}
+
+		return tx.Commit()
+	}
+
+	for _, entry := range entries {
+		if updated, err := b.update(ctx, entry); err != nil {
+			return err
+		} else if !updated {
+			return b.updateError(ctx, entry)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// update sets the data fields of the row with the key and version of entry,
+// and returns false if there is no such row
+func (b *KeyedBackend) update(ctx context.Context, entry mutator.MappedFieldValues) (bool, error) {
+	assignments, assignFieldNames := b.updateAssignments()
+
+	where, whereValues, err := b.equalsClause(keyFieldNames(b.settings), entry)
+	if err != nil {
+		return false, err
+	}
+
+	where, whereValues, err = b.versionClause(where, whereValues, entry)
+	if err != nil {
+		return false, err
+	}
+
+	values, err := toColumnValues(assignFieldNames, entry)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s",
+		b.tableName(),
+		assignments,
+		where,
+	), append(values, whereValues...)...)
+	if err != nil && b.dialect.IsDuplicateKeyError(err) {
+		return false, KeyExistsError
+	} else if err != nil {
+		return false, err
+	}
+
+	numRows, err := result.RowsAffected()
+	return numRows > 0, err
+}
+
+// addIfAbsent inserts entry unless a row with its primary key exists, and
+// returns the added entry or nil if it was not added
+func (b *KeyedBackend) addIfAbsent(ctx context.Context, entry mutator.MappedFieldValues) (mutator.MappedFieldValues, error) {
+	insertFieldNames, autoGenerateFieldNames := b.insertFieldNames()
+
+	values, err := toColumnValues(insertFieldNames, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	query := b.dialect.InsertIfAbsentQuery(b.settings.Name, insertFieldNames, b.primaryKeyNames)
+	result, err := b.querier(ctx).ExecContext(ctx, query, values...)
+	if err != nil && b.dialect.IsDuplicateKeyError(err) {
+		return nil, KeyExistsError
+	} else if err != nil {
+		return nil, err
+	}
+
+	if numRows, err := result.RowsAffected(); err != nil || numRows == 0 {
+		return nil, err
+	}
+
+	return b.withGeneratedValues(entry, autoGenerateFieldNames, result)
+}
+
+func (b *KeyedBackend) UpdateIf(ctx context.Context, entries []mutator.MappedFieldValues, predicate func(existing mutator.MappedFieldValues) (bool, error)) error {
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// every entry is checked before any are updated
+	for _, entry := range entries {
+		found, err := b.Get(ctx, []mutator.MappedFieldValues{entry})
+		if err != nil {
+			return err
+		} else if len(found) == 0 {
+			return KeyDoesNotExistError
+		}
+
+		if matches, err := predicate(found[0]); err != nil {
+			return err
+		} else if !matches {
+			return ConditionFailedError
+		}
+	}
+
+	if err := b.Update(ctx, entries); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (b *KeyedBackend) getByIndex(ctx context.Context, indexName string, values mutator.MappedFieldValues, orderBy []string) ([]mutator.MappedFieldValues, error) {
+	index := b.settings.Index(indexName)
+	if index == nil {
+		return nil, IndexDoesNotExistError
+	}
+
+	where, whereValues, err := b.equalsClause(index.FieldNames, values)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldNames := allFieldNames(b.settings)
+	rows, err := b.querier(ctx).QueryxContext(ctx, b.selectQuery(fieldNames, where, orderBy), whereValues...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRows(rows, b.settings, fieldNames)
+}
+
+func (b *KeyedBackend) Upsert(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	versionFieldName := fields.VersionFieldName(b.settings.DataSettings)
+	hasDataFields := len(dataFieldNames(b.settings)) > 0
+
+	upserted := make([]mutator.MappedFieldValues, len(entries))
+	for i, entry := range entries {
+		updated := false
+		if hasDataFields {
+			if updated, err = b.update(ctx, entry); err != nil {
+				return nil, err
+			}
+		}
+
+		if !updated {
+			added, err := b.addIfAbsent(ctx, entry)
+			if err != nil {
+				return nil, err
+			} else if added != nil {
+				upserted[i] = added
+				continue
+			}
+		}
+
+		// the row exists but was not updated, either because it was added
+		// since the update or because its version is different
+		if !updated && hasDataFields {
+			if updated, err = b.update(ctx, entry); err != nil {
+				return nil, err
+			} else if !updated {
+				return nil, b.updateError(ctx, entry)
+			}
+		}
+
+		upserted[i] = entry
+		if versionFieldName != "" {
+			upserted[i] = utils.MergeMaps(entry)
+			upserted[i][versionFieldName], err = fields.NextVersion(entry[versionFieldName])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return upserted, tx.Commit()
+}
+
+func (b *KeyedBackend) AddIfAbsent(ctx context.Context, entries []mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	added := []mutator.MappedFieldValues{}
+	for _, entry := range entries {
+		addedEntry, err := b.addIfAbsent(ctx, entry)
+		if err != nil {
+			return nil, err
+		} else if addedEntry != nil {
+			added = append(added, addedEntry)
+		}
+	}
+
+	return added, tx.Commit()
+}
+
+// updateError finds why no rows matched an update of entry, the row with
+// its key either does not exist or has a different version
+func (b *KeyedBackend) updateError(ctx context.Context, entry mutator.MappedFieldValues) error {
+	versionFieldName := fields.VersionFieldName(b.settings.DataSettings)
+	if versionFieldName == "" {
+		return KeyDoesNotExistError
+	}
+
+	where, keyValues, err := b.equalsClause(keyFieldNames(b.settings), entry)
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.checkVersions(ctx, where, keyValues, entry); err != nil {
+		return err
+	}
+
+	return KeyDoesNotExistError
+}
+
+// checkVersions returns a ConflictError if any row matching where has a
+// different version to entry, otherwise the number of rows matched
+func (b *Backend) checkVersions(ctx context.Context, where string, whereValues []any, entry mutator.MappedFieldValues) (int64, error) {
+	versionFieldName := fields.VersionFieldName(b.settings.DataSettings)
+	fieldNames := append(append([]string{}, keyFieldNames(b.settings)...), versionFieldName)
+
+	rows, err := b.querier(ctx).QueryxContext(ctx, b.selectQuery(fieldNames, where, nil), whereValues...)
+	if err != nil {
+		return 0, err
+	}
+
+	found, err := scanRows(rows, b.settings, fieldNames)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, existing := range found {
+		if existing[versionFieldName] != entry[versionFieldName] {
+			key := mutator.MappedFieldValues{}
+			for _, fieldName := range keyFieldNames(b.settings) {
+				key[fieldName] = existing[fieldName]
+			}
+
+			return 0, &ConflictError{
+				Key:      key,
+				Expected: entry[versionFieldName],
+				Actual:   existing[versionFieldName],
+			}
+		}
+	}
+
+	return int64(len(found)), nil
+}
+
+func (b *KeyedBackend) Delete(ctx context.Context, keys []mutator.MappedFieldValues) error {
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, key := range keys {
+		where, values, err := b.equalsClause(keyFieldNames(b.settings), key)
+		if err != nil {
+			return err
+		}
+
+		result, err := b.querier(ctx).ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", b.tableName(), where), values...)
+		if err != nil {
+			return err
+		}
+
+		if numRows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if numRows == 0 {
+			return KeyDoesNotExistError
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,76 @@
+package datastoresql
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+)
+
+// Column describes a field of an entry as a column in a table
+type Column struct {
+	Name       string
+	EmptyValue any
+	Setting    *fields.FieldSetting
+	IsKey      bool
+	IsIndexed  bool
+}
+
+// IsAutoIncrement reports whether the database generates the column's values
+func (c Column) IsAutoIncrement() bool {
+	return c.Setting.IsAutoIncrement(c.EmptyValue)
+}
+
+// StoredColumn is a column as it is defined in the database
+type StoredColumn struct {
+	Name          string
+	Type          string
+	Nullable      bool
+	AutoIncrement bool
+}
+
+// StoredTable is the schema of a table as it is defined in the database, with
+// its columns in order
+type StoredTable struct {
+	Columns    []*StoredColumn
+	PrimaryKey []string
+}
+
+// Dialect holds everything which differs between sql databases, so the
+// queries for every table kind can be shared between backends
+type Dialect interface {
+	QuoteIdentifier(name string) string
+	// CreateTableQueries returns the statements which create a table and its
+	// indexes, each of which must do nothing if it already exists
+	CreateTableQueries(tableName string, columns []Column, primaryKey []string, indexes []*datastore.Index) ([]string, error)
+	IsDuplicateKeyError(err error) bool
+	// InsertIfAbsentQuery inserts one row with values for columnNames, or
+	// does nothing if a row with the same primary key exists
+	InsertIfAbsentQuery(tableName string, columnNames []string, primaryKey []string) string
+	// MaxPlaceholders returns the most values which can be bound to one
+	// statement
+	MaxPlaceholders() int
+
+	// StoredTable reads the schema of a table, or returns nil if it does not
+	// exist
+	StoredTable(ctx context.Context, db sqlx.QueryerContext, tableName string) (*StoredTable, error)
+	// ColumnMatches reports whether a stored column has the definition the
+	// dialect creates for column
+	ColumnMatches(stored *StoredColumn, column Column) (bool, error)
+	// AddColumnQuery adds column after the column named after, or first if
+	// it is empty, in databases which let columns be positioned
+	AddColumnQuery(tableName string, column Column, after string) (string, error)
+	AlterColumnQuery(tableName string, column Column) (string, error)
+	// MoveColumnQuery moves column after the column named after, or first if
+	// it is empty. It returns an empty statement in databases which cannot
+	// position columns, where the order is left as it is.
+	MoveColumnQuery(tableName string, column Column, after string) (string, error)
+	DropColumnQuery(tableName string, columnName string) string
+	// AlterPrimaryKeyQuery replaces the storedKey of a table with primaryKey,
+	// either of which may be empty
+	AlterPrimaryKeyQuery(tableName string, storedKey []string, primaryKey []string) (string, error)
+	// TransactionalDDL reports whether changes to the schema are rolled back
+	// with the transaction they are made in
+	TransactionalDDL() bool
+}
@@ -0,0 +1,39 @@
+package datastoresql
+
+import (
+	"errors"
+
+	"github.com/sophielizg/go-libs/datastore"
+)
+
+var AutoGenerateNotSupportedError = errors.New("auto generate is not supported for this field in sql backends")
+
+var UnsupportedFieldTypeError = errors.New("field type cannot be stored in a sql column")
+
+var KeyExistsError = datastore.KeyExistsError
+
+var KeyDoesNotExistError = datastore.KeyDoesNotExistError
+
+var IndexDoesNotExistError = datastore.IndexDoesNotExistError
+
+var InvalidIndexError = datastore.InvalidIndexError
+
+var FieldDoesNotExistError = datastore.FieldDoesNotExistError
+
+var InvalidCursorError = datastore.InvalidCursorError
+
+var PageNotSupportedError = datastore.PageNotSupportedError
+
+var AggregateTypeError = datastore.AggregateTypeError
+
+var ConditionFailedError = datastore.ConditionFailedError
+
+var QueueEmptyError = datastore.QueueEmptyError
+
+var DeadLetterQueueDoesNotExistError = datastore.DeadLetterQueueDoesNotExistError
+
+var InvalidDeadLetterQueueError = datastore.InvalidDeadLetterQueueError
+
+var MigrationNotSupportedError = datastore.MigrationNotSupportedError
+
+type ConflictError = datastore.ConflictError
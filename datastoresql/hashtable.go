@@ -0,0 +1,28 @@
+package datastoresql
+
+import (
+	"context"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+)
+
+type HashTableBackend struct {
+	KeyedBackend
+}
+
+func (b *HashTableBackend) Register() error {
+	return b.createTable(keyFieldNames(b.settings))
+}
+
+func (b *HashTableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	return b.scan(ctx, batchSize, nil, filter, fieldNames)
+}
+
+func (b *HashTableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	return b.page(ctx, "", nil, keyFieldNames(b.settings), cursor, limit)
+}
+
+func (b *HashTableBackend) GetByIndex(ctx context.Context, indexName string, values mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	return b.getByIndex(ctx, indexName, values, keyFieldNames(b.settings))
+}
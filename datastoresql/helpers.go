@@ -0,0 +1,221 @@
+package datastoresql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/utils"
+)
+
+func keyFieldNames(settings *datastore.TableSettings) []string {
+	if settings.KeySettings == nil {
+		return nil
+	}
+
+	return settings.KeySettings.FieldOrder
+}
+
+func dataFieldNames(settings *datastore.TableSettings) []string {
+	if settings.DataSettings == nil {
+		return nil
+	}
+
+	return settings.DataSettings.FieldOrder
+}
+
+func allFieldNames(settings *datastore.TableSettings) []string {
+	keyFieldNames := keyFieldNames(settings)
+	dataFieldNames := dataFieldNames(settings)
+
+	fieldNames := make([]string, 0, len(keyFieldNames)+len(dataFieldNames))
+	fieldNames = append(fieldNames, keyFieldNames...)
+	return append(fieldNames, dataFieldNames...)
+}
+
+// hashFieldNames are the key fields which are not sort fields
+func hashFieldNames(settings *datastore.TableSettings) []string {
+	hashFieldNames := []string{}
+
+	for _, fieldName := range keyFieldNames(settings) {
+		if !utils.SliceContains(settings.SortFieldNames, fieldName) {
+			hashFieldNames = append(hashFieldNames, fieldName)
+		}
+	}
+
+	return hashFieldNames
+}
+
+// autoGenerateFieldNames are the fields generated by the database, other
+// generators are applied before entries reach the backend
+func autoGenerateFieldNames(settings *datastore.TableSettings, rowSettings *fields.RowSettings) []string {
+	autoGenerateFieldNames := []string{}
+	if rowSettings == nil {
+		return autoGenerateFieldNames
+	}
+
+	for _, fieldName := range rowSettings.FieldOrder {
+		setting := rowSettings.FieldSettings[fieldName]
+		if setting.IsAutoIncrement(settings.EmptyValues[fieldName]) {
+			autoGenerateFieldNames = append(autoGenerateFieldNames, fieldName)
+		}
+	}
+
+	return autoGenerateFieldNames
+}
+
+// validateAutoGenerateSettings checks every auto generated field has a
+// generator, and that AutoIncrement fields are ints
+func validateAutoGenerateSettings(settings *datastore.TableSettings) error {
+	for _, rowSettings := range []*fields.RowSettings{settings.KeySettings, settings.DataSettings} {
+		if rowSettings == nil {
+			continue
+		}
+
+		for fieldName, setting := range rowSettings.FieldSettings {
+			emptyValue := settings.EmptyValues[fieldName]
+			if setting == nil || !setting.AutoGenerate {
+				continue
+			} else if setting.GeneratorFor(emptyValue) == nil {
+				return fmt.Errorf("%s: %w", fieldName, AutoGenerateNotSupportedError)
+			} else if setting.IsAutoIncrement(emptyValue) && !fields.IsIntField(emptyValue) {
+				return fmt.Errorf("%s: %w", fieldName, AutoGenerateNotSupportedError)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateVersionSettings checks the version field, if there is one, can be
+// incremented in sql
+func validateVersionSettings(settings *datastore.TableSettings) error {
+	versionFieldName := fields.VersionFieldName(settings.DataSettings)
+	if versionFieldName == "" {
+		return nil
+	}
+
+	_, err := fields.NextVersion(settings.EmptyValues[versionFieldName])
+	return err
+}
+
+func placeholders(num int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", num), ", ")
+}
+
+// toColumnValue converts a field value to a value the sql driver accepts
+func toColumnValue(value any) (any, error) {
+	switch val := value.(type) {
+	case fields.JsonMap, fields.JsonList:
+		bytes, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(bytes), nil
+	case fields.Time:
+		// times are stored in utc
+		return val.UTC(), nil
+	case fields.NullTime:
+		if val == nil {
+			return nil, nil
+		}
+
+		return val.UTC(), nil
+	default:
+		return value, nil
+	}
+}
+
+func toColumnValues(fieldNames []string, entry mutator.MappedFieldValues) ([]any, error) {
+	values := make([]any, len(fieldNames))
+
+	for i, fieldName := range fieldNames {
+		var err error
+		values[i], err = toColumnValue(entry[fieldName])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// newScanDest creates a pointer to scan a column into, based on the type of
+// the empty value for the field
+func newScanDest(emptyValue any) any {
+	switch emptyValue.(type) {
+	case fields.JsonMap, fields.JsonList:
+		return &[]byte{}
+	default:
+		return reflect.New(reflect.TypeOf(emptyValue)).Interface()
+	}
+}
+
+func fromScanDest(emptyValue any, dest any) (any, error) {
+	switch emptyValue.(type) {
+	case fields.JsonMap:
+		bytes := *dest.(*[]byte)
+		if bytes == nil {
+			return fields.JsonMap(nil), nil
+		}
+
+		value := fields.JsonMap{}
+		err := json.Unmarshal(bytes, &value)
+		return value, err
+	case fields.JsonList:
+		bytes := *dest.(*[]byte)
+		if bytes == nil {
+			return fields.JsonList(nil), nil
+		}
+
+		value := fields.JsonList{}
+		err := json.Unmarshal(bytes, &value)
+		return value, err
+	default:
+		return reflect.ValueOf(dest).Elem().Interface(), nil
+	}
+}
+
+func scanRow(rows *sqlx.Rows, settings *datastore.TableSettings, fieldNames []string, extraDests ...any) (mutator.MappedFieldValues, error) {
+	dests := make([]any, len(fieldNames), len(fieldNames)+len(extraDests))
+	for i, fieldName := range fieldNames {
+		dests[i] = newScanDest(settings.EmptyValues[fieldName])
+	}
+
+	if err := rows.Scan(append(dests, extraDests...)...); err != nil {
+		return nil, err
+	}
+
+	entry := mutator.MappedFieldValues{}
+	for i, fieldName := range fieldNames {
+		var err error
+		entry[fieldName], err = fromScanDest(settings.EmptyValues[fieldName], dests[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+func scanRows(rows *sqlx.Rows, settings *datastore.TableSettings, fieldNames []string) ([]mutator.MappedFieldValues, error) {
+	defer rows.Close()
+
+	entries := []mutator.MappedFieldValues{}
+	for rows.Next() {
+		entry, err := scanRow(rows, settings, fieldNames)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,269 @@
+package datastoresql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/utils"
+)
+
+const migrationHistoryBatchSize = 100
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// positionName moves name to i in names, inserting it if it is not there
+func positionName(names []string, name string, i int) []string {
+	positioned := make([]string, 0, len(names)+1)
+	for _, existing := range names {
+		if existing != name {
+			positioned = append(positioned, existing)
+		}
+	}
+
+	positioned = append(positioned, "")
+	copy(positioned[i+1:], positioned[i:])
+	positioned[i] = name
+	return positioned
+}
+
+// PlanMigration adds, alters, moves and then drops columns, and replaces the
+// primary key if it has changed
+func (b *Backend) PlanMigration(ctx context.Context) (*datastore.MigrationPlan, error) {
+	stored, err := b.dialect.StoredTable(ctx, b.db, b.settings.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &datastore.MigrationPlan{TableName: b.settings.Name}
+	if stored == nil {
+		return plan, nil
+	}
+
+	storedColumns := map[string]*StoredColumn{}
+	for _, storedColumn := range stored.Columns {
+		storedColumns[storedColumn.Name] = storedColumn
+	}
+
+	columns := b.tableColumns()
+	alters := []*datastore.MigrationOperation{}
+	columnNames := make([]string, len(columns))
+	for i, column := range columns {
+		columnNames[i] = column.Name
+		after := ""
+		if i > 0 {
+			after = columns[i-1].Name
+		}
+
+		storedColumn := storedColumns[column.Name]
+		if storedColumn == nil {
+			statement, err := b.dialect.AddColumnQuery(b.settings.Name, column, after)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.settings.Name, err)
+			}
+
+			plan.Operations = append(plan.Operations, &datastore.MigrationOperation{
+				Type:      datastore.ADD_FIELD,
+				FieldName: column.Name,
+				Statement: statement,
+			})
+			continue
+		}
+
+		matches, err := b.dialect.ColumnMatches(storedColumn, column)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.settings.Name, err)
+		} else if matches {
+			continue
+		}
+
+		statement, err := b.dialect.AlterColumnQuery(b.settings.Name, column)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.settings.Name, err)
+		}
+
+		alters = append(alters, &datastore.MigrationOperation{
+			Type:      datastore.ALTER_FIELD,
+			FieldName: column.Name,
+			Statement: statement,
+		})
+	}
+	plan.Operations = append(plan.Operations, alters...)
+
+	// the order the kept columns will be in, once added columns have been
+	// placed after the column before them
+	order := []string{}
+	for _, storedColumn := range stored.Columns {
+		if utils.SliceContains(columnNames, storedColumn.Name) {
+			order = append(order, storedColumn.Name)
+		}
+	}
+	for i, column := range columns {
+		if storedColumns[column.Name] == nil {
+			order = positionName(order, column.Name, i)
+		}
+	}
+
+	for i, column := range columns {
+		if order[i] == column.Name {
+			continue
+		}
+
+		after := ""
+		if i > 0 {
+			after = columns[i-1].Name
+		}
+
+		statement, err := b.dialect.MoveColumnQuery(b.settings.Name, column, after)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.settings.Name, err)
+		} else if statement == "" {
+			break
+		}
+
+		plan.Operations = append(plan.Operations, &datastore.MigrationOperation{
+			Type:      datastore.MOVE_FIELD,
+			FieldName: column.Name,
+			Statement: statement,
+		})
+		order = positionName(order, column.Name, i)
+	}
+
+	if !equalNames(stored.PrimaryKey, b.primaryKeyNames) {
+		statement, err := b.dialect.AlterPrimaryKeyQuery(b.settings.Name, stored.PrimaryKey, b.primaryKeyNames)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.settings.Name, err)
+		}
+
+		plan.Operations = append(plan.Operations, &datastore.MigrationOperation{
+			Type:      datastore.ALTER_KEY,
+			Statement: statement,
+		})
+	}
+
+	for _, storedColumn := range stored.Columns {
+		if !utils.SliceContains(columnNames, storedColumn.Name) {
+			plan.Operations = append(plan.Operations, &datastore.MigrationOperation{
+				Type:      datastore.DROP_FIELD,
+				FieldName: storedColumn.Name,
+				Statement: b.dialect.DropColumnQuery(b.settings.Name, storedColumn.Name),
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// migrationHistory registers the table migrations are recorded in, which is
+// shared by every table on the db
+func (b *Backend) migrationHistory() (*datastore.AppendTable[datastore.MigrationRecord, *datastore.MigrationRecord], error) {
+	table := datastore.NewMigrationHistoryTable()
+	table.Init()
+
+	backend := &AppendTableBackend{}
+	backend.SetSettings(table.Settings)
+	backend.SetDB(b.db, b.dialect)
+	table.SetBackend(backend)
+
+	return table, backend.Register()
+}
+
+// ApplyMigration runs the statements of plan and records them in the
+// migration history, in one transaction where the database can roll back
+// changes to the schema
+func (b *Backend) ApplyMigration(ctx context.Context, plan *datastore.MigrationPlan) error {
+	if plan == nil || len(plan.Operations) == 0 {
+		return nil
+	}
+
+	history, err := b.migrationHistory()
+	if err != nil {
+		return err
+	}
+
+	var tx datastore.Tx = joinedTx{}
+	if b.dialect.TransactionalDDL() {
+		if ctx, tx, err = BeginTx(ctx, b.db); err != nil {
+			return err
+		}
+	}
+	defer tx.Rollback()
+
+	statements := make(fields.JsonList, len(plan.Operations))
+	for i, operation := range plan.Operations {
+		if _, err := b.querier(ctx).ExecContext(ctx, operation.Statement); err != nil {
+			return fmt.Errorf("%s: %w", plan.TableName, err)
+		}
+
+		statements[i] = operation.Statement
+	}
+
+	_, err = history.AddContext(ctx, &datastore.MigrationRecord{
+		TableName:  plan.TableName,
+		Statements: statements,
+		AppliedAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (b *Backend) MigrationHistory(ctx context.Context) ([]*datastore.MigrationRecord, error) {
+	history, err := b.migrationHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	dataChan, errorChan := history.ScanWithFilterContext(
+		ctx,
+		migrationHistoryBatchSize,
+		compare.Field(datastore.MigrationTableNameKey, compare.Eq(b.settings.Name)),
+	)
+
+	records := []*datastore.MigrationRecord{}
+	var scanErr error
+	for dataChan != nil || errorChan != nil {
+		select {
+		case record, more := <-dataChan:
+			if !more {
+				dataChan = nil
+			} else {
+				records = append(records, record)
+			}
+		case err, more := <-errorChan:
+			if !more {
+				errorChan = nil
+			} else if scanErr == nil {
+				scanErr = err
+			}
+		}
+	}
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Id < records[j].Id
+	})
+
+	return records, nil
+}
@@ -0,0 +1,441 @@
+package datastoresql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/utils"
+)
+
+const (
+	messageIdColumn     = "_MessageId"
+	inFlightColumn      = "_InFlight"
+	visibleAtColumn     = "_VisibleAt"
+	deliveryCountColumn = "_DeliveryCount"
+
+	// recievePollInterval is how often RecieveMessageWait checks for new
+	// messages, since sql databases have no way to notify waiting consumers
+	recievePollInterval = 50 * time.Millisecond
+)
+
+// QueueBackend stores messages in a table ordered by a generated id, with a
+// flag marking messages which are in flight
+type QueueBackend struct {
+	Backend
+}
+
+func (b *QueueBackend) Register() error {
+	if b.settings.MaxDeliveryAttempts > 0 && b.settings.DeadLetterQueueName == b.settings.Name {
+		return InvalidDeadLetterQueueError
+	}
+
+	if b.settings.MaxDeliveryAttempts > 0 {
+		stored, err := b.dialect.StoredTable(context.Background(), b.db, b.settings.DeadLetterQueueName)
+		if err != nil {
+			return err
+		} else if stored == nil {
+			return DeadLetterQueueDoesNotExistError
+		}
+	}
+
+	return b.createTable(
+		[]string{messageIdColumn},
+		Column{
+			Name:       messageIdColumn,
+			EmptyValue: fields.BigInt(0),
+			Setting:    &fields.FieldSetting{AutoGenerate: true},
+			IsKey:      true,
+		},
+		Column{
+			Name:       inFlightColumn,
+			EmptyValue: fields.Bool(false),
+			Setting:    &fields.FieldSetting{},
+		},
+		Column{
+			Name:       visibleAtColumn,
+			EmptyValue: fields.NullTime(nil),
+			Setting:    &fields.FieldSetting{},
+		},
+		Column{
+			Name:       deliveryCountColumn,
+			EmptyValue: fields.Int(0),
+			Setting:    &fields.FieldSetting{},
+		},
+	)
+}
+
+func (b *QueueBackend) isDeadLetter(deliveryCount int) bool {
+	return b.settings.MaxDeliveryAttempts > 0 && deliveryCount >= b.settings.MaxDeliveryAttempts
+}
+
+// availableClause matches messages which are not in flight, or whose
+// visibility timeout has passed
+func (b *QueueBackend) availableClause(now time.Time) (string, []any) {
+	return fmt.Sprintf(
+		"(%s = ? OR %s <= ?)",
+		b.quote(inFlightColumn),
+		b.quote(visibleAtColumn),
+	), []any{false, now.UTC()}
+}
+
+// visibleAt is when a message recieved at now should be redelivered, or nil
+// if it should stay in flight until it is acked
+func (b *QueueBackend) visibleAt(now time.Time) any {
+	if b.settings.VisibilityTimeout <= 0 {
+		return nil
+	}
+
+	return now.Add(b.settings.VisibilityTimeout).UTC()
+}
+
+func (b *QueueBackend) Count(ctx context.Context) (int, error) {
+	where, values := b.availableClause(time.Now())
+	if b.settings.MaxDeliveryAttempts > 0 {
+		// expired messages which are out of attempts will be moved to the
+		// dead letter queue rather than recieved
+		where += fmt.Sprintf(" AND (%s = ? OR %s < ?)", b.quote(inFlightColumn), b.quote(deliveryCountColumn))
+		values = append(values, false, b.settings.MaxDeliveryAttempts)
+	}
+
+	var count int
+	err := b.querier(ctx).GetContext(ctx, &count, fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE %s",
+		b.tableName(),
+		where,
+	), values...)
+	return count, err
+}
+
+func (b *QueueBackend) HasMessage(ctx context.Context) (bool, error) {
+	count, err := b.Count(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (b *QueueBackend) SendMessage(ctx context.Context, messages []mutator.MappedFieldValues) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	fieldNames := dataFieldNames(b.settings)
+	values := []any{}
+	for _, message := range messages {
+		messageValues, err := toColumnValues(fieldNames, message)
+		if err != nil {
+			return err
+		}
+
+		values = append(values, messageValues...)
+		values = append(values, false, 0)
+	}
+
+	insertFieldNames := append(append([]string{}, fieldNames...), inFlightColumn, deliveryCountColumn)
+	_, err := b.querier(ctx).ExecContext(ctx, b.insertQuery(insertFieldNames, len(messages)), values...)
+	return err
+}
+
+func (b *QueueBackend) RecieveMessage(ctx context.Context) (string, mutator.MappedFieldValues, error) {
+	messageId, message, _, err := b.RecieveMessageWithDeliveryCount(ctx)
+	return messageId, message, err
+}
+
+func (b *QueueBackend) RecieveMessageWithDeliveryCount(ctx context.Context) (string, mutator.MappedFieldValues, int, error) {
+	fieldNames := dataFieldNames(b.settings)
+	selectFieldNames := append(append([]string{}, fieldNames...), messageIdColumn, deliveryCountColumn)
+
+	// another consumer may recieve the same message between the select and
+	// the update, so keep trying until this consumer is the one to mark it
+	for {
+		now := time.Now()
+		where, values := b.availableClause(now)
+		rows, err := b.querier(ctx).QueryxContext(
+			ctx,
+			b.selectQuery(selectFieldNames, where, []string{messageIdColumn})+" LIMIT 1",
+			values...,
+		)
+		if err != nil {
+			return "", nil, 0, err
+		}
+
+		if !rows.Next() {
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return "", nil, 0, err
+			}
+
+			return "", nil, 0, QueueEmptyError
+		}
+
+		var messageId int64
+		var deliveryCount int
+		message, err := scanRow(rows, b.settings, fieldNames, &messageId, &deliveryCount)
+		rows.Close()
+		if err != nil {
+			return "", nil, 0, err
+		}
+
+		result, err := b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET %s = ?, %s = ?, %s = %s + 1 WHERE %s = ? AND %s",
+			b.tableName(),
+			b.quote(inFlightColumn),
+			b.quote(visibleAtColumn),
+			b.quote(deliveryCountColumn),
+			b.quote(deliveryCountColumn),
+			b.quote(messageIdColumn),
+			where,
+		), append([]any{true, b.visibleAt(now), messageId}, values...)...)
+		if err != nil {
+			return "", nil, 0, err
+		}
+
+		numRows, err := result.RowsAffected()
+		if err != nil {
+			return "", nil, 0, err
+		} else if numRows == 0 {
+			continue
+		}
+
+		// a message which ran out of attempts but was never acked is only
+		// moved once its visibility timeout passes
+		if b.isDeadLetter(deliveryCount) {
+			if err := b.moveToDeadLetterQueue(ctx, messageId); err != nil {
+				return "", nil, 0, err
+			}
+
+			continue
+		}
+
+		return strconv.FormatInt(messageId, 10), message, deliveryCount + 1, nil
+	}
+}
+
+func (b *QueueBackend) RecieveMessageWait(ctx context.Context, maxWait time.Duration) (string, mutator.MappedFieldValues, error) {
+	deadline := time.Now().Add(maxWait)
+
+	for {
+		messageId, message, err := b.RecieveMessage(ctx)
+		if err != QueueEmptyError {
+			return messageId, message, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return "", nil, QueueEmptyError
+		} else if remaining > recievePollInterval {
+			remaining = recievePollInterval
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", nil, ctx.Err()
+		}
+	}
+}
+
+func (b *QueueBackend) RecieveMessages(ctx context.Context, n int) ([]string, []mutator.MappedFieldValues, error) {
+	messageIds := make([]string, 0, n)
+	messages := make([]mutator.MappedFieldValues, 0, n)
+
+	for len(messageIds) < n {
+		messageId, message, err := b.RecieveMessage(ctx)
+		if err == QueueEmptyError {
+			break
+		} else if err != nil {
+			// messages already marked in flight are made available again,
+			// even if ctx is done
+			b.AckFailure(detachedContext{ctx}, messageIds)
+			return nil, nil, err
+		}
+
+		messageIds = append(messageIds, messageId)
+		messages = append(messages, message)
+	}
+
+	return messageIds, messages, nil
+}
+
+// updateInFlight sets assignments on an in flight message which is not
+// visible by visibleAfter, if given, or returns KeyDoesNotExistError
+func (b *QueueBackend) updateInFlight(ctx context.Context, messageId string, visibleAfter *time.Time, assignments string, values ...any) error {
+	messageIdInt, err := strconv.ParseInt(messageId, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	where := fmt.Sprintf("%s = ? AND %s = ?", b.quote(messageIdColumn), b.quote(inFlightColumn))
+	values = append(values, messageIdInt, true)
+	if visibleAfter != nil {
+		where += fmt.Sprintf(" AND (%s IS NULL OR %s > ?)", b.quote(visibleAtColumn), b.quote(visibleAtColumn))
+		values = append(values, visibleAfter.UTC())
+	}
+
+	result, err := b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s",
+		b.tableName(),
+		assignments,
+		where,
+	), values...)
+	if err != nil {
+		return err
+	}
+
+	if numRows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if numRows == 0 {
+		return KeyDoesNotExistError
+	}
+
+	return nil
+}
+
+func (b *QueueBackend) AckSuccess(ctx context.Context, messageIds []string) error {
+	for _, messageId := range utils.Unique(messageIds) {
+		messageIdInt, err := strconv.ParseInt(messageId, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		result, err := b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+			"DELETE FROM %s WHERE %s = ? AND %s = ?",
+			b.tableName(),
+			b.quote(messageIdColumn),
+			b.quote(inFlightColumn),
+		), messageIdInt, true)
+		if err != nil {
+			return err
+		}
+
+		if numRows, err := result.RowsAffected(); err != nil {
+			return err
+		} else if numRows == 0 {
+			return KeyDoesNotExistError
+		}
+	}
+
+	return nil
+}
+
+func (b *QueueBackend) AckFailure(ctx context.Context, messageIds []string) error {
+	for _, messageId := range utils.Unique(messageIds) {
+		if b.settings.MaxDeliveryAttempts > 0 {
+			deliveryCount, err := b.DeliveryCount(ctx, messageId)
+			if err != nil {
+				return err
+			}
+
+			if b.isDeadLetter(deliveryCount) {
+				messageIdInt, err := strconv.ParseInt(messageId, 10, 64)
+				if err != nil {
+					return err
+				}
+
+				if err := b.moveToDeadLetterQueue(ctx, messageIdInt); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		err := b.updateInFlight(
+			ctx,
+			messageId,
+			nil,
+			b.assignments([]string{inFlightColumn, visibleAtColumn}),
+			false,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveToDeadLetterQueue copies an in flight message into the dead letter
+// queue's table and deletes it in one transaction
+func (b *QueueBackend) moveToDeadLetterQueue(ctx context.Context, messageId int64) error {
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fieldNames := dataFieldNames(b.settings)
+	insertFieldNames := append(append([]string{}, fieldNames...), inFlightColumn, deliveryCountColumn)
+	selectColumns := append(b.quoteAll(fieldNames), "?", "?")
+
+	_, err = b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT %s FROM %s WHERE %s = ? AND %s = ?",
+		b.quote(b.settings.DeadLetterQueueName),
+		strings.Join(b.quoteAll(insertFieldNames), ", "),
+		strings.Join(selectColumns, ", "),
+		b.tableName(),
+		b.quote(messageIdColumn),
+		b.quote(inFlightColumn),
+	), false, 0, messageId, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+		"DELETE FROM %s WHERE %s = ? AND %s = ?",
+		b.tableName(),
+		b.quote(messageIdColumn),
+		b.quote(inFlightColumn),
+	), messageId, true)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (b *QueueBackend) ExtendVisibility(ctx context.Context, messageId string, duration time.Duration) error {
+	now := time.Now()
+
+	// messages whose visibility timeout has already passed can no longer be
+	// extended, since another consumer may recieve them
+	return b.updateInFlight(
+		ctx,
+		messageId,
+		&now,
+		b.assignments([]string{visibleAtColumn}),
+		now.Add(duration).UTC(),
+	)
+}
+
+func (b *QueueBackend) DeliveryCount(ctx context.Context, messageId string) (int, error) {
+	messageIdInt, err := strconv.ParseInt(messageId, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	deliveryCounts := []int{}
+	err = b.querier(ctx).SelectContext(ctx, &deliveryCounts, fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s = ? AND %s = ?",
+		b.quote(deliveryCountColumn),
+		b.tableName(),
+		b.quote(messageIdColumn),
+		b.quote(inFlightColumn),
+	), messageIdInt, true)
+	if err != nil {
+		return 0, err
+	} else if len(deliveryCounts) == 0 {
+		return 0, KeyDoesNotExistError
+	}
+
+	return deliveryCounts[0], nil
+}
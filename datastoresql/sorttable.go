@@ -0,0 +1,182 @@
+package datastoresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sophielizg/go-libs/datastore/compare"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastore/mutator"
+	"github.com/sophielizg/go-libs/datastore/queries"
+)
+
+type SortTableBackend struct {
+	KeyedBackend
+}
+
+// primaryKey orders the hash fields before the sort fields, so comparator
+// queries can use the primary key index
+func (b *SortTableBackend) primaryKey() []string {
+	return append(hashFieldNames(b.settings), b.settings.SortFieldNames...)
+}
+
+// sortComparatorClause matches the hash fields of key exactly, and every sort
+// field with a non nil comparator
+func (b *SortTableBackend) sortComparatorClause(key mutator.MappedFieldValues, comparator mutator.MappedFieldValues) (string, []any, error) {
+	clause, values, err := b.equalsClause(hashFieldNames(b.settings), key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	conditions := []string{clause}
+	for _, fieldName := range b.settings.SortFieldNames {
+		if compare.IsNilComparator(comparator[fieldName]) {
+			continue
+		}
+
+		condition, operands, err := b.comparatorClause(fieldName, comparator[fieldName])
+		if err != nil {
+			return "", nil, err
+		}
+
+		conditions = append(conditions, condition)
+		values = append(values, operands...)
+	}
+
+	return strings.Join(conditions, " AND "), values, nil
+}
+
+func (b *SortTableBackend) Register() error {
+	return b.createTable(b.primaryKey())
+}
+
+func (b *SortTableBackend) Scan(ctx context.Context, batchSize int, filter *compare.Filter, fieldNames []string) (chan mutator.MappedFieldValues, chan error) {
+	return b.scan(ctx, batchSize, b.primaryKey(), filter, fieldNames)
+}
+
+func (b *SortTableBackend) ScanPage(ctx context.Context, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	return b.page(ctx, "", nil, b.primaryKey(), cursor, limit)
+}
+
+func (b *SortTableBackend) GetWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues, options *queries.SortOptions) ([]mutator.MappedFieldValues, error) {
+	where, values, err := b.sortComparatorClause(key, comparator)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldNames := allFieldNames(b.settings)
+	query := b.selectQuery(fieldNames, where, nil)
+
+	orderBy := b.quoteAll(b.primaryKey())
+	if options.Order == queries.DESCENDING {
+		for i := range orderBy {
+			orderBy[i] += " DESC"
+		}
+	}
+	query += " ORDER BY " + strings.Join(orderBy, ", ")
+
+	if options.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", options.Limit)
+	}
+
+	rows, err := b.querier(ctx).QueryxContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRows(rows, b.settings, fieldNames)
+}
+
+func (b *SortTableBackend) GetPageWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues, cursor string, limit int) ([]mutator.MappedFieldValues, string, error) {
+	where, values, err := b.sortComparatorClause(key, comparator)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return b.page(ctx, where, values, b.primaryKey(), cursor, limit)
+}
+
+func (b *SortTableBackend) UpdateWithSortComparator(ctx context.Context, entry mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error {
+	if len(dataFieldNames(b.settings)) == 0 {
+		return nil
+	}
+
+	assignments, assignFieldNames := b.updateAssignments()
+	values, err := toColumnValues(assignFieldNames, entry)
+	if err != nil {
+		return err
+	}
+
+	where, whereValues, err := b.sortComparatorClause(entry, comparator)
+	if err != nil {
+		return err
+	}
+
+	if fields.VersionFieldName(b.settings.DataSettings) == "" {
+		_, err = b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET %s WHERE %s",
+			b.tableName(),
+			assignments,
+			where,
+		), append(values, whereValues...)...)
+		if err != nil && b.dialect.IsDuplicateKeyError(err) {
+			return KeyExistsError
+		}
+
+		return err
+	}
+
+	// every matching row must have the version in entry, so they are checked
+	// before any are updated
+	ctx, tx, err := BeginTx(ctx, b.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	numMatched, err := b.checkVersions(ctx, where, whereValues, entry)
+	if err != nil {
+		return err
+	}
+
+	versionWhere, versionWhereValues, err := b.versionClause(where, whereValues, entry)
+	if err != nil {
+		return err
+	}
+
+	result, err := b.querier(ctx).ExecContext(ctx, fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s",
+		b.tableName(),
+		assignments,
+		versionWhere,
+	), append(values, versionWhereValues...)...)
+	if err != nil && b.dialect.IsDuplicateKeyError(err) {
+		return KeyExistsError
+	} else if err != nil {
+		return err
+	}
+
+	// a row written between the check and the update changes the count
+	if numRows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if numRows != numMatched {
+		return &ConflictError{Expected: entry[fields.VersionFieldName(b.settings.DataSettings)]}
+	}
+
+	return tx.Commit()
+}
+
+func (b *SortTableBackend) DeleteWithSortComparator(ctx context.Context, key mutator.MappedFieldValues, comparator mutator.MappedFieldValues) error {
+	where, values, err := b.sortComparatorClause(key, comparator)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.querier(ctx).ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", b.tableName(), where), values...)
+	return err
+}
+
+func (b *SortTableBackend) GetByIndex(ctx context.Context, indexName string, values mutator.MappedFieldValues) ([]mutator.MappedFieldValues, error) {
+	return b.getByIndex(ctx, indexName, values, b.primaryKey())
+}
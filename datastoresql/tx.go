@@ -0,0 +1,85 @@
+package datastoresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastore"
+)
+
+type txKey struct{}
+
+type contextTx struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+}
+
+// querier runs queries either directly on a db or in a transaction
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// joinedTx is returned when BeginTx joins an outer transaction, which is
+// left to commit or roll back the changes
+type joinedTx struct{}
+
+func (joinedTx) Commit() error {
+	return nil
+}
+
+func (joinedTx) Rollback() error {
+	return nil
+}
+
+// BeginTx starts a transaction on db carried by the returned context, or
+// joins the one ctx already carries
+func BeginTx(ctx context.Context, db *sqlx.DB) (context.Context, datastore.Tx, error) {
+	if txFromContext(ctx, db) != nil {
+		return ctx, joinedTx{}, nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return context.WithValue(ctx, txKey{}, &contextTx{db: db, tx: tx}), tx, nil
+}
+
+func txFromContext(ctx context.Context, db *sqlx.DB) *sqlx.Tx {
+	contextTx, _ := ctx.Value(txKey{}).(*contextTx)
+	if contextTx == nil || contextTx.db != db {
+		return nil
+	}
+
+	return contextTx.tx
+}
+
+// querier returns the transaction carried by ctx, or the db if there is none
+func (b *Backend) querier(ctx context.Context) querier {
+	if tx := txFromContext(ctx, b.db); tx != nil {
+		return tx
+	}
+
+	return b.db
+}
+
+// detachedContext keeps the values of a context without its cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package datastoresqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastore/examples/logtable"
+	"github.com/sophielizg/go-libs/datastoresqlite"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestAppendTableBackend(t *testing.T) {
+	datastoretest.RunAppendTableSuite(t, func(t *testing.T) (*datastoresqlite.Connection, *datastoresqlite.AppendTableBackend) {
+		conn := newTestConnection(t)
+		t.Cleanup(func() { conn.Close() })
+		return conn, &datastoresqlite.AppendTableBackend{}
+	})
+}
+
+func TestAppendTableLogEntry(t *testing.T) {
+	conn := newTestConnection(t)
+	defer conn.Close()
+
+	table := logtable.New()
+	tableBackend := &datastoresqlite.AppendTableBackend{}
+
+	group := datastore.NewConnectionGroup(
+		datastore.WithConnection(conn),
+	)
+	err := group.RegisterTables(
+		datastore.RegisterAppendTable[*datastoresqlite.Connection](table, tableBackend),
+	)
+	testutils.AssertOk(t, err)
+
+	createdTime := time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC)
+	entry := &logtable.Entry{
+		Data: &logtable.Data{
+			Message:     "test",
+			Source:      "sqlite",
+			Level:       "info",
+			CreatedTime: createdTime,
+		},
+	}
+
+	_, err = table.Add(entry)
+	testutils.AssertOk(t, err)
+
+	dataChan, errorChan := table.Scan(10)
+	scanned := datastoretest.CollectScan(t, dataChan, errorChan)
+
+	testutils.AssertEquals(t, 1, len(scanned))
+	testutils.AssertEquals(t, "test", scanned[0].Data.Message)
+	testutils.AssertTrue(t, createdTime.Equal(scanned[0].Data.CreatedTime))
+
+	testutils.AssertOk(t, tableBackend.Drop())
+}
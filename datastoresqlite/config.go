@@ -0,0 +1,10 @@
+package datastoresqlite
+
+import "time"
+
+type Config struct {
+	// Path to the database file, which is created if it does not exist, or
+	// :memory: for a database which is dropped when the connection is closed
+	Path        string
+	BusyTimeout time.Duration
+}
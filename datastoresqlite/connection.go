@@ -0,0 +1,70 @@
+package datastoresqlite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastoresql"
+)
+
+const defaultBusyTimeout = 5 * time.Second
+
+const memoryPath = ":memory:"
+
+// Connection opens the sqlite database at Config.Path. Migrations can add and
+// drop fields, but return MigrationNotSupportedError for a change to the type
+// of a field or to the primary key, which sqlite can only make by copying the
+// table. The order of columns is left as it is.
+type Connection struct {
+	Config Config
+	db     *sqlx.DB
+}
+
+func (c *Connection) Open() error {
+	busyTimeout := c.Config.BusyTimeout
+	if busyTimeout == 0 {
+		busyTimeout = defaultBusyTimeout
+	}
+
+	// write ahead logging lets scans run alongside writes, and immediate
+	// transactions take the write lock up front instead of failing to upgrade
+	dsn := fmt.Sprintf(
+		"file:%s?_busy_timeout=%d&_journal_mode=WAL&_txlock=immediate",
+		url.PathEscape(c.Config.Path),
+		busyTimeout.Milliseconds(),
+	)
+
+	db, err := sqlx.Connect("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+
+	// every connection to :memory: opens a new empty database, so the pool
+	// keeps the one it has
+	if c.Config.Path == memoryPath {
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
+	c.db = db
+	return nil
+}
+
+func (c *Connection) Close() {
+	c.db.Close()
+}
+
+func (c *Connection) BeginTx(ctx context.Context) (context.Context, datastore.Tx, error) {
+	return datastoresql.BeginTx(ctx, c.db)
+}
+
+// Expose underlying db to query directly
+func (c *Connection) Db() *sqlx.DB {
+	return c.db
+}
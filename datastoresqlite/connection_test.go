@@ -0,0 +1,70 @@
+package datastoresqlite_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastoresqlite"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func newTestConnection(t *testing.T) *datastoresqlite.Connection {
+	t.Helper()
+
+	conn := &datastoresqlite.Connection{
+		Config: datastoresqlite.Config{
+			Path: filepath.Join(t.TempDir(), "test.db"),
+		},
+	}
+	testutils.AssertOk(t, conn.Open())
+
+	return conn
+}
+
+func TestOpen(t *testing.T) {
+	testutils.Case(t, "opens paths with uri characters", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test?mode=ro#1%20.db")
+		conn := &datastoresqlite.Connection{
+			Config: datastoresqlite.Config{Path: path},
+		}
+		testutils.AssertOk(t, conn.Open())
+		defer conn.Close()
+
+		_, err := conn.Db().Exec("CREATE TABLE Test (Id INTEGER)")
+		testutils.AssertOk(t, err)
+
+		_, err = os.Stat(path)
+		testutils.AssertOk(t, err)
+	})
+
+	testutils.Case(t, "shares one in memory database", func(t *testing.T) {
+		conn := &datastoresqlite.Connection{
+			Config: datastoresqlite.Config{Path: ":memory:"},
+		}
+		testutils.AssertOk(t, conn.Open())
+		defer conn.Close()
+
+		ctx := context.Background()
+		_, err := conn.Db().ExecContext(ctx, "CREATE TABLE Test (Id INTEGER)")
+		testutils.AssertOk(t, err)
+
+		// a query made while the connection is held waits for it, instead of
+		// opening another connection to a new empty database
+		held, err := conn.Db().Conn(ctx)
+		testutils.AssertOk(t, err)
+
+		done := make(chan error)
+		go func() {
+			var count int
+			done <- conn.Db().GetContext(ctx, &count, "SELECT COUNT(*) FROM Test")
+		}()
+
+		// give the query time to ask for a connection
+		time.Sleep(10 * time.Millisecond)
+		held.Close()
+		testutils.AssertOk(t, <-done)
+	})
+}
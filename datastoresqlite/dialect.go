@@ -0,0 +1,149 @@
+package datastoresqlite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/fields"
+	"github.com/sophielizg/go-libs/datastoresql"
+)
+
+type Dialect struct{}
+
+func (d Dialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d Dialect) IsDuplicateKeyError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
+
+// MaxPlaceholders is the default SQLITE_MAX_VARIABLE_NUMBER of the sqlite
+// version bundled with the driver
+func (d Dialect) MaxPlaceholders() int {
+	return 32766
+}
+
+func (d Dialect) InsertIfAbsentQuery(tableName string, columnNames []string, primaryKey []string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO NOTHING",
+		d.QuoteIdentifier(tableName),
+		d.quoteAll(columnNames),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columnNames)), ", "),
+		d.quoteAll(primaryKey),
+	)
+}
+
+// baseColumnType derives the sqlite type for a field from the type of its
+// empty value
+func baseColumnType(column datastoresql.Column) (string, bool, error) {
+	switch column.EmptyValue.(type) {
+	case fields.Int, fields.UInt, fields.BigInt, fields.BigUInt:
+		return "INTEGER", false, nil
+	case fields.NullInt, fields.NullUInt, fields.NullBigInt, fields.NullBigUInt:
+		return "INTEGER", true, nil
+	case fields.SmallFloat, fields.Float:
+		return "REAL", false, nil
+	case fields.NullSmallFloat, fields.NullFloat:
+		return "REAL", true, nil
+	case fields.String:
+		return "TEXT", false, nil
+	case fields.NullString:
+		return "TEXT", true, nil
+	case fields.Bool:
+		return "BOOLEAN", false, nil
+	case fields.NullBool:
+		return "BOOLEAN", true, nil
+	case fields.Time:
+		return "DATETIME", false, nil
+	case fields.NullTime:
+		return "DATETIME", true, nil
+	case fields.JsonMap, fields.JsonList:
+		return "TEXT", true, nil
+	default:
+		return "", false, datastoresql.UnsupportedFieldTypeError
+	}
+}
+
+// columnType is the sqlite column definition for a field, apart from auto
+// increment columns which are defined as the primary key
+func columnType(column datastoresql.Column) (string, error) {
+	columnType, nullable, err := baseColumnType(column)
+	if err != nil {
+		return "", err
+	}
+
+	if !nullable {
+		columnType += " NOT NULL"
+	}
+
+	return columnType, nil
+}
+
+func (d Dialect) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func (d Dialect) CreateTableQueries(tableName string, columns []datastoresql.Column, primaryKey []string, indexes []*datastore.Index) ([]string, error) {
+	definitions := make([]string, 0, len(columns)+1)
+	inlinePrimaryKey := false
+
+	for _, column := range columns {
+		columnType, err := columnType(column)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column.Name, err)
+		}
+
+		// sqlite only generates values for a single INTEGER PRIMARY KEY column
+		if column.IsAutoIncrement() {
+			if !fields.IsIntField(column.EmptyValue) || len(primaryKey) != 1 || primaryKey[0] != column.Name {
+				return nil, fmt.Errorf("%s: %w", column.Name, datastoresql.AutoGenerateNotSupportedError)
+			}
+
+			columnType = "INTEGER PRIMARY KEY AUTOINCREMENT"
+			inlinePrimaryKey = true
+		}
+
+		definitions = append(definitions, d.QuoteIdentifier(column.Name)+" "+columnType)
+	}
+
+	if len(primaryKey) > 0 && !inlinePrimaryKey {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", d.quoteAll(primaryKey)))
+	}
+
+	queries := []string{fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s)",
+		d.QuoteIdentifier(tableName),
+		strings.Join(definitions, ", "),
+	)}
+
+	// index names are shared by every table in sqlite, so they are prefixed
+	// with the table name
+	for _, index := range indexes {
+		createIndex := "CREATE INDEX"
+		if index.Unique {
+			createIndex = "CREATE UNIQUE INDEX"
+		}
+
+		queries = append(queries, fmt.Sprintf(
+			"%s IF NOT EXISTS %s ON %s (%s)",
+			createIndex,
+			d.QuoteIdentifier(tableName+"_"+index.Name),
+			d.QuoteIdentifier(tableName),
+			d.quoteAll(index.FieldNames),
+		))
+	}
+
+	return queries, nil
+}
@@ -0,0 +1,15 @@
+package datastoresqlite
+
+import "github.com/sophielizg/go-libs/datastoresql"
+
+var AutoGenerateNotSupportedError = datastoresql.AutoGenerateNotSupportedError
+
+var UnsupportedFieldTypeError = datastoresql.UnsupportedFieldTypeError
+
+var KeyExistsError = datastoresql.KeyExistsError
+
+var KeyDoesNotExistError = datastoresql.KeyDoesNotExistError
+
+var MigrationNotSupportedError = datastoresql.MigrationNotSupportedError
+
+var QueueEmptyError = datastoresql.QueueEmptyError
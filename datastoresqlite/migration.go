@@ -0,0 +1,134 @@
+package datastoresqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sophielizg/go-libs/datastoresql"
+)
+
+type storedColumnRow struct {
+	Name    string `db:"name"`
+	Type    string `db:"type"`
+	NotNull bool   `db:"notnull"`
+	// Pk is the position of the column in the primary key, counting from one,
+	// or zero if it is not part of it
+	Pk int `db:"pk"`
+}
+
+func (d Dialect) StoredTable(ctx context.Context, db sqlx.QueryerContext, tableName string) (*datastoresql.StoredTable, error) {
+	rows := []*storedColumnRow{}
+	err := sqlx.SelectContext(ctx, db, &rows, `SELECT name, type, "notnull", pk FROM pragma_table_info(?) ORDER BY cid`, tableName)
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return nil, nil
+	}
+
+	stored := &datastoresql.StoredTable{
+		Columns: make([]*datastoresql.StoredColumn, len(rows)),
+	}
+	keyRows := []*storedColumnRow{}
+	for i, row := range rows {
+		stored.Columns[i] = &datastoresql.StoredColumn{
+			Name:     row.Name,
+			Type:     row.Type,
+			Nullable: !row.NotNull,
+		}
+
+		if row.Pk > 0 {
+			keyRows = append(keyRows, row)
+		}
+	}
+
+	sort.Slice(keyRows, func(i, j int) bool {
+		return keyRows[i].Pk < keyRows[j].Pk
+	})
+	for _, row := range keyRows {
+		stored.PrimaryKey = append(stored.PrimaryKey, row.Name)
+	}
+
+	return stored, nil
+}
+
+// ColumnMatches ignores whether auto increment columns are nullable
+func (d Dialect) ColumnMatches(stored *datastoresql.StoredColumn, column datastoresql.Column) (bool, error) {
+	columnType, nullable, err := baseColumnType(column)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", column.Name, err)
+	}
+
+	return strings.EqualFold(stored.Type, columnType) &&
+		(column.IsAutoIncrement() || stored.Nullable == nullable), nil
+}
+
+// defaultValue is the value of a new NOT NULL column for existing rows, which
+// sqlite requires
+func defaultValue(columnType string) string {
+	switch columnType {
+	case "TEXT":
+		return "''"
+	case "DATETIME":
+		return "'0001-01-01 00:00:00+00:00'"
+	default:
+		return "0"
+	}
+}
+
+// AddColumnQuery always adds column last, since sqlite cannot position
+// columns. Columns of the primary key cannot be added.
+func (d Dialect) AddColumnQuery(tableName string, column datastoresql.Column, after string) (string, error) {
+	if column.IsKey || column.IsAutoIncrement() {
+		return "", fmt.Errorf("%s: %w", column.Name, datastoresql.MigrationNotSupportedError)
+	}
+
+	baseType, nullable, err := baseColumnType(column)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", column.Name, err)
+	}
+
+	definition := baseType
+	if !nullable {
+		definition += " NOT NULL DEFAULT " + defaultValue(baseType)
+	}
+
+	return fmt.Sprintf(
+		"ALTER TABLE %s ADD COLUMN %s %s",
+		d.QuoteIdentifier(tableName),
+		d.QuoteIdentifier(column.Name),
+		definition,
+	), nil
+}
+
+// AlterColumnQuery returns MigrationNotSupportedError, since sqlite can only
+// change the definition of a column by copying the table
+func (d Dialect) AlterColumnQuery(tableName string, column datastoresql.Column) (string, error) {
+	return "", fmt.Errorf("%s: %w", column.Name, datastoresql.MigrationNotSupportedError)
+}
+
+// MoveColumnQuery returns an empty statement, since sqlite cannot position
+// columns
+func (d Dialect) MoveColumnQuery(tableName string, column datastoresql.Column, after string) (string, error) {
+	return "", nil
+}
+
+func (d Dialect) DropColumnQuery(tableName string, columnName string) string {
+	return fmt.Sprintf(
+		"ALTER TABLE %s DROP COLUMN %s",
+		d.QuoteIdentifier(tableName),
+		d.QuoteIdentifier(columnName),
+	)
+}
+
+// AlterPrimaryKeyQuery returns MigrationNotSupportedError, since sqlite
+// cannot change the primary key of a table
+func (d Dialect) AlterPrimaryKeyQuery(tableName string, storedKey []string, primaryKey []string) (string, error) {
+	return "", datastoresql.MigrationNotSupportedError
+}
+
+func (d Dialect) TransactionalDDL() bool {
+	return true
+}
@@ -0,0 +1,13 @@
+package datastoresqlite
+
+import "github.com/sophielizg/go-libs/datastoresql"
+
+type QueueBackend struct {
+	conn *Connection
+	datastoresql.QueueBackend
+}
+
+func (b *QueueBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+	b.SetDB(conn.db, Dialect{})
+}
@@ -0,0 +1,16 @@
+package datastoresqlite_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastoresqlite"
+)
+
+func TestQueueBackend(t *testing.T) {
+	datastoretest.RunQueueSuite(t, func(t *testing.T) (*datastoresqlite.Connection, *datastoresqlite.QueueBackend) {
+		conn := newTestConnection(t)
+		t.Cleanup(func() { conn.Close() })
+		return conn, &datastoresqlite.QueueBackend{}
+	})
+}
@@ -0,0 +1,13 @@
+package datastoresqlite
+
+import "github.com/sophielizg/go-libs/datastoresql"
+
+type SortTableBackend struct {
+	conn *Connection
+	datastoresql.SortTableBackend
+}
+
+func (b *SortTableBackend) SetConnection(conn *Connection) {
+	b.conn = conn
+	b.SetDB(conn.db, Dialect{})
+}
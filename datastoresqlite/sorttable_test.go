@@ -0,0 +1,69 @@
+package datastoresqlite_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sophielizg/go-libs/datastore"
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastore/examples/purchase"
+	"github.com/sophielizg/go-libs/datastoresqlite"
+	"github.com/sophielizg/go-libs/testutils"
+)
+
+func TestSortTableBackend(t *testing.T) {
+	datastoretest.RunSortTableSuite(t, func(t *testing.T) (*datastoresqlite.Connection, *datastoresqlite.SortTableBackend) {
+		conn := newTestConnection(t)
+		t.Cleanup(func() { conn.Close() })
+		return conn, &datastoresqlite.SortTableBackend{}
+	})
+}
+
+func TestSortTablePurchases(t *testing.T) {
+	conn := newTestConnection(t)
+	defer conn.Close()
+
+	table := purchase.NewTable()
+	tableBackend := &datastoresqlite.SortTableBackend{}
+
+	group := datastore.NewConnectionGroup(
+		datastore.WithConnection(conn),
+	)
+	err := group.RegisterTables(
+		datastore.RegisterSortTable[*datastoresqlite.Connection](table, tableBackend),
+	)
+	testutils.AssertOk(t, err)
+
+	purchaseTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, customerName := range []string{"customer", "other"} {
+		for i := 0; i < 4; i += 1 {
+			_, err = table.Add(&purchase.Entry{
+				Key: &purchase.Key{
+					CustomerName: customerName,
+					PurchaseTime: purchaseTime.Add(time.Duration(i) * time.Hour),
+					ItemBrand:    "brand",
+					ItemName:     "item",
+				},
+				Data: &purchase.Data{Department: "department", Price: 1.5, Quantity: i},
+			})
+			testutils.AssertOk(t, err)
+		}
+	}
+
+	latest, err := purchase.Latest(table, "customer", 2)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 2, len(latest))
+	testutils.AssertEquals(t, 3, latest[0].Data.Quantity)
+	testutils.AssertEquals(t, 2, latest[1].Data.Quantity)
+	testutils.AssertEquals(t, "customer", latest[1].Key.CustomerName)
+
+	totals, err := purchase.DepartmentTotals(table)
+	testutils.AssertOk(t, err)
+	testutils.AssertEquals(t, 1, len(totals))
+	testutils.AssertEquals(t, "department", totals[0].Group.Data.Department)
+	testutils.AssertTrue(t, totals[0].Values["Count"] == 8)
+	testutils.AssertTrue(t, totals[0].Values["SumPrice"] == 12.0)
+	testutils.AssertTrue(t, totals[0].Values["SumQuantity"] == 12)
+
+	testutils.AssertOk(t, tableBackend.Drop())
+}
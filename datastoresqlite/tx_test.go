@@ -0,0 +1,25 @@
+package datastoresqlite_test
+
+import (
+	"testing"
+
+	"github.com/sophielizg/go-libs/datastore/datastoretest"
+	"github.com/sophielizg/go-libs/datastoresqlite"
+)
+
+func TestTx(t *testing.T) {
+	datastoretest.RunTxSuite(
+		t,
+		func(t *testing.T) *datastoresqlite.Connection {
+			conn := newTestConnection(t)
+			t.Cleanup(func() { conn.Close() })
+			return conn
+		},
+		func() *datastoresqlite.HashTableBackend {
+			return &datastoresqlite.HashTableBackend{}
+		},
+		func() *datastoresqlite.SortTableBackend {
+			return &datastoresqlite.SortTableBackend{}
+		},
+	)
+}
@@ -39,7 +39,7 @@ func (v *DataRow) Mutator() *mutator.FieldMutator {
 	return v.fieldMutator
 }
 
-var DataRowSettings = fields.DataRowSettings{
+var DataRowSettings = fields.RowSettings{
 	FieldSettings: fields.NewFieldSettings(
 		fields.WithNumBytes(MessageKey, 255),
 		fields.WithNumBytes(LoggerNameKey, 63),
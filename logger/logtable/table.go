@@ -8,7 +8,7 @@ func New(tableName string) *LogTable {
 	return &LogTable{
 		Settings: datastore.NewTableSettings(
 			datastore.WithTableName(tableName),
-			datastore.WithDataRowSettings(&DataRowSettings),
+			datastore.WithDataSettings(&DataRowSettings),
 		),
 	}
 }
@@ -1,5 +1,7 @@
 package utils
 
+import "sort"
+
 func MergeMaps[K comparable, V any](maps ...map[K]V) map[K]V {
 	merged := make(map[K]V)
 
@@ -11,3 +13,13 @@ func MergeMaps[K comparable, V any](maps ...map[K]V) map[K]V {
 
 	return merged
 }
+
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
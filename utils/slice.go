@@ -9,3 +9,18 @@ func SliceContains[T comparable](slice []T, value T) bool {
 
 	return false
 }
+
+// Unique returns the values of slice without repeats, in the order they first
+// appear
+func Unique[T comparable](slice []T) []T {
+	seen := make(map[T]bool, len(slice))
+	unique := make([]T, 0, len(slice))
+	for _, item := range slice {
+		if !seen[item] {
+			seen[item] = true
+			unique = append(unique, item)
+		}
+	}
+
+	return unique
+}